@@ -0,0 +1,111 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+
+	"filippo.io/edwards25519"
+)
+
+// AccumulatingVerifier is a batch verifier that uses constant memory
+// regardless of the number of entries, for batches too large to hold.
+//
+// Instead of keeping the entries for Verify, Add folds each entry into a
+// running sum of the batch equation, with its own random coefficient z_i:
+//
+//	sum([z_i]R_i + [z_i * k_i]A_i)  and  sum(z_i * s_i).
+//
+// This costs a two-term scalar multiplication per entry, which makes an
+// AccumulatingVerifier slower than a BatchVerifier and about as fast as
+// verifying each entry individually. Since the entries are not kept, the
+// invalid ones cannot be identified after a failure.
+type AccumulatingVerifier struct {
+	sum   edwards25519.Point
+	sCoef edwards25519.Scalar
+	n     int
+	bad   bool // a malformed entry was added, or randomness failed
+}
+
+// NewAccumulatingVerifier creates an empty AccumulatingVerifier.
+func NewAccumulatingVerifier() *AccumulatingVerifier {
+	v := new(AccumulatingVerifier)
+	v.sum.Set(edwards25519.NewIdentityPoint())
+	return v
+}
+
+// Add folds a (public key, message, sig) triple into the batch. It retains
+// no reference to its arguments. A malformed entry makes the batch fail.
+func (v *AccumulatingVerifier) Add(publicKey ed25519.PublicKey, message, sig []byte) {
+	v.n++
+	if v.bad {
+		return
+	}
+	if len(publicKey) != ed25519.PublicKeySize {
+		v.bad = true
+		return
+	}
+	if len(sig) != ed25519.SignatureSize || sig[63]&224 != 0 {
+		v.bad = true
+		return
+	}
+
+	// ZIP215: this works because SetBytes does not check that encodings are canonical.
+	A := new(edwards25519.Point)
+	if err := decodeKey(A, publicKey); err != nil {
+		v.bad = true
+		return
+	}
+	// ZIP215: this works because SetBytes does not check that encodings are canonical.
+	R, err := new(edwards25519.Point).SetBytes(sig[:32])
+	if err != nil {
+		v.bad = true
+		return
+	}
+	s, err := new(edwards25519.Scalar).SetCanonicalBytes(sig[32:])
+	if err != nil {
+		v.bad = true
+		return
+	}
+	k := ComputeChallenge(sig[:32], publicKey, message)
+
+	var randomness [16]byte
+	var buf [32]byte
+	z := new(edwards25519.Scalar)
+	if _, err := rand.Read(randomness[:]); err != nil {
+		v.bad = true
+		return
+	}
+	if err := setRandomizer(z, randomness[:], buf[:]); err != nil {
+		v.bad = true
+		return
+	}
+
+	zk := new(edwards25519.Scalar).Multiply(z, k)
+	term := new(edwards25519.Point).VarTimeMultiScalarMult(
+		[]*edwards25519.Scalar{z, zk}, []*edwards25519.Point{R, A})
+	v.sum.Add(&v.sum, term)
+	v.sCoef.MultiplyAdd(z, s, &v.sCoef)
+}
+
+// Len returns the number of entries added to the batch.
+func (v *AccumulatingVerifier) Len() int {
+	return v.n
+}
+
+// Verify reports whether all entries added so far are valid, with the
+// validation criteria of Verify, by checking
+//
+//	[8]([-sum(z_i * s_i)]B + sum([z_i]R_i + [z_i * k_i]A_i)) = 0.
+//
+// Calling Verify on an empty batch returns false. More entries may be added
+// after Verify, which then checks them along with the previous ones.
+func (v *AccumulatingVerifier) Verify() bool {
+	if v.bad || v.n == 0 {
+		return false
+	}
+	minusS := new(edwards25519.Scalar).Negate(&v.sCoef)
+	check := new(edwards25519.Point).ScalarBaseMult(minusS)
+	check.Add(check, &v.sum)
+	check.MultByCofactor(check)
+	return check.Equal(edwards25519.NewIdentityPoint()) == 1
+}
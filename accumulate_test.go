@@ -0,0 +1,48 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"testing"
+
+	"github.com/hdevalence/ed25519consensus/testvectors"
+)
+
+func TestAccumulatingVerifier(t *testing.T) {
+	v := NewAccumulatingVerifier()
+	if v.Verify() {
+		t.Error("empty batch accepted")
+	}
+	for i := 0; i < 38; i++ {
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		msg := []byte(fmt.Sprintf("message %d", i))
+		v.Add(pub, msg, ed25519.Sign(priv, msg))
+	}
+	if v.Len() != 38 {
+		t.Errorf("Len() = %d, want 38", v.Len())
+	}
+	if !v.Verify() {
+		t.Fatal("valid batch rejected")
+	}
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	v.Add(pub, []byte("other"), ed25519.Sign(priv, []byte("message")))
+	if v.Verify() {
+		t.Error("batch with an invalid signature accepted")
+	}
+
+	v = NewAccumulatingVerifier()
+	v.Add(pub, []byte("message"), ed25519.Sign(priv, []byte("message")))
+	v.Add(pub[:31], []byte("message"), ed25519.Sign(priv, []byte("message")))
+	if v.Verify() {
+		t.Error("batch with a short public key accepted")
+	}
+}
+
+func TestZIP215Accumulating(t *testing.T) {
+	testvectors.Run(t, func(publicKey ed25519.PublicKey, message, signature []byte) bool {
+		v := NewAccumulatingVerifier()
+		v.Add(publicKey, message, signature)
+		return v.Verify()
+	})
+}
@@ -0,0 +1,239 @@
+package ed25519consensus
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Strategy is a way of verifying the entries of a batch, chosen by an
+// AdaptiveVerifier.
+type Strategy int
+
+const (
+	// StrategyBatch checks the whole batch with one batch equation, and
+	// verifies the entries individually only if it fails.
+	StrategyBatch Strategy = iota
+	// StrategyChunked checks the batch in chunks, each with its own batch
+	// equation, and verifies individually only the entries of failed chunks.
+	StrategyChunked
+	// StrategyIndividual verifies every entry individually, in parallel.
+	StrategyIndividual
+)
+
+// String returns the name of the strategy.
+func (s Strategy) String() string {
+	switch s {
+	case StrategyBatch:
+		return "batch"
+	case StrategyChunked:
+		return "chunked"
+	case StrategyIndividual:
+		return "individual"
+	default:
+		return "unknown"
+	}
+}
+
+// Costs of the strategy model, relative to one individual verification.
+// They are rough estimates: a batch equation has a fixed cost of about one
+// verification, plus about half a verification per entry.
+const (
+	adaptiveChunkCost = 0.75
+	adaptiveEntryCost = 0.5
+
+	// adaptiveDecay is the weight of the last batch in the observed
+	// failure rate.
+	adaptiveDecay = 0.25
+)
+
+// AdaptiveVerifier verifies batches with the strategy expected to be
+// cheapest given how often entries are invalid. When most entries are
+// valid, one batch equation is cheapest; when failures are frequent, as
+// with batches from adversarial gossip, a failed batch equation is wasted
+// work, and smaller chunks or individual verification are cheaper.
+//
+// The failure rate starts at a hint given by the caller, and is then
+// updated with an exponential moving average of the rate observed by
+// Verify. An AdaptiveVerifier is safe for concurrent use.
+type AdaptiveVerifier struct {
+	mu   sync.Mutex
+	rate float64
+}
+
+// NewAdaptiveVerifier creates an AdaptiveVerifier expecting a fraction
+// failureRate of invalid entries, between 0 and 1.
+func NewAdaptiveVerifier(failureRate float64) *AdaptiveVerifier {
+	return &AdaptiveVerifier{rate: clampRate(failureRate)}
+}
+
+func clampRate(rate float64) float64 {
+	if !(rate > 0) { // also catches NaN
+		return 0
+	}
+	if rate > 1 {
+		return 1
+	}
+	return rate
+}
+
+// FailureRate returns the current estimate of the fraction of invalid
+// entries.
+func (a *AdaptiveVerifier) FailureRate() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.rate
+}
+
+// Strategy returns the strategy that Verify would use for a batch of n
+// entries, and the chunk size for StrategyChunked.
+func (a *AdaptiveVerifier) Strategy(n int) (s Strategy, chunkSize int) {
+	return chooseStrategy(a.FailureRate(), n)
+}
+
+// chooseStrategy minimizes the expected cost per entry of verifying n
+// entries in chunks of c, each costing
+//
+//	adaptiveChunkCost/c + adaptiveEntryCost + (1 - (1-rate)^c)
+//
+// with the last term accounting for verifying a failed chunk individually,
+// against a cost of 1 for individual verification.
+func chooseStrategy(rate float64, n int) (Strategy, int) {
+	if !ShouldBatch(n) {
+		return StrategyIndividual, 0
+	}
+	best, bestCost := 0, 1.0
+	for c := minBatchSize; ; c *= 2 {
+		if c > n {
+			c = n
+		}
+		cost := adaptiveChunkCost/float64(c) + adaptiveEntryCost + 1 - math.Pow(1-rate, float64(c))
+		if cost < bestCost {
+			best, bestCost = c, cost
+		}
+		if c == n {
+			break
+		}
+	}
+	switch {
+	case best == 0:
+		return StrategyIndividual, 0
+	case best == n:
+		return StrategyBatch, 0
+	default:
+		return StrategyChunked, best
+	}
+}
+
+// Verify verifies the entries of v with the strategy returned by Strategy,
+// and returns for each entry whether it is valid. Like v.Verify, it calls
+// the failure callback of v for each invalid entry, records the valid ones
+// in the cache of v, and reports to the tracer and metrics collector.
+//
+// Verify then updates the estimated failure rate with the fraction of
+// invalid entries in v.
+func (a *AdaptiveVerifier) Verify(v *BatchVerifier) []bool {
+	n := len(v.entries)
+	valid := make([]bool, n)
+	errs := make([]error, n)
+	if n == 0 {
+		return valid
+	}
+	if v.overflowed {
+		// The dropped entry cannot be identified, so none is valid.
+		return valid
+	}
+	v.aliases.check()
+	m := metrics.Load()
+	var start time.Time
+	if m != nil {
+		start = time.Now()
+	}
+
+	strategy, chunkSize := a.Strategy(n)
+	switch strategy {
+	case StrategyBatch:
+		v.verifyAdaptiveChunk(valid, errs, 0, n)
+	case StrategyChunked:
+		for start := 0; start < n; start += chunkSize {
+			end := start + chunkSize
+			if end > n {
+				end = n
+			}
+			v.verifyAdaptiveChunk(valid, errs, start, end)
+		}
+	case StrategyIndividual:
+		v.verifyIndividually(valid, errs, 0, n)
+	}
+
+	// Every good entry was hashed by the verification above.
+	tracing := tracer.Load() != nil
+	invalid := 0
+	for i, ok := range valid {
+		e := &v.entries[i]
+		if tracing && e.bound {
+			traceDigest(e.pubkey[:], e.signature[:], e.dom, &e.digest, &v.policy, ok)
+		}
+		if ok {
+			if v.cache != nil {
+				v.cacheStoreEntry(e)
+			}
+			continue
+		}
+		invalid++
+		if v.onFailure != nil {
+			v.reportFailure(i, e, errs[i])
+		}
+		if m != nil {
+			m.c.ObserveFailure(errs[i])
+		}
+	}
+	if m != nil {
+		m.c.ObserveBatch(n, invalid == 0, time.Since(start))
+	}
+
+	a.mu.Lock()
+	a.rate += adaptiveDecay * (float64(invalid)/float64(n) - a.rate)
+	a.mu.Unlock()
+	return valid
+}
+
+// verifyAdaptiveChunk sets valid[start:end] from one batch equation over
+// the entries, falling back to individual verification if it fails.
+func (v *BatchVerifier) verifyAdaptiveChunk(valid []bool, errs []error, start, end int) {
+	if v.verifyEntries(v.entries[start:end]) {
+		for i := start; i < end; i++ {
+			valid[i] = true
+		}
+		return
+	}
+	v.verifyIndividually(valid, errs, start, end)
+}
+
+// verifyIndividually sets valid[start:end] by verifying each entry on its
+// own, spread over GOMAXPROCS goroutines, and errs[start:end] to the reason
+// each invalid entry is invalid.
+func (v *BatchVerifier) verifyIndividually(valid []bool, errs []error, start, end int) {
+	workers := runtime.GOMAXPROCS(0)
+	if workers > end-start {
+		workers = end - start
+	}
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := start + w; i < end; i += workers {
+				e := &v.entries[i]
+				if !e.good {
+					errs[i] = e.rejection()
+					continue
+				}
+				errs[i] = e.check()
+				valid[i] = errs[i] == nil
+			}
+		}(w)
+	}
+	wg.Wait()
+}
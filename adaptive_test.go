@@ -0,0 +1,97 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"testing"
+)
+
+func TestChooseStrategy(t *testing.T) {
+	for _, tt := range []struct {
+		rate     float64
+		n        int
+		strategy Strategy
+	}{
+		{0, 1000, StrategyBatch},
+		{0.0001, 100, StrategyBatch},
+		{0.01, 1000, StrategyChunked},
+		{0.5, 1000, StrategyIndividual},
+		{0, 1, StrategyIndividual},
+	} {
+		s, c := chooseStrategy(tt.rate, tt.n)
+		if s != tt.strategy {
+			t.Errorf("rate %v, n %d: got %v, want %v", tt.rate, tt.n, s, tt.strategy)
+		}
+		if (s == StrategyChunked) != (c > 0) || c >= tt.n {
+			t.Errorf("rate %v, n %d: chunk size %d for %v", tt.rate, tt.n, c, s)
+		}
+	}
+}
+
+func TestAdaptiveVerifier(t *testing.T) {
+	for _, rate := range []float64{0, 0.05, 1} {
+		a := NewAdaptiveVerifier(rate)
+		v := NewBatchVerifier()
+		var reported []int
+		v.SetFailureCallback(func(i int, _ ed25519.PublicKey, _, _ []byte, _ error) {
+			reported = append(reported, i)
+		})
+		for i := 0; i < 100; i++ {
+			pub, priv, _ := ed25519.GenerateKey(nil)
+			msg := []byte(fmt.Sprintf("message %d", i))
+			sig := ed25519.Sign(priv, msg)
+			if i%10 == 3 {
+				msg = []byte("tampered")
+			}
+			v.Add(pub, msg, sig)
+		}
+		v.Add(nil, nil, nil)
+
+		valid := a.Verify(&v)
+		for i, ok := range valid {
+			if want := i < 100 && i%10 != 3; ok != want {
+				t.Errorf("rate %v: entry %d valid %v, want %v", rate, i, ok, want)
+			}
+		}
+		if len(reported) != 11 {
+			t.Errorf("rate %v: %d failures reported, want 11", rate, len(reported))
+		}
+		if r := a.FailureRate(); r == rate {
+			t.Errorf("rate %v: failure rate was not updated", rate)
+		}
+	}
+}
+
+func TestAdaptiveVerifierHooks(t *testing.T) {
+	c := &testCollector{}
+	SetMetricsCollector(c)
+	var trace []TraceRecord
+	SetTracer(func(r *TraceRecord) { trace = append(trace, *r) })
+	t.Cleanup(func() {
+		SetMetricsCollector(nil)
+		SetTracer(nil)
+	})
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("adaptive")
+	sig := ed25519.Sign(priv, msg)
+	cache := NewVerificationCache(8)
+	v := NewBatchVerifier()
+	v.SetCache(cache)
+	v.Add(pub, msg, sig)
+	v.Add(pub, []byte("tampered"), sig)
+	NewAdaptiveVerifier(0).Verify(&v)
+
+	if len(c.batches) != 1 || c.batches[0] != -2 {
+		t.Errorf("batches %v, want [-2]", c.batches)
+	}
+	if len(c.failures) != 1 || c.failures[0] != ErrInvalidSignature {
+		t.Errorf("failures %v, want [%v]", c.failures, ErrInvalidSignature)
+	}
+	if len(trace) != 2 || !trace[0].Valid || trace[1].Valid || len(Replay(trace)) != 0 {
+		t.Errorf("trace of %d records does not match the decisions", len(trace))
+	}
+	if cache.Len() != 1 {
+		t.Errorf("cache holds %d outcomes, want the valid entry only", cache.Len())
+	}
+}
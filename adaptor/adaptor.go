@@ -0,0 +1,226 @@
+// Package adaptor implements adaptor signatures (pre-signatures) over Ed25519.
+//
+// A pre-signature commits a signer to an Ed25519 signature that can only be
+// completed by someone who knows the discrete logarithm t of a statement
+// point T = [t]B. Completing the pre-signature with Adapt yields an ordinary
+// Ed25519 signature, accepted by ed25519consensus.Verify, and anyone holding
+// both the pre-signature and the completed signature can recover t with
+// Extract. This is the building block of atomic swaps and payment channels.
+//
+// A pre-signature (R, s') of message M under A for statement T satisfies
+//
+//	[8][s']B = [8](R + [k]A),  k = SHA-512(enc(R + T) || enc(A) || M),
+//
+// and adapts to the signature (R + T, s' + t). Points are decoded with the
+// same rules as ed25519consensus.Verify, except that statements must be
+// torsion-free so that their witness is well defined.
+package adaptor
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+	"io"
+
+	"filippo.io/edwards25519"
+	"github.com/hdevalence/ed25519consensus"
+)
+
+const (
+	// PreSignatureSize is the size, in bytes, of a pre-signature.
+	PreSignatureSize = 64
+	// StatementSize is the size, in bytes, of a statement.
+	StatementSize = 32
+	// WitnessSize is the size, in bytes, of a witness.
+	WitnessSize = 32
+)
+
+var (
+	errInvalidStatement = errors.New("adaptor: invalid statement")
+	errInvalidWitness   = errors.New("adaptor: witness does not match statement")
+	errInvalidPreSig    = errors.New("adaptor: invalid pre-signature encoding")
+	errInvalidSignature = errors.New("adaptor: invalid signature encoding")
+)
+
+// GenerateStatement returns a random witness t and the statement [t]B, using
+// entropy from random, or crypto/rand.Reader if random is nil.
+func GenerateStatement(random io.Reader) (witness, statement []byte, err error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	var buf [64]byte
+	if _, err := io.ReadFull(random, buf[:]); err != nil {
+		return nil, nil, err
+	}
+	t, _ := new(edwards25519.Scalar).SetUniformBytes(buf[:])
+	T := new(edwards25519.Point).ScalarBaseMult(t)
+	return t.Bytes(), T.Bytes(), nil
+}
+
+// decodeStatement decodes a statement, rejecting points with a torsion
+// component.
+func decodeStatement(statement []byte) (*edwards25519.Point, error) {
+	if len(statement) != StatementSize ||
+		ed25519consensus.ValidatePublicKey(statement, ed25519consensus.RequireTorsionFreeKey) != nil {
+		return nil, errInvalidStatement
+	}
+	return new(edwards25519.Point).SetBytes(statement)
+}
+
+// challenge returns SHA-512(enc(R + T) || A || M) reduced modulo L, along
+// with the encoding of R + T.
+func challenge(R, T *edwards25519.Point, publicKey, message []byte) (*edwards25519.Scalar, []byte) {
+	RT := new(edwards25519.Point).Add(R, T).Bytes()
+	h := sha512.New()
+	h.Write(RT)
+	h.Write(publicKey)
+	h.Write(message)
+	k, _ := new(edwards25519.Scalar).SetUniformBytes(h.Sum(nil))
+	return k, RT
+}
+
+// nonceDomain is hashed before the nonce prefix of the key when deriving
+// the nonce of a pre-signature. Without it, the nonce would be the RFC 8032
+// nonce of a plain signature of statement || message, and the two signatures
+// together would reveal the secret scalar.
+const nonceDomain = "ed25519consensus adaptor nonce v1"
+
+// PreSign returns a pre-signature of message by priv for statement. The
+// nonce is derived deterministically from the key, the statement and the
+// message, like in RFC 8032, but domain-separated from the nonces of plain
+// signatures by the same key.
+func PreSign(priv ed25519.PrivateKey, message, statement []byte) ([]byte, error) {
+	if len(priv) != ed25519.PrivateKeySize {
+		return nil, errors.New("adaptor: bad private key length")
+	}
+	T, err := decodeStatement(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha512.Sum512(priv.Seed())
+	a, _ := new(edwards25519.Scalar).SetBytesWithClamping(digest[:32])
+	publicKey := priv[32:]
+
+	h := sha512.New()
+	h.Write([]byte(nonceDomain))
+	h.Write(digest[32:])
+	h.Write(statement)
+	h.Write(message)
+	r, _ := new(edwards25519.Scalar).SetUniformBytes(h.Sum(nil))
+	R := new(edwards25519.Point).ScalarBaseMult(r)
+
+	k, _ := challenge(R, T, publicKey, message)
+	s := new(edwards25519.Scalar).MultiplyAdd(k, a, r)
+
+	preSig := make([]byte, 0, PreSignatureSize)
+	preSig = append(preSig, R.Bytes()...)
+	return append(preSig, s.Bytes()...), nil
+}
+
+// VerifyPreSignature reports whether preSig is a valid pre-signature of
+// message by publicKey for statement. If it is, Adapt with the witness of
+// statement produces a signature accepted by ed25519consensus.Verify.
+func VerifyPreSignature(publicKey ed25519.PublicKey, message, statement, preSig []byte) bool {
+	if len(publicKey) != ed25519.PublicKeySize || len(preSig) != PreSignatureSize {
+		return false
+	}
+	T, err := decodeStatement(statement)
+	if err != nil {
+		return false
+	}
+	A, err := new(edwards25519.Point).SetBytes(publicKey)
+	if err != nil {
+		return false
+	}
+	R, err := new(edwards25519.Point).SetBytes(preSig[:32])
+	if err != nil {
+		return false
+	}
+	s, err := new(edwards25519.Scalar).SetCanonicalBytes(preSig[32:])
+	if err != nil {
+		return false
+	}
+
+	k, _ := challenge(R, T, publicKey, message)
+	// Check [8]([s']B - [k]A - R) == 0, as in ZIP215.
+	A.Negate(A)
+	p := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(k, A, s)
+	p.Subtract(p, R)
+	p.MultByCofactor(p)
+	return p.Equal(edwards25519.NewIdentityPoint()) == 1
+}
+
+// Adapt completes preSig into an Ed25519 signature using the witness of
+// statement. It returns an error if witness is not the discrete logarithm of
+// statement. Adapt does not check preSig; use VerifyPreSignature for that.
+func Adapt(preSig, statement, witness []byte) ([]byte, error) {
+	if len(preSig) != PreSignatureSize {
+		return nil, errInvalidPreSig
+	}
+	T, err := decodeStatement(statement)
+	if err != nil {
+		return nil, err
+	}
+	t, err := decodeWitness(T, witness)
+	if err != nil {
+		return nil, err
+	}
+	R, err := new(edwards25519.Point).SetBytes(preSig[:32])
+	if err != nil {
+		return nil, errInvalidPreSig
+	}
+	s, err := new(edwards25519.Scalar).SetCanonicalBytes(preSig[32:])
+	if err != nil {
+		return nil, errInvalidPreSig
+	}
+
+	sig := make([]byte, 0, ed25519.SignatureSize)
+	sig = append(sig, new(edwards25519.Point).Add(R, T).Bytes()...)
+	return append(sig, s.Add(s, t).Bytes()...), nil
+}
+
+// Extract recovers the witness of statement from a pre-signature and the
+// signature it was adapted into.
+func Extract(sig, preSig, statement []byte) ([]byte, error) {
+	if len(sig) != ed25519.SignatureSize {
+		return nil, errInvalidSignature
+	}
+	if len(preSig) != PreSignatureSize {
+		return nil, errInvalidPreSig
+	}
+	T, err := decodeStatement(statement)
+	if err != nil {
+		return nil, err
+	}
+	s, err := new(edwards25519.Scalar).SetCanonicalBytes(sig[32:])
+	if err != nil {
+		return nil, errInvalidSignature
+	}
+	sPre, err := new(edwards25519.Scalar).SetCanonicalBytes(preSig[32:])
+	if err != nil {
+		return nil, errInvalidPreSig
+	}
+	t := s.Subtract(s, sPre)
+	if new(edwards25519.Point).ScalarBaseMult(t).Equal(T) != 1 {
+		return nil, errInvalidWitness
+	}
+	return t.Bytes(), nil
+}
+
+// decodeWitness decodes witness and checks that it is the discrete logarithm
+// of T.
+func decodeWitness(T *edwards25519.Point, witness []byte) (*edwards25519.Scalar, error) {
+	if len(witness) != WitnessSize {
+		return nil, errInvalidWitness
+	}
+	t, err := new(edwards25519.Scalar).SetCanonicalBytes(witness)
+	if err != nil {
+		return nil, errInvalidWitness
+	}
+	if new(edwards25519.Point).ScalarBaseMult(t).Equal(T) != 1 {
+		return nil, errInvalidWitness
+	}
+	return t, nil
+}
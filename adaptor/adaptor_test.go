@@ -0,0 +1,105 @@
+package adaptor
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/hdevalence/ed25519consensus"
+)
+
+func TestAdaptorRoundTrip(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("swap 1 BTC for 20 ETH")
+	witness, statement, err := GenerateStatement(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	preSig, err := PreSign(priv, msg, statement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyPreSignature(pub, msg, statement, preSig) {
+		t.Fatal("pre-signature did not verify")
+	}
+	if ed25519consensus.Verify(pub, msg, preSig) {
+		t.Error("pre-signature verified as a signature")
+	}
+	if VerifyPreSignature(pub, []byte("other"), statement, preSig) {
+		t.Error("pre-signature verified for the wrong message")
+	}
+	_, other, _ := GenerateStatement(nil)
+	if VerifyPreSignature(pub, msg, other, preSig) {
+		t.Error("pre-signature verified for the wrong statement")
+	}
+
+	sig, err := Adapt(preSig, statement, witness)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519consensus.Verify(pub, msg, sig) || !ed25519.Verify(pub, msg, sig) {
+		t.Fatal("adapted signature did not verify")
+	}
+
+	got, err := Extract(sig, preSig, statement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, witness) {
+		t.Errorf("extracted witness %x, want %x", got, witness)
+	}
+}
+
+func TestAdaptorErrors(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("message")
+	witness, statement, _ := GenerateStatement(nil)
+	preSig, _ := PreSign(priv, msg, statement)
+
+	wrongWitness, _, _ := GenerateStatement(nil)
+	if _, err := Adapt(preSig, statement, wrongWitness); err == nil {
+		t.Error("Adapt accepted a witness for a different statement")
+	}
+
+	// A statement of order 8 has no witness.
+	torsion, _ := hex.DecodeString("26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc05")
+	if _, err := PreSign(priv, msg, torsion); err == nil {
+		t.Error("PreSign accepted a torsion statement")
+	}
+	if VerifyPreSignature(pub, msg, torsion, preSig) {
+		t.Error("VerifyPreSignature accepted a torsion statement")
+	}
+
+	sig, _ := Adapt(preSig, statement, witness)
+	unrelated := ed25519.Sign(priv, msg)
+	if _, err := Extract(unrelated, preSig, statement); err == nil {
+		t.Error("Extract succeeded from an unrelated signature")
+	}
+	if _, err := Extract(sig[:63], preSig, statement); err == nil {
+		t.Error("Extract accepted a truncated signature")
+	}
+}
+
+// TestPreSignNonceSeparation guards against deriving the pre-signature nonce
+// like the RFC 8032 nonce of a plain signature of statement || message: the
+// two signatures would share R, and reveal the key together.
+func TestPreSignNonceSeparation(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	for i := 0; i < 4; i++ {
+		msg := []byte{byte(i)}
+		_, statement, err := GenerateStatement(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		preSig, err := PreSign(priv, msg, statement)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig := ed25519.Sign(priv, append(append([]byte{}, statement...), msg...))
+		if bytes.Equal(preSig[:32], sig[:32]) {
+			t.Fatal("pre-signature and plain signature of statement || message share R")
+		}
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !ed25519consensus_debug
+
+package ed25519consensus
+
+// aliasChecker records the caller slices retained by a BatchVerifier, and
+// checks that they were not modified before Verify uses them. The check is
+// only performed when building with the ed25519consensus_debug tag; in normal
+// builds aliasChecker is empty and its methods compile away.
+type aliasChecker struct{}
+
+// record notes that the batch retains message for entry i.
+func (*aliasChecker) record(i int, message []byte) {}
+
+// check panics if a recorded slice was modified since it was recorded, and
+// then forgets all recorded slices, which Verify releases.
+func (*aliasChecker) check() {}
+
+// reset forgets all recorded slices, for a batch emptied by Reset.
+func (*aliasChecker) reset() {}
@@ -0,0 +1,35 @@
+//go:build ed25519consensus_debug
+
+package ed25519consensus
+
+import (
+	"bytes"
+	"fmt"
+)
+
+type aliasChecker struct {
+	retained []retainedSlice
+}
+
+type retainedSlice struct {
+	index    int
+	slice    []byte
+	original []byte
+}
+
+func (c *aliasChecker) record(i int, message []byte) {
+	c.retained = append(c.retained, retainedSlice{i, message, append([]byte(nil), message...)})
+}
+
+func (c *aliasChecker) check() {
+	for _, r := range c.retained {
+		if !bytes.Equal(r.slice, r.original) {
+			panic(fmt.Sprintf("ed25519consensus: message of batch entry %d was modified after Add; use AddCopy to add reused buffers", r.index))
+		}
+	}
+	c.retained = c.retained[:0]
+}
+
+func (c *aliasChecker) reset() {
+	c.retained = c.retained[:0]
+}
@@ -0,0 +1,40 @@
+//go:build ed25519consensus_debug
+
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestAliasCheck(t *testing.T) {
+	v := NewBatchVerifier()
+	v.SetDeferredHashing(1)
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	buf := []byte("reused buffer")
+	v.Add(pub, buf, ed25519.Sign(priv, buf))
+	copy(buf, "REUSED")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Verify did not panic on a modified message")
+		}
+	}()
+	v.Verify()
+}
+
+func TestAliasCheckAfterReset(t *testing.T) {
+	v := NewBatchVerifier()
+	v.SetDeferredHashing(1)
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	buf := []byte("reused buffer")
+	v.Add(pub, buf, ed25519.Sign(priv, buf))
+
+	// Discarding the batch releases buf, which may then be reused.
+	v.Reset()
+	copy(buf, "REUSED")
+	v.Add(pub, buf, ed25519.Sign(priv, buf))
+	if !v.Verify() {
+		t.Error("batch failed to verify after Reset")
+	}
+}
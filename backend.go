@@ -0,0 +1,41 @@
+package ed25519consensus
+
+import "filippo.io/edwards25519"
+
+// backend abstracts the variable-time curve arithmetic that dominates
+// verification, so that alternative implementations (assembly-accelerated,
+// formally verified, or hardware-offloaded) can be substituted without
+// changing the public API. Backends take and return edwards25519 values, and
+// convert to their own representation internally if they use one.
+//
+// A backend must compute exactly the same group elements as the generic one:
+// acceptance decisions are consensus-critical, so any difference is a bug.
+//
+// The backend is chosen at compile time. Exactly one file, selected with build
+// tags, defines selectedBackend as an alias for the backend in use. Calling it
+// through a concrete type rather than an interface value keeps calls
+// statically dispatched, so that Verify still does not allocate.
+type backend interface {
+	// varTimeDoubleScalarBaseMult sets v = a * A + b * B, where B is the
+	// canonical generator, and returns v.
+	varTimeDoubleScalarBaseMult(v *edwards25519.Point, a *edwards25519.Scalar, A *edwards25519.Point, b *edwards25519.Scalar) *edwards25519.Point
+
+	// varTimeMultiScalarMult sets v = sum(scalars[i] * points[i]), using the
+	// strategy selected by window as described in BatchVerifier.SetWindowSize
+	// where applicable, and returns v.
+	varTimeMultiScalarMult(v *edwards25519.Point, scalars []*edwards25519.Scalar, points []*edwards25519.Point, window int) *edwards25519.Point
+}
+
+var _ backend = selectedBackend{}
+
+// genericBackend implements backend with the portable arithmetic of
+// filippo.io/edwards25519.
+type genericBackend struct{}
+
+func (genericBackend) varTimeDoubleScalarBaseMult(v *edwards25519.Point, a *edwards25519.Scalar, A *edwards25519.Point, b *edwards25519.Scalar) *edwards25519.Point {
+	return v.VarTimeDoubleScalarBaseMult(a, A, b)
+}
+
+func (genericBackend) varTimeMultiScalarMult(v *edwards25519.Point, scalars []*edwards25519.Scalar, points []*edwards25519.Point, window int) *edwards25519.Point {
+	return multiScalarMult(v, scalars, points, window)
+}
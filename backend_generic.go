@@ -0,0 +1,5 @@
+package ed25519consensus
+
+// selectedBackend is the generic backend. Files adding another backend should
+// define selectedBackend under their own build tag, and exclude that tag here.
+type selectedBackend = genericBackend
@@ -0,0 +1,32 @@
+package ed25519consensus
+
+import (
+	"testing"
+
+	"filippo.io/edwards25519"
+)
+
+// TestSelectedBackend checks the backend in use against the constant-time
+// reference arithmetic of edwards25519.
+func TestSelectedBackend(t *testing.T) {
+	scalars, points := randomTerms(t, 300)
+
+	B := edwards25519.NewGeneratorPoint()
+	for i := 0; i+1 < len(scalars); i += 2 {
+		got := selectedBackend{}.varTimeDoubleScalarBaseMult(new(edwards25519.Point), scalars[i], points[i], scalars[i+1])
+		want := new(edwards25519.Point).MultiScalarMult(scalars[i:i+2], []*edwards25519.Point{points[i], B})
+		if got.Equal(want) != 1 {
+			t.Fatalf("double scalar multiplication %d disagrees with the reference", i)
+		}
+	}
+
+	for _, w := range []int{StrausWindow, AutoWindow, 2, 7, maxWindow} {
+		for _, n := range []int{1, 17, len(scalars)} {
+			want := new(edwards25519.Point).MultiScalarMult(scalars[:n], points[:n])
+			got := selectedBackend{}.varTimeMultiScalarMult(new(edwards25519.Point), scalars[:n], points[:n], w)
+			if got.Equal(want) != 1 {
+				t.Errorf("multiscalar multiplication of %d terms with window %d disagrees with the reference", n, w)
+			}
+		}
+	}
+}
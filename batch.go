@@ -4,6 +4,13 @@ import (
 	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"math"
+	"runtime/debug"
+	"sync"
+	"time"
+	"unsafe"
 
 	"filippo.io/edwards25519"
 )
@@ -11,7 +18,68 @@ import (
 // BatchVerifier accumulates batch entries with Add, before performing batch
 // verification with Verify.
 type BatchVerifier struct {
-	entries []entry
+	entries   []entry
+	chunkSize int
+	window    int
+
+	offloader  MSMOffloader
+	offloadMin int
+
+	hashWorkers int
+
+	onFailure FailureCallback
+
+	// tags holds the tags of entries added with AddTagged, and is nil if
+	// there are none. It may be shorter than entries.
+	tags []any
+
+	randomizerBits int
+	deterministic  bool
+
+	aliases aliasChecker
+
+	maxEntries int
+	maxMemory  int
+	memory     int
+	overflowed bool // an entry was dropped by an Add method returning no error
+
+	cache     *VerificationCache
+	cacheHits int // entries not added because the cache knew them valid
+
+	policy Policy
+
+	scratch batchScratch
+}
+
+// batchScratch is the storage of the batch equation, kept across calls to
+// Verify so that a reused BatchVerifier does not allocate it every time.
+type batchScratch struct {
+	svals      []edwards25519.Scalar
+	scalars    []*edwards25519.Scalar
+	pvals      []edwards25519.Point
+	points     []*edwards25519.Point
+	randomness []byte
+}
+
+// get returns storage for terms scalars and points, and randomBytes bytes
+// of randomness, growing the scratch if needed. The scalars and points
+// hold values from the previous use.
+func (s *batchScratch) get(terms, randomBytes int) ([]*edwards25519.Scalar, []*edwards25519.Point, []byte) {
+	if len(s.svals) < terms {
+		s.svals = make([]edwards25519.Scalar, terms)
+		s.scalars = make([]*edwards25519.Scalar, terms)
+		s.pvals = make([]edwards25519.Point, terms)
+		s.points = make([]*edwards25519.Point, terms)
+		// Populate the pointers with concrete values to reduce heap allocation
+		for i := range s.scalars {
+			s.scalars[i] = &s.svals[i]
+			s.points[i] = &s.pvals[i]
+		}
+	}
+	if len(s.randomness) < randomBytes {
+		s.randomness = make([]byte, randomBytes)
+	}
+	return s.scalars[:terms], s.points[:terms], s.randomness[:randomBytes]
 }
 
 // entry represents a batch entry with the public key, signature and scalar
@@ -21,6 +89,35 @@ type entry struct {
 	pubkey    [ed25519.PublicKeySize]byte
 	signature [ed25519.SignatureSize]byte
 	digest    [64]byte
+
+	// message is retained until Verify when hashing is deferred, and nil
+	// once digest has been computed.
+	message []byte
+
+	// dom is the dom2 prefix of the challenge hash for Ed25519ph and
+	// Ed25519ctx entries, and nil for plain Ed25519 entries.
+	dom []byte
+
+	// retained is the message passed to Add, kept for the failure callback.
+	retained []byte
+
+	// parsed is the decoded signature for entries added with AddParsed.
+	parsed *ParsedSignature
+
+	// key is the decoded public key for entries added with AddSession or
+	// AddDecoded, used instead of pubkey by both the batch equation and check.
+	key *edwards25519.Point
+
+	// reason is why a well-formed entry that is not good was rejected by
+	// Add, such as a violation of the key policy.
+	reason error
+
+	// cofactorless selects the cofactorless equation, as by Policy.
+	cofactorless bool
+
+	// bound is true if digest is computed from the message by the batch,
+	// rather than provided by the caller, so that the entry may be cached.
+	bound bool
 }
 
 // NewBatchVerifier creates an empty BatchVerifier.
@@ -39,46 +136,716 @@ func NewPreallocatedBatchVerifier(size int) BatchVerifier {
 	}
 }
 
-// Add adds a (public key, message, sig) triple to the current batch. It retains
-// no reference to the inputs.
+// BatchOptions configures a BatchVerifier created by
+// NewBatchVerifierWithOptions. The zero value selects the defaults of
+// NewBatchVerifier, and each field has the meaning of the corresponding Set
+// method, which may also be called later.
+type BatchOptions struct {
+	// Capacity is the number of entries to preallocate, as with
+	// NewPreallocatedBatchVerifier.
+	Capacity int
+
+	// Deterministic derives the random coefficients from the entries
+	// instead of crypto/rand. See SetDeterministic.
+	Deterministic bool
+	// RandomizerWidth is 128, 192 or 256, or zero for the default of 128
+	// bits. See SetRandomizerWidth.
+	RandomizerWidth int
+
+	// Policy is enforced on the entries. See SetPolicy.
+	Policy Policy
+
+	// HashWorkers defers challenge hashing to Verify on that many
+	// goroutines. See SetDeferredHashing.
+	HashWorkers int
+	// ChunkSize bounds the number of entries per multiscalar
+	// multiplication. See SetChunkSize.
+	ChunkSize int
+}
+
+// NewBatchVerifierWithOptions creates an empty BatchVerifier configured by
+// opts. It panics if opts.RandomizerWidth is invalid.
+func NewBatchVerifierWithOptions(opts BatchOptions) BatchVerifier {
+	v := NewPreallocatedBatchVerifier(opts.Capacity)
+	v.SetDeterministic(opts.Deterministic)
+	if opts.RandomizerWidth != 0 {
+		v.SetRandomizerWidth(opts.RandomizerWidth)
+	}
+	v.SetPolicy(opts.Policy)
+	v.SetDeferredHashing(opts.HashWorkers)
+	v.SetChunkSize(opts.ChunkSize)
+	return v
+}
+
+// SetChunkSize configures v to verify its entries in chunks of at most n
+// entries, each checked with its own multiscalar multiplication, which bounds
+// the scratch memory used by Verify regardless of the batch size. Verify
+// returns true only if every chunk verifies.
+//
+// A size of zero (the default) verifies the whole batch at once. A negative
+// size selects a chunk size automatically from the runtime's soft memory limit
+// (see runtime/debug.SetMemoryLimit).
+func (v *BatchVerifier) SetChunkSize(n int) {
+	v.chunkSize = n
+}
+
+// SetWindowSize selects the multiscalar multiplication strategy used by Verify.
+//
+// A size between 2 and 16 selects Pippenger's bucket method with signed
+// windows of that many bits, which is fastest for large batches. StrausWindow
+// selects the interleaved sliding-window method, which is fastest for small
+// ones. AutoWindow (the default) chooses between them, and picks the
+// Pippenger window, from the number of entries being verified. The best
+// choice depends on the CPU, so callers verifying batches of a known size
+// may want to benchmark the alternatives.
+func (v *BatchVerifier) SetWindowSize(w int) {
+	if w < StrausWindow || w == 1 || w > maxWindow {
+		panic("ed25519consensus: invalid window size")
+	}
+	v.window = w
+}
+
+// SetRandomizerWidth sets the size in bits of the random coefficients z_i of
+// the batch equation to 128 (the default), 192 or 256. Forging a batch
+// containing an invalid signature succeeds with probability about 2^-bits
+// (or 2^-252 for 256-bit coefficients, which are uniform modulo the group
+// order), and wider coefficients make verification slower. SetRandomizerWidth
+// panics for any other width.
+func (v *BatchVerifier) SetRandomizerWidth(bits int) {
+	if bits != 128 && bits != 192 && bits != 256 {
+		panic("ed25519consensus: invalid randomizer width")
+	}
+	v.randomizerBits = bits
+}
+
+// SetDeterministic makes Verify derive the random coefficients z_i from a
+// hash of all the entries being verified (Fiat-Shamir), instead of reading
+// them from crypto/rand. Replicas verifying the same batch then perform
+// identical computations, and Verify works without an operating system RNG.
+//
+// Deterministic coefficients are sound as long as SHA-512 behaves as a random
+// oracle, since the entries are fixed before the coefficients are derived.
+func (v *BatchVerifier) SetDeterministic(on bool) {
+	v.deterministic = on
+}
+
+// MSMOffloader computes the multiscalar multiplications of batch verification
+// outside of this package, for example on a GPU.
+//
+// An MSMOffloader is fully trusted: it decides which signatures are accepted,
+// so an incorrect implementation can make invalid batches verify.
+type MSMOffloader interface {
+	// MultiScalarMult returns sum(scalars[i] * points[i]). It must not
+	// modify or retain the arguments. If it returns an error, the
+	// BatchVerifier computes the sum itself instead.
+	MultiScalarMult(scalars []*edwards25519.Scalar, points []*edwards25519.Point) (*edwards25519.Point, error)
+}
+
+// SetOffloader makes Verify hand the multiscalar multiplication of every batch
+// (or chunk, see SetChunkSize) of at least minSize entries to o, falling back
+// to the pure-Go implementation if o fails. A nil o disables offloading.
+func (v *BatchVerifier) SetOffloader(o MSMOffloader, minSize int) {
+	v.offloader = o
+	v.offloadMin = minSize
+}
+
+// SetDeferredHashing makes Add defer computing the SHA-512 challenge of each
+// entry to Verify, where it is spread across the given number of goroutines
+// and overlapped with point decompression. A count of zero (the default)
+// hashes in Add.
+//
+// While hashing is deferred, Add retains the message slice until the next
+// call to Verify returns, and the caller must not modify it in the meantime.
+func (v *BatchVerifier) SetDeferredHashing(workers int) {
+	v.hashWorkers = workers
+}
+
+// FailureCallback is called by Verify for each invalid entry of a failed
+// batch, with the entry's index in the batch, its inputs, and one of
+// ErrMalformedEntry, ErrInvalidKeyEncoding, ErrMalformedSignature or
+// ErrInvalidSignature, or the error of a violated policy (see SetKeyPolicy).
+// The public key and signature are nil for malformed entries.
+type FailureCallback func(index int, publicKey ed25519.PublicKey, message, sig []byte, reason error)
+
+var (
+	// ErrMalformedEntry means a batch entry had inputs of the wrong length,
+	// or another problem detected by Add.
+	ErrMalformedEntry = errors.New("ed25519consensus: malformed batch entry")
+	// ErrMalformedSignature means a signature has the wrong length, R is not
+	// a valid point encoding, or s is not reduced.
+	ErrMalformedSignature = errors.New("ed25519consensus: malformed signature")
+	// ErrInvalidSignature means the verification equation does not hold.
+	ErrInvalidSignature = errors.New("ed25519consensus: invalid signature")
+)
+
+// SetFailureCallback makes Verify, when the batch fails, check every entry
+// individually and call f for each invalid one, in order. This drives
+// pipelines such as evidence collection directly from the verifier, at the
+// cost of individual verification on failure. A nil f disables it.
+//
+// While a callback is set, Add retains the message slice until the next call
+// to Verify returns, and the caller must not modify it in the meantime.
+func (v *BatchVerifier) SetFailureCallback(f FailureCallback) {
+	v.onFailure = f
+}
+
+// ErrBatchFull is returned by TryAdd and TryAddWithContext when adding the
+// entry would exceed the limits set with SetLimits. The entry is not added.
+var ErrBatchFull = errors.New("ed25519consensus: batch is full")
+
+// entryMemory is the memory accounted for each entry, besides the messages
+// retained by the batch.
+const entryMemory = int(unsafe.Sizeof(entry{}))
+
+// SetLimits bounds the number of entries of the batch to maxEntries, and the
+// memory accounted for by MemoryUsage to maxMemory bytes, so that the
+// resources an unauthenticated peer can make the verifier hold are bounded.
+// Once a limit would be exceeded, TryAdd and TryAddWithContext return
+// ErrBatchFull without adding the entry, and the other Add methods drop the
+// entry and make Verify fail. A limit of zero (the default) is no limit.
+func (v *BatchVerifier) SetLimits(maxEntries, maxMemory int) {
+	v.maxEntries = maxEntries
+	v.maxMemory = maxMemory
+}
+
+// MemoryUsage returns the memory in bytes accounted for by the entries of the
+// batch: a fixed size per entry, plus the length of every message retained
+// by the batch, as with deferred hashing or a failure callback. Retained
+// messages are accounted for until the batch is discarded, even after Verify
+// releases them. The actual usage may be higher, as the entries slice grows
+// in steps.
+func (v *BatchVerifier) MemoryUsage() int {
+	return v.memory
+}
+
+// reserve accounts for n new entries retaining retained bytes of messages in
+// total, or returns ErrBatchFull if that would exceed the limits.
+func (v *BatchVerifier) reserve(n, retained int) error {
+	if !v.fits(n, retained) {
+		return ErrBatchFull
+	}
+	v.memory += n*entryMemory + retained
+	return nil
+}
+
+// fits reports whether n new entries retaining retained bytes of messages
+// in total are within the limits.
+func (v *BatchVerifier) fits(n, retained int) bool {
+	if v.maxEntries > 0 && len(v.entries)+n > v.maxEntries {
+		return false
+	}
+	return v.maxMemory == 0 || v.memory+n*entryMemory+retained <= v.maxMemory
+}
+
+// retains reports whether the batch retains the messages passed to Add.
+func (v *BatchVerifier) retains() bool {
+	return v.hashWorkers > 0 || v.onFailure != nil
+}
+
+// dropIfFull records that an entry was dropped if err is ErrBatchFull, for
+// the Add methods that cannot report it, so that Verify fails.
+func (v *BatchVerifier) dropIfFull(err error) {
+	if err != nil {
+		v.overflowed = true
+	}
+}
+
+// Reset empties the batch, so that it can be reused for the next one. It
+// keeps the configuration of the batch, and the storage of its entries and
+// of the batch equation, so that verifying batches of similar sizes block
+// after block does not allocate them again.
+func (v *BatchVerifier) Reset() {
+	v.entries = v.entries[:0]
+	v.tags = nil
+	v.memory = 0
+	v.overflowed = false
+	v.cacheHits = 0
+	v.aliases.reset()
+}
+
+// Grow grows the capacity of the batch, if necessary, to guarantee room for
+// another n entries, so that adding them does not reallocate the entries.
+// This extends NewPreallocatedBatchVerifier to batches whose size becomes
+// known while they are built, such as when a block's transaction count is
+// decoded. Grow does not reserve beyond the entry limit set with SetLimits.
+// It panics if n is negative.
+func (v *BatchVerifier) Grow(n int) {
+	if n < 0 {
+		panic("ed25519consensus: negative grow count")
+	}
+	if v.maxEntries > 0 && len(v.entries)+n > v.maxEntries {
+		n = v.maxEntries - len(v.entries)
+	}
+	if n <= cap(v.entries)-len(v.entries) {
+		return
+	}
+	grown := make([]entry, len(v.entries), len(v.entries)+n)
+	copy(grown, v.entries)
+	v.entries = grown
+}
+
+// Add adds a (public key, message, sig) triple to the current batch. The
+// public key and signature are copied. Unless hashing is deferred (see
+// SetDeferredHashing) or a failure callback is set, it retains no reference to
+// the message either; otherwise the message must not be modified until Verify
+// returns. Building with the ed25519consensus_debug tag makes Verify panic if
+// a retained message was modified.
+//
+// If the entry would exceed the limits set with SetLimits, Add drops it and
+// Verify fails; use TryAdd to detect this instead.
 func (v *BatchVerifier) Add(publicKey ed25519.PublicKey, message, sig []byte) {
-	// Compute the challenge upfront to store it in the fixed-size entry
-	// structure that can get allocated on the caller stack and avoid heap
-	// allocations. Also, avoid holding any reference to the arguments.
+	v.dropIfFull(v.add(publicKey, message, sig, nil, true))
+}
+
+// TryAdd is like Add, but returns ErrBatchFull without adding the entry if it
+// would exceed the limits set with SetLimits, leaving the batch unaffected.
+// Otherwise it returns nil, even for a malformed entry, which is added and
+// makes Verify fail as with Add.
+func (v *BatchVerifier) TryAdd(publicKey ed25519.PublicKey, message, sig []byte) error {
+	return v.add(publicKey, message, sig, nil, true)
+}
+
+// AddCopy is like Add, but copies the message if the batch retains it, so that
+// the caller may reuse all of its buffers as soon as AddCopy returns.
+func (v *BatchVerifier) AddCopy(publicKey ed25519.PublicKey, message, sig []byte) {
+	if v.retains() {
+		message = append([]byte{}, message...)
+	}
+	v.dropIfFull(v.add(publicKey, message, sig, nil, true))
+}
+
+// AddParsed is like Add, for a signature decoded with ParseSignature. Verify
+// then uses the decoded R and s instead of decoding them again. The batch
+// retains sig, which must not be modified.
+func (v *BatchVerifier) AddParsed(publicKey ed25519.PublicKey, message []byte, sig *ParsedSignature) {
+	if sig == nil {
+		v.dropIfFull(v.add(publicKey, message, nil, nil, false))
+		return
+	}
+	n := len(v.entries)
+	if err := v.add(publicKey, message, sig.encoding[:], nil, true); err != nil {
+		v.dropIfFull(err)
+		return
+	}
+	if len(v.entries) > n { // not skipped by the cache
+		v.entries[n].parsed = sig
+	}
+}
+
+// AddDecoded is like Add, for a public key already decoded to the point A, as
+// for VerifyDecoded. Verify then uses A instead of decoding publicKey again.
+// The batch retains A, which must not be modified.
+func (v *BatchVerifier) AddDecoded(publicKey ed25519.PublicKey, A *edwards25519.Point, message, sig []byte) {
+	if A == nil {
+		v.dropIfFull(v.add(publicKey, message, sig, nil, false))
+		return
+	}
+	n := len(v.entries)
+	if err := v.add(publicKey, message, sig, nil, true); err != nil {
+		v.dropIfFull(err)
+		return
+	}
+	if len(v.entries) > n { // not skipped by the cache
+		v.entries[n].key = A
+	}
+}
 
+// AddWithChallenge adds a (public key, signature) pair to the current batch,
+// together with its challenge k = SHA-512(R || A || M) mod L computed
+// elsewhere, for example with ComputeChallenge. The batch does not hash
+// anything for this entry, so the caller is responsible for k matching the
+// message.
+func (v *BatchVerifier) AddWithChallenge(publicKey ed25519.PublicKey, sig []byte, k *edwards25519.Scalar) {
+	if err := v.reserve(1, 0); err != nil {
+		v.dropIfFull(err)
+		return
+	}
 	v.entries = append(v.entries, entry{})
 	e := &v.entries[len(v.entries)-1]
 
-	if len(publicKey) != ed25519.PublicKeySize || len(sig) != ed25519.SignatureSize {
+	if k == nil || len(publicKey) != ed25519.PublicKeySize || len(sig) != ed25519.SignatureSize {
 		return
 	}
 
-	h := sha512.New()
-	h.Write(sig[:32])
-	h.Write(publicKey)
-	h.Write(message)
-	h.Sum(e.digest[:0])
+	copy(e.pubkey[:], publicKey)
+	copy(e.signature[:], sig)
+	e.cofactorless = v.policy.Cofactorless
+	if e.reason = v.checkPolicy(publicKey, sig, nil); e.reason != nil {
+		return
+	}
+	// A reduced scalar is its own wide reduction, so k zero-extended to 64
+	// bytes stands in for the digest.
+	copy(e.digest[:], k.Bytes())
+
+	e.good = true
+}
+
+// AddPH adds an Ed25519ph entry to the current batch: a public key, the
+// SHA-512 hash of a message, a signature and a context string. The entry is
+// verified as by VerifyPH.
+func (v *BatchVerifier) AddPH(publicKey ed25519.PublicKey, digest, sig []byte, context string) {
+	dom := dom2(1, context)
+	v.dropIfFull(v.add(publicKey, digest, sig, dom, dom != nil && len(digest) == sha512.Size))
+}
+
+// AddWithContext adds an Ed25519ctx entry to the current batch: a public key,
+// a message, a signature and a context string. The entry is verified as by
+// VerifyWithContext.
+func (v *BatchVerifier) AddWithContext(publicKey ed25519.PublicKey, message, sig []byte, context string) {
+	v.dropIfFull(v.TryAddWithContext(publicKey, message, sig, context))
+}
+
+// TryAddWithContext is like AddWithContext, but returns ErrBatchFull as
+// TryAdd does.
+func (v *BatchVerifier) TryAddWithContext(publicKey ed25519.PublicKey, message, sig []byte, context string) error {
+	if context == "" {
+		return v.add(publicKey, message, sig, nil, true)
+	}
+	dom := dom2(0, context)
+	return v.add(publicKey, message, sig, dom, dom != nil)
+}
+
+// add appends an entry to the batch, which is invalid unless ok is true, or
+// returns ErrBatchFull.
+func (v *BatchVerifier) add(publicKey ed25519.PublicKey, message, sig, dom []byte, ok bool) error {
+	// Unless hashing is deferred, compute the challenge upfront to store it
+	// in the fixed-size entry structure that can get allocated on the caller
+	// stack and avoid heap allocations. Also, avoid holding any reference to
+	// the arguments.
+
+	wellFormed := ok && len(publicKey) == ed25519.PublicKeySize && len(sig) == ed25519.SignatureSize
+	var reason error
+	if wellFormed {
+		reason = v.checkPolicy(publicKey, sig, dom)
+	}
+
+	var key cacheKey
+	cached := v.cache != nil && wellFormed && reason == nil && !v.policy.Cofactorless
+	if cached {
+		key.set(publicKey, sig, dom, message)
+		if valid, hit := v.cache.lookup(&key); hit && valid {
+			v.cacheHits++
+			return nil
+		}
+	}
+
+	retained := 0
+	if v.retains() {
+		retained = len(message)
+	}
+	if err := v.reserve(1, retained); err != nil {
+		return err
+	}
+
+	v.entries = append(v.entries, entry{})
+	e := &v.entries[len(v.entries)-1]
+	if v.onFailure != nil {
+		e.retained = message
+	}
+	if wellFormed {
+		var digest *[64]byte
+		if cached {
+			digest = &key.digest
+		}
+		v.fillEntry(e, publicKey, message, sig, dom, reason, digest)
+	}
+	if v.onFailure != nil || e.message != nil {
+		v.aliases.record(len(v.entries)-1, message)
+	}
+	return nil
+}
 
+// fillEntry sets the zero entry e to a well-formed entry, which is invalid
+// with the given reason if it is not nil. The challenge digest is copied from
+// digest if not nil, and otherwise computed or deferred.
+func (v *BatchVerifier) fillEntry(e *entry, publicKey ed25519.PublicKey, message, sig, dom []byte, reason error, digest *[64]byte) {
+	e.dom = dom
 	copy(e.pubkey[:], publicKey)
 	copy(e.signature[:], sig)
+	e.cofactorless = v.policy.Cofactorless
+	if reason != nil {
+		e.reason = reason
+		return
+	}
+	e.bound = true
+
+	if digest != nil {
+		e.digest = *digest
+	} else if v.hashWorkers > 0 {
+		// Keep a non-nil slice even for an empty message, to mark the
+		// digest as pending.
+		e.message = message[:len(message):len(message)]
+		if e.message == nil {
+			e.message = []byte{}
+		}
+	} else {
+		e.computeDigest(message)
+	}
 
 	e.good = true
 }
 
+// computeDigest sets e.digest to the SHA-512 hash of dom2 || R || A || M.
+func (e *entry) computeDigest(message []byte) {
+	h := sha512.New()
+	h.Write(e.dom)
+	h.Write(e.signature[:32])
+	h.Write(e.pubkey[:])
+	h.Write(message)
+	h.Sum(e.digest[:0])
+}
+
+// computeDeferredDigests starts computing the pending digests of entries in
+// the configured number of goroutines, tracked by wg.
+func (v *BatchVerifier) computeDeferredDigests(entries []entry, wg *sync.WaitGroup) {
+	pending := false
+	for i := range entries {
+		if entries[i].message != nil {
+			pending = true
+			break
+		}
+	}
+	if !pending {
+		return
+	}
+
+	workers := v.hashWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(entries) {
+		workers = len(entries)
+	}
+	for w := 0; w < workers; w++ {
+		start, end := w*len(entries)/workers, (w+1)*len(entries)/workers
+		wg.Add(1)
+		go func(entries []entry) {
+			defer wg.Done()
+			for i := range entries {
+				if e := &entries[i]; e.message != nil {
+					e.computeDigest(e.message)
+					e.message = nil
+				}
+			}
+		}(entries[start:end])
+	}
+}
+
 // Verify checks all entries in the current batch, returning true if all entries
 // are valid and false if any one entry is invalid.
 //
-// If a failure arises it is unknown which entry failed, the caller must verify
-// each entry individually.
+// Verify does not identify the invalid entries. To learn which entries
+// failed, set a callback with SetFailureCallback, or use VerifyTagged, which
+// return them after verifying every entry individually.
 //
-// Calling Verify on an empty batch returns false.
+// Calling Verify on an empty batch returns false, unless entries were added
+// that the cache set with SetCache knew to be valid.
 func (v *BatchVerifier) Verify() bool {
-	vl := len(v.entries)
 	// Abort early on an empty batch, which probably indicates a bug
-	if vl == 0 {
+	if len(v.entries) == 0 || v.overflowed {
+		return !v.overflowed && v.cacheHits > 0
+	}
+	v.aliases.check()
+	m := metrics.Load()
+	var start time.Time
+	if m != nil {
+		start = time.Now()
+	}
+	valid := v.verifyBatch()
+	if m != nil {
+		m.c.ObserveBatch(len(v.entries), valid, time.Since(start))
+	}
+	v.traceEntries(valid)
+	if valid {
+		if v.cache != nil {
+			v.cacheStoreValid()
+		}
+		return true
+	}
+	if v.onFailure != nil || m != nil {
+		v.forEachFailure(func(i int, e *entry, err error) {
+			if v.onFailure != nil {
+				v.reportFailure(i, e, err)
+			}
+			if m != nil {
+				m.c.ObserveFailure(err)
+			}
+		})
+	}
+	return false
+}
+
+// verifyBatch checks the batch equation over all entries, in chunks if
+// configured to.
+func (v *BatchVerifier) verifyBatch() bool {
+	vl := len(v.entries)
+
+	chunkSize := v.chunkSize
+	if chunkSize < 0 {
+		chunkSize = autoChunkSize()
+	}
+	if chunkSize == 0 || chunkSize >= vl {
+		return v.verifyEntries(v.entries)
+	}
+
+	for start := 0; start < vl; start += chunkSize {
+		end := start + chunkSize
+		if end > vl {
+			end = vl
+		}
+		if !v.verifyEntries(v.entries[start:end]) {
+			return false
+		}
+	}
+	return true
+}
+
+// reportFailure calls the failure callback for the invalid entry e at index
+// i.
+func (v *BatchVerifier) reportFailure(i int, e *entry, err error) {
+	if err == ErrMalformedEntry {
+		v.onFailure(i, nil, e.retained, nil, err)
+		return
+	}
+	v.onFailure(i, e.pubkey[:], e.retained, e.signature[:], err)
+}
+
+// forEachFailure verifies each entry individually, calling f for each invalid
+// one with the reason it is invalid.
+func (v *BatchVerifier) forEachFailure(f func(i int, e *entry, err error)) {
+	for i := range v.entries {
+		e := &v.entries[i]
+		if !e.good {
+			f(i, e, e.rejection())
+			continue
+		}
+		if err := e.check(); err != nil {
+			f(i, e, err)
+		}
+	}
+}
+
+// rejection returns why Add rejected an entry that is not good.
+func (e *entry) rejection() error {
+	if e.reason != nil {
+		return e.reason
+	}
+	return ErrMalformedEntry
+}
+
+// check verifies a well-formed entry on its own, and returns the reason it
+// is invalid, if any.
+func (e *entry) check() error {
+	if e.message != nil {
+		// The batch failed before the digest of this entry was computed.
+		e.computeDigest(e.message)
+		e.message = nil
+	}
+	// Use the decoded key and signature, if any, as the batch equation does.
+	A := e.key
+	if A == nil {
+		var err error
+		if A, err = new(edwards25519.Point).SetBytes(e.pubkey[:]); err != nil {
+			return ErrInvalidKeyEncoding
+		}
+	}
+	var R *edwards25519.Point
+	var s *edwards25519.Scalar
+	if e.parsed != nil {
+		R, s = &e.parsed.R, &e.parsed.s
+	} else {
+		var err error
+		if R, err = new(edwards25519.Point).SetBytes(e.signature[:32]); err != nil {
+			return ErrMalformedSignature
+		}
+		if s, err = new(edwards25519.Scalar).SetCanonicalBytes(e.signature[32:]); err != nil {
+			return ErrMalformedSignature
+		}
+	}
+	k, _ := new(edwards25519.Scalar).SetUniformBytes(e.digest[:])
+	if e.cofactorless {
+		if !verifyCofactorless(A, R, s, k) {
+			return ErrInvalidSignature
+		}
+	} else if !verifyEquation(A, R, s, k, false) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+const (
+	// entryScratchBytes approximates the memory used by Verify for each
+	// entry: two scalars and two points, their pointers, and the lookup
+	// tables built by the multiscalar multiplication.
+	entryScratchBytes = 4096
+
+	// defaultChunkSize is used by autoChunkSize when no memory limit is set.
+	defaultChunkSize = 16384
+
+	// minChunkSize keeps the automatic chunk size from degenerating into
+	// individual verification under very tight memory limits.
+	minChunkSize = 64
+)
+
+// autoChunkSize picks a chunk size whose scratch memory is at most an eighth
+// of the runtime's soft memory limit.
+func autoChunkSize() int {
+	limit := debug.SetMemoryLimit(-1)
+	if limit == math.MaxInt64 {
+		return defaultChunkSize
+	}
+	n := limit / 8 / entryScratchBytes
+	if n < minChunkSize {
+		return minChunkSize
+	}
+	if n > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int(n)
+}
+
+// minBatchSize is the smallest number of entries for which the batch
+// equation is cheaper than individual verification. It was measured with
+// BenchmarkBatch and BenchmarkVerification on amd64: a single entry costs
+// about 25% more as a batch, while two entries cost about the same.
+const minBatchSize = 2
+
+// ShouldBatch reports whether verifying n signatures with the batch equation
+// is expected to be faster than verifying them individually. Verify applies
+// it to each batch or chunk, so callers only need it to decide whether to
+// build a BatchVerifier at all.
+func ShouldBatch(n int) bool {
+	return n >= minBatchSize
+}
+
+// verifyEntries checks the batch equation over entries, which must not be
+// empty, or verifies them individually if there are too few of them.
+func (v *BatchVerifier) verifyEntries(entries []entry) bool {
+	vl := len(entries)
+	if !ShouldBatch(vl) || v.policy.Cofactorless {
+		for i := range entries {
+			if !entries[i].good || entries[i].check() != nil {
+				return false
+			}
+		}
+		return true
+	}
+
+	scalars, points, ok := v.batchEquation(entries)
+	if !ok {
 		return false
 	}
+	check := v.multiScalarMult(scalars, points)
+	check.MultByCofactor(check)
+	return check.Equal(edwards25519.NewIdentityPoint()) == 1
+}
 
+// batchEquation returns the terms of the batch equation over entries, whose
+// sum must be a small-order point for the batch to be valid, or false if an
+// entry is malformed or randomness could not be read. The returned slices
+// are scratch storage of v, valid until the next use of the scratch.
+func (v *BatchVerifier) batchEquation(entries []entry) ([]*edwards25519.Scalar, []*edwards25519.Point, bool) {
+	vl := len(entries)
 	// The batch verification equation is
 	//
 	// [-sum(z_i * s_i)]B + sum([z_i]R_i) + sum([z_i * k_i]A_i) = 0.
@@ -87,65 +854,219 @@ func (v *BatchVerifier) Verify() bool {
 	// - R_i is the signature's R value;
 	// - s_i is the signature's s value;
 	// - k_i is the hash of the message and other data;
-	// - z_i is a random 128-bit Scalar.
-	svals := make([]edwards25519.Scalar, 1+vl+vl)
-	scalars := make([]*edwards25519.Scalar, 1+vl+vl)
-
-	// Populate scalars variable with concrete scalars to reduce heap allocation
-	for i := range scalars {
-		scalars[i] = &svals[i]
+	// - z_i is a random 128-bit Scalar (see SetRandomizerWidth).
+	//
+	// Each coefficient is made of n bytes of randomness, or reduced from
+	// 64 bytes for full-width coefficients. The randomness of the whole
+	// batch is read at once.
+	n := 16
+	if v.randomizerBits != 0 {
+		n = v.randomizerBits / 8
+	}
+	if n == 32 {
+		n = 64
 	}
+	scalars, points, randomness := v.scratch.get(1+vl+vl, vl*n)
 
 	Bcoeff := scalars[0]
 	Rcoeffs := scalars[1 : 1+vl]
 	Acoeffs := scalars[1+vl:]
+	// The other coefficients are overwritten below, but Bcoeff is a sum.
+	Bcoeff.Set(edwards25519.NewScalar())
 
-	pvals := make([]edwards25519.Point, 1+vl+vl)
-	points := make([]*edwards25519.Point, 1+vl+vl)
-	for i := range points {
-		points[i] = &pvals[i]
-	}
 	B := points[0]
 	Rs := points[1 : 1+vl]
 	As := points[1+vl:]
 
+	// Hash any deferred entries while decoding points. Only the digest and
+	// message fields are written concurrently, so the loop below must not
+	// read them until the hashing goroutines are done.
+	var wg sync.WaitGroup
+	v.computeDeferredDigests(entries, &wg)
+	defer wg.Wait()
+	if v.deterministic {
+		// Deterministic coefficients are derived from every entry, so all
+		// the digests must be known first.
+		wg.Wait()
+		var transcript [64]byte
+		if !batchTranscript(entries, &transcript) {
+			return nil, nil, false
+		}
+		deriveRandomness(randomness, n, &transcript)
+	} else if _, err := rand.Read(randomness); err != nil {
+		return nil, nil, false
+	}
 	buf := make([]byte, 32)
+
 	B.Set(edwards25519.NewGeneratorPoint())
-	for i, entry := range v.entries {
+	for i := range entries {
+		entry := &entries[i]
 		if !entry.good {
-			return false
+			return nil, nil, false
 		}
 
-		if _, err := Rs[i].SetBytes(entry.signature[:32]); err != nil {
-			return false
+		var s edwards25519.Scalar
+		if entry.parsed != nil {
+			Rs[i].Set(&entry.parsed.R)
+			s.Set(&entry.parsed.s)
+		} else {
+			if _, err := Rs[i].SetBytes(entry.signature[:32]); err != nil {
+				return nil, nil, false
+			}
+			if _, err := s.SetCanonicalBytes(entry.signature[32:]); err != nil {
+				return nil, nil, false
+			}
 		}
 
-		if _, err := As[i].SetBytes(entry.pubkey[:]); err != nil {
-			return false
+		if entry.key != nil {
+			As[i].Set(entry.key)
+		} else if err := decodeKey(As[i], entry.pubkey[:]); err != nil {
+			return nil, nil, false
 		}
 
-		if _, err := rand.Read(buf[:16]); err != nil {
-			return false
-		}
-		if _, err := Rcoeffs[i].SetCanonicalBytes(buf); err != nil {
-			return false
+		if err := setRandomizer(Rcoeffs[i], randomness[i*n:(i+1)*n], buf); err != nil {
+			return nil, nil, false
 		}
 
-		s, err := new(edwards25519.Scalar).SetCanonicalBytes(entry.signature[32:])
+		Bcoeff.MultiplyAdd(Rcoeffs[i], &s, Bcoeff)
+	}
+	Bcoeff.Negate(Bcoeff) // this term is subtracted in the summation
+
+	wg.Wait()
+	for i := range entries {
+		k, err := new(edwards25519.Scalar).SetUniformBytes(entries[i].digest[:])
 		if err != nil {
-			return false
+			return nil, nil, false
 		}
-		Bcoeff.MultiplyAdd(Rcoeffs[i], s, Bcoeff)
+		Acoeffs[i].Multiply(Rcoeffs[i], k)
+	}
 
-		k, err := new(edwards25519.Scalar).SetUniformBytes(entry.digest[:])
-		if err != nil {
+	// Identical entries, such as votes received over several gossip paths,
+	// are merged into a single pair of terms by summing their coefficients,
+	// as z_i*R + z_j*R = (z_i + z_j)*R and likewise for A.
+	if first := duplicateEntries(entries); first != nil {
+		merged := make([]*edwards25519.Scalar, 1, len(scalars))
+		mergedPoints := make([]*edwards25519.Point, 1, len(points))
+		merged[0], mergedPoints[0] = Bcoeff, B
+		for i, j := range first {
+			if j != i {
+				Rcoeffs[j].Add(Rcoeffs[j], Rcoeffs[i])
+				Acoeffs[j].Add(Acoeffs[j], Acoeffs[i])
+			}
+		}
+		for _, terms := range [2]struct {
+			coeffs []*edwards25519.Scalar
+			points []*edwards25519.Point
+		}{{Rcoeffs, Rs}, {Acoeffs, As}} {
+			for i, j := range first {
+				if j == i {
+					merged = append(merged, terms.coeffs[i])
+					mergedPoints = append(mergedPoints, terms.points[i])
+				}
+			}
+		}
+		scalars, points = merged, mergedPoints
+	}
+
+	return scalars, points, true
+}
+
+// duplicateEntries returns, for each entry, the index of the first entry
+// identical to it, or nil if all entries are distinct. The digests of the
+// entries must be computed.
+func duplicateEntries(entries []entry) []int {
+	seen := make(map[cacheKey]int, len(entries))
+	var first []int
+	for i := range entries {
+		e := &entries[i]
+		k := cacheKey{pubkey: e.pubkey, signature: e.signature, digest: e.digest}
+		j, ok := seen[k]
+		if !ok {
+			seen[k] = i
+			j = i
+		}
+		if j != i && first == nil {
+			first = make([]int, len(entries))
+			for l := 0; l < i; l++ {
+				first[l] = l
+			}
+		}
+		if first != nil {
+			first[i] = j
+		}
+	}
+	return first
+}
+
+// batchTranscriptDomain separates the transcript hash of deterministic
+// coefficients from other uses of SHA-512.
+const batchTranscriptDomain = "ed25519consensus deterministic batch v1"
+
+// batchTranscript sets t to a hash binding every public key, signature and
+// challenge of entries, and reports whether all entries are well-formed.
+func batchTranscript(entries []entry, t *[64]byte) bool {
+	h := sha512.New()
+	h.Write([]byte(batchTranscriptDomain))
+	for i := range entries {
+		e := &entries[i]
+		if !e.good {
 			return false
 		}
-		Acoeffs[i].Multiply(Rcoeffs[i], k)
+		h.Write(e.pubkey[:])
+		h.Write(e.signature[:])
+		h.Write(e.digest[:])
 	}
-	Bcoeff.Negate(Bcoeff) // this term is subtracted in the summation
+	h.Sum(t[:0])
+	return true
+}
 
-	check := new(edwards25519.Point).VarTimeMultiScalarMult(scalars, points)
-	check.MultByCofactor(check)
-	return check.Equal(edwards25519.NewIdentityPoint()) == 1
+// deriveRandomness fills randomness with n bytes for each entry, derived
+// from the batch transcript.
+func deriveRandomness(randomness []byte, n int, transcript *[64]byte) {
+	h := sha512.New()
+	var index [8]byte
+	var digest [64]byte
+	for i := 0; i*n < len(randomness); i++ {
+		h.Reset()
+		h.Write(transcript[:])
+		binary.LittleEndian.PutUint64(index[:], uint64(i))
+		h.Write(index[:])
+		copy(randomness[i*n:(i+1)*n], h.Sum(digest[:0]))
+	}
+}
+
+// errZeroRandomizer is returned by setRandomizer for a zero coefficient,
+// which would drop its entry from the batch equation. It only happens by
+// chance with negligible probability, so it signals a broken entropy source.
+var errZeroRandomizer = errors.New("ed25519consensus: zero batch coefficient")
+
+// setRandomizer sets z to the coefficient made from b, which is reduced if
+// it is 64 bytes long, and otherwise taken as a little-endian integer of at
+// most 32 bytes. buf must be 32 bytes long.
+func setRandomizer(z *edwards25519.Scalar, b, buf []byte) error {
+	var err error
+	if len(b) == 64 {
+		_, err = z.SetUniformBytes(b)
+	} else {
+		for i := copy(buf, b); i < len(buf); i++ {
+			buf[i] = 0
+		}
+		_, err = z.SetCanonicalBytes(buf)
+	}
+	if err == nil && z.Equal(edwards25519.NewScalar()) == 1 {
+		err = errZeroRandomizer
+	}
+	return err
+}
+
+// multiScalarMult computes sum(scalars[i] * points[i]), offloading it if
+// configured to.
+func (v *BatchVerifier) multiScalarMult(scalars []*edwards25519.Scalar, points []*edwards25519.Point) *edwards25519.Point {
+	// Each entry contributes two terms, plus one for the basepoint.
+	if v.offloader != nil && len(scalars)/2 >= v.offloadMin {
+		if p, err := v.offloader.MultiScalarMult(scalars, points); err == nil {
+			return p
+		}
+	}
+	return selectedBackend{}.varTimeMultiScalarMult(new(edwards25519.Point), scalars, points, v.window)
 }
@@ -0,0 +1,44 @@
+//go:build go1.20
+
+package ed25519consensus
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"testing"
+)
+
+// These tests sign with ed25519.Options.Context, which was added in Go 1.20.
+
+func TestBatchMixedVariants(t *testing.T) {
+	for _, workers := range []int{0, 2} {
+		v := NewBatchVerifier()
+		v.SetDeferredHashing(workers)
+		populateBatchVerifier(t, &v)
+
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		msg := []byte("message")
+		digest := sha512.Sum512(msg)
+		phSig, _ := priv.Sign(nil, digest[:], &ed25519.Options{Hash: crypto.SHA512, Context: "ph"})
+		ctxSig, _ := priv.Sign(nil, msg, &ed25519.Options{Context: "ctx"})
+		v.AddPH(pub, digest[:], phSig, "ph")
+		v.AddWithContext(pub, msg, ctxSig, "ctx")
+		v.AddWithContext(pub, msg, ed25519.Sign(priv, msg), "")
+		if !v.Verify() {
+			t.Errorf("failed batch verification of mixed variants with %d hashing workers", workers)
+		}
+
+		v.AddWithContext(pub, msg, phSig, "ph")
+		if v.Verify() {
+			t.Errorf("batch verification with %d hashing workers should fail due to mismatched variant", workers)
+		}
+	}
+
+	v := NewBatchVerifier()
+	pub, _, _ := ed25519.GenerateKey(nil)
+	v.AddPH(pub, []byte("not a digest"), make([]byte, 64), "")
+	if v.Verify() {
+		t.Error("batch verification should fail due to short digest")
+	}
+}
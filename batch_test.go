@@ -1,9 +1,14 @@
 package ed25519consensus
 
 import (
+	"bytes"
 	"crypto/ed25519"
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
+
+	"filippo.io/edwards25519"
 )
 
 func TestBatch(t *testing.T) {
@@ -50,6 +55,379 @@ func TestBatchFailsOnCorruptSignature(t *testing.T) {
 	}
 }
 
+func TestBatchChunked(t *testing.T) {
+	for _, size := range []int{1, 7, 38, 39, 100, -1} {
+		v := NewBatchVerifier()
+		populateBatchVerifier(t, &v)
+		v.SetChunkSize(size)
+		if !v.Verify() {
+			t.Errorf("failed chunked batch verification with chunk size %d", size)
+		}
+
+		// corrupt an entry in the last chunk
+		v.entries[37].signature[1] ^= 1
+		if v.Verify() {
+			t.Errorf("chunked batch verification with chunk size %d should fail due to corrupt signature", size)
+		}
+	}
+}
+
+// testOffloader computes sums with edwards25519, or fails if err is set.
+type testOffloader struct {
+	calls int
+	err   error
+}
+
+func (o *testOffloader) MultiScalarMult(scalars []*edwards25519.Scalar, points []*edwards25519.Point) (*edwards25519.Point, error) {
+	o.calls++
+	if o.err != nil {
+		return nil, o.err
+	}
+	return new(edwards25519.Point).VarTimeMultiScalarMult(scalars, points), nil
+}
+
+func TestBatchDeferredHashing(t *testing.T) {
+	for _, workers := range []int{1, 3, 100} {
+		v := NewBatchVerifier()
+		v.SetDeferredHashing(workers)
+		populateBatchVerifier(t, &v)
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		v.Add(pub, nil, ed25519.Sign(priv, nil))
+		if !v.Verify() {
+			t.Errorf("failed batch verification with %d hashing workers", workers)
+		}
+		if v.Verify(); v.entries[7].message != nil {
+			t.Error("deferred message retained after Verify")
+		}
+
+		populateBatchVerifier(t, &v)
+		v.entries[5].message = []byte("another message")
+		if v.Verify() {
+			t.Errorf("batch verification with %d hashing workers should fail due to wrong message", workers)
+		}
+
+		// Disabling deferred hashing must still hash pending entries.
+		populateBatchVerifier(t, &v)
+		v.SetDeferredHashing(0)
+		if !v.Verify() {
+			t.Error("failed batch verification after disabling deferred hashing")
+		}
+	}
+}
+
+func TestBatchAddCopy(t *testing.T) {
+	v := NewBatchVerifier()
+	v.SetDeferredHashing(1)
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	buf := []byte("reused buffer")
+	v.AddCopy(pub, buf, ed25519.Sign(priv, buf))
+	copy(buf, "REUSED")
+	if !v.Verify() {
+		t.Error("batch verification failed after reusing the message buffer")
+	}
+}
+
+func TestBatchLimits(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("limited")
+	sig := ed25519.Sign(priv, msg)
+
+	v := NewBatchVerifier()
+	v.SetLimits(2, 0)
+	for i := 0; i < 2; i++ {
+		if err := v.TryAdd(pub, msg, sig); err != nil {
+			t.Fatalf("TryAdd %d: %v", i, err)
+		}
+	}
+	if err := v.TryAdd(pub, msg, sig); err != ErrBatchFull {
+		t.Errorf("TryAdd beyond the entry limit: got %v, want ErrBatchFull", err)
+	}
+	if len(v.entries) != 2 || v.MemoryUsage() != 2*entryMemory {
+		t.Errorf("got %d entries using %d bytes", len(v.entries), v.MemoryUsage())
+	}
+	if !v.Verify() {
+		t.Error("batch failed to verify")
+	}
+
+	// Add cannot report the full batch, so it makes Verify fail.
+	v.Add(pub, msg, sig)
+	if len(v.entries) != 2 {
+		t.Errorf("Add beyond the entry limit added an entry")
+	}
+	if v.Verify() {
+		t.Error("batch with a dropped entry verified")
+	}
+
+	// Retained messages count towards the memory limit.
+	v = NewBatchVerifier()
+	v.SetDeferredHashing(1)
+	v.SetLimits(0, 2*entryMemory+100)
+	if err := v.TryAdd(pub, make([]byte, 50), sig); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.TryAddWithContext(pub, make([]byte, 51), sig, "ctx"); err != ErrBatchFull {
+		t.Errorf("TryAddWithContext beyond the memory limit: got %v, want ErrBatchFull", err)
+	}
+	v.AddWithChallenge(pub, sig, ComputeChallenge(sig[:32], pub, msg))
+	if got, want := v.MemoryUsage(), 2*entryMemory+50; got != want {
+		t.Errorf("MemoryUsage() = %d, want %d", got, want)
+	}
+}
+
+func TestBatchAddWithChallenge(t *testing.T) {
+	for _, workers := range []int{0, 2} {
+		v := NewBatchVerifier()
+		v.SetDeferredHashing(workers)
+		populateBatchVerifier(t, &v)
+
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		msg := []byte("hashed elsewhere")
+		sig := ed25519.Sign(priv, msg)
+		v.AddWithChallenge(pub, sig, ComputeChallenge(sig[:32], pub, msg))
+		if !v.Verify() {
+			t.Errorf("failed batch verification with %d hashing workers", workers)
+		}
+
+		v.AddWithChallenge(pub, sig, ComputeChallenge(sig[:32], pub, []byte("other")))
+		if v.Verify() {
+			t.Errorf("batch verification with %d hashing workers should fail due to wrong challenge", workers)
+		}
+
+		populateBatchVerifier(t, &v)
+		v.AddWithChallenge(pub, sig, nil)
+		if v.Verify() {
+			t.Error("batch verification should fail due to missing challenge")
+		}
+	}
+}
+
+func TestBatchRandomizerWidth(t *testing.T) {
+	for _, bits := range []int{128, 192, 256} {
+		v := NewBatchVerifier()
+		v.SetRandomizerWidth(bits)
+		populateBatchVerifier(t, &v)
+		if !v.Verify() {
+			t.Errorf("failed batch verification with %d-bit randomizers", bits)
+		}
+		v.entries[4].signature[1] ^= 1
+		if v.Verify() {
+			t.Errorf("batch verification with %d-bit randomizers should fail due to corrupt signature", bits)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("SetRandomizerWidth accepted a 64-bit width")
+		}
+	}()
+	v := NewBatchVerifier()
+	v.SetRandomizerWidth(64)
+}
+
+func TestBatchDeterministic(t *testing.T) {
+	for _, bits := range []int{128, 256} {
+		for _, workers := range []int{0, 2} {
+			v := NewBatchVerifier()
+			v.SetDeterministic(true)
+			v.SetRandomizerWidth(bits)
+			v.SetDeferredHashing(workers)
+			populateBatchVerifier(t, &v)
+			if !v.Verify() {
+				t.Errorf("failed deterministic batch verification with %d-bit coefficients", bits)
+			}
+
+			populateBatchVerifier(t, &v)
+			v.entries[4].signature[1] ^= 1
+			if v.Verify() {
+				t.Error("deterministic batch verification should fail due to corrupt signature")
+			}
+		}
+	}
+
+	// The same entries produce the same coefficients.
+	v := NewBatchVerifier()
+	populateBatchVerifier(t, &v)
+	var t1, t2 [64]byte
+	batchTranscript(v.entries, &t1)
+	batchTranscript(v.entries, &t2)
+	r1, r2 := make([]byte, 16*len(v.entries)), make([]byte, 16*len(v.entries))
+	deriveRandomness(r1, 16, &t1)
+	deriveRandomness(r2, 16, &t2)
+	if !bytes.Equal(r1, r2) {
+		t.Error("deterministic coefficients differ for the same batch")
+	}
+	if bytes.Equal(r1[:16], r1[16:32]) {
+		t.Error("deterministic coefficients repeat across entries")
+	}
+}
+
+func TestNewBatchVerifierWithOptions(t *testing.T) {
+	opts := BatchOptions{
+		Capacity:        64,
+		Deterministic:   true,
+		RandomizerWidth: 192,
+		Policy:          Policy{RequireCanonicalR: true},
+		HashWorkers:     2,
+		ChunkSize:       16,
+	}
+	v := NewBatchVerifierWithOptions(opts)
+	if cap(v.entries) != 64 || !v.deterministic || v.randomizerBits != 192 ||
+		!v.policy.RequireCanonicalR || v.hashWorkers != 2 || v.chunkSize != 16 {
+		t.Errorf("options not applied: %+v", v)
+	}
+	populateBatchVerifier(t, &v)
+	if !v.Verify() {
+		t.Error("batch failed to verify")
+	}
+
+	if v := NewBatchVerifierWithOptions(BatchOptions{}); !reflect.DeepEqual(v, NewBatchVerifier()) {
+		t.Error("zero options differ from NewBatchVerifier")
+	}
+}
+
+func TestSetRandomizerRejectsZero(t *testing.T) {
+	z, buf := new(edwards25519.Scalar), make([]byte, 32)
+	for _, n := range []int{16, 24, 64} {
+		if err := setRandomizer(z, make([]byte, n), buf); err != errZeroRandomizer {
+			t.Errorf("%d zero bytes: got %v, want errZeroRandomizer", n, err)
+		}
+	}
+	if err := setRandomizer(z, []byte{1}, buf); err != nil || z.Equal(scalarFromInt(1)) != 1 {
+		t.Errorf("setRandomizer(1) = %v", err)
+	}
+}
+
+func TestBatchOffloader(t *testing.T) {
+	for _, o := range []*testOffloader{{}, {err: errors.New("device unavailable")}} {
+		v := NewBatchVerifier()
+		populateBatchVerifier(t, &v)
+		v.SetOffloader(o, 10)
+		v.SetChunkSize(13)
+		if !v.Verify() {
+			t.Errorf("failed batch verification with offloader error %v", o.err)
+		}
+		if o.calls != 3 {
+			t.Errorf("expected 3 offloaded chunks, got %d", o.calls)
+		}
+
+		v.entries[4].signature[1] ^= 1
+		if v.Verify() {
+			t.Errorf("batch verification with offloader error %v should fail due to corrupt signature", o.err)
+		}
+	}
+
+	o := &testOffloader{}
+	v := NewBatchVerifier()
+	populateBatchVerifier(t, &v)
+	v.SetOffloader(o, 40)
+	if !v.Verify() {
+		t.Error("failed batch verification")
+	}
+	if o.calls != 0 {
+		t.Errorf("batch below the offload threshold was offloaded")
+	}
+}
+
+func TestBatchFailureCallback(t *testing.T) {
+	type failure struct {
+		index  int
+		msg    string
+		reason error
+	}
+	for _, chunk := range []int{0, 5} {
+		var got []failure
+		v := NewBatchVerifier()
+		v.SetChunkSize(chunk)
+		v.SetDeferredHashing(2)
+		v.SetFailureCallback(func(index int, pub ed25519.PublicKey, msg, sig []byte, reason error) {
+			got = append(got, failure{index, string(msg), reason})
+		})
+
+		populateBatchVerifier(t, &v)
+		if !v.Verify() || got != nil {
+			t.Fatalf("valid batch reported failures: %v", got)
+		}
+
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		sig := ed25519.Sign(priv, []byte("signed"))
+		v.Add(pub, []byte("short"), sig[:63])
+		v.Add(pub, []byte("forged"), sig)
+		badS := append([]byte(nil), sig...)
+		badS[63] |= 0x10
+		v.Add(pub, []byte("unreduced"), badS)
+		v.entries[7].pubkey = decodeHex32(t, "0200000000000000000000000000000000000000000000000000000000000000")
+
+		if v.Verify() {
+			t.Fatal("batch verification should fail")
+		}
+		want := []failure{
+			{7, "egg", ErrInvalidKeyEncoding},
+			{39, "short", ErrMalformedEntry},
+			{40, "forged", ErrInvalidSignature},
+			{41, "unreduced", ErrMalformedSignature},
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("chunk size %d: got failures %v, want %v", chunk, got, want)
+		}
+	}
+}
+
+func TestBatchDuplicates(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("vote")
+	sig := ed25519.Sign(priv, msg)
+
+	v := NewBatchVerifier()
+	populateBatchVerifier(t, &v)
+	for i := 0; i < 3; i++ {
+		v.Add(pub, msg, sig)
+	}
+	first := duplicateEntries(v.entries)
+	if n := len(v.entries); first == nil || first[n-1] != n-3 || first[n-2] != n-3 || first[n-3] != n-3 {
+		t.Fatalf("duplicates not detected: %v", first)
+	}
+	if !v.Verify() {
+		t.Error("batch with duplicate entries rejected")
+	}
+
+	// A duplicated invalid entry still makes the batch fail.
+	v = NewBatchVerifier()
+	populateBatchVerifier(t, &v)
+	v.Add(pub, []byte("other"), sig)
+	v.Add(pub, []byte("other"), sig)
+	if v.Verify() {
+		t.Error("batch with duplicate invalid entries accepted")
+	}
+
+	populateBatchVerifier(t, &v)
+	if first := duplicateEntries(v.entries); first != nil {
+		t.Errorf("duplicates detected among distinct entries: %v", first)
+	}
+}
+
+func TestShouldBatch(t *testing.T) {
+	if ShouldBatch(1) || !ShouldBatch(2) || !ShouldBatch(1000) {
+		t.Error("unexpected ShouldBatch threshold")
+	}
+
+	// A single entry, and chunks of one, are verified individually.
+	for _, chunk := range []int{0, 1} {
+		v := NewBatchVerifier()
+		v.SetChunkSize(chunk)
+		v.SetDeferredHashing(1)
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		msg := []byte("alone")
+		v.Add(pub, msg, ed25519.Sign(priv, msg))
+		if !v.Verify() {
+			t.Errorf("chunk size %d: failed verification of a single entry", chunk)
+		}
+		v.Add(pub, []byte("other"), ed25519.Sign(priv, msg))
+		if v.Verify() {
+			t.Errorf("chunk size %d: verification should fail due to wrong message", chunk)
+		}
+	}
+}
+
 func TestEmptyBatchFails(t *testing.T) {
 	v := NewBatchVerifier()
 
@@ -58,6 +436,85 @@ func TestEmptyBatchFails(t *testing.T) {
 	}
 }
 
+func TestBatchReset(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("block")
+	sig := ed25519.Sign(priv, msg)
+	bad := append([]byte(nil), sig...)
+	bad[0] ^= 1
+
+	v := NewBatchVerifier()
+	v.SetDeterministic(true)
+	round := func(n int, invalid bool) bool {
+		v.Reset()
+		for i := 0; i < n; i++ {
+			v.Add(pub, msg, sig)
+		}
+		if invalid {
+			v.Add(pub, msg, bad)
+		}
+		return v.Verify()
+	}
+	for _, r := range []struct {
+		n       int
+		invalid bool
+	}{{64, false}, {64, true}, {16, false}, {100, false}, {8, true}, {64, false}} {
+		if got := round(r.n, r.invalid); got == r.invalid {
+			t.Errorf("round of %d entries, invalid %v: Verify = %v", r.n, r.invalid, got)
+		}
+	}
+
+	first := testing.AllocsPerRun(1, func() {
+		w := NewBatchVerifier()
+		for i := 0; i < 64; i++ {
+			w.Add(pub, msg, sig)
+		}
+		w.Verify()
+	})
+	reused := testing.AllocsPerRun(10, func() { round(64, false) })
+	if reused >= first {
+		t.Errorf("reused batch allocated %v times, a new one %v times", reused, first)
+	}
+}
+
+func TestBatchGrow(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("grow")
+	sig := ed25519.Sign(priv, msg)
+
+	v := NewBatchVerifier()
+	v.Add(pub, msg, sig)
+	// AllocsPerRun calls the function twice, adding 200 entries.
+	v.Grow(200)
+	if c := cap(v.entries); c < 201 {
+		t.Fatalf("capacity %d after Grow(200), want at least 201", c)
+	}
+	if allocs := testing.AllocsPerRun(1, func() {
+		for i := 0; i < 100; i++ {
+			v.Add(pub, msg, sig)
+		}
+	}); allocs > 0 {
+		t.Errorf("adding grown entries allocated %0.1f times", allocs)
+	}
+	if !v.Verify() {
+		t.Error("grown batch failed to verify")
+	}
+
+	v = NewBatchVerifier()
+	v.SetLimits(10, 0)
+	v.Grow(1000)
+	if c := cap(v.entries); c > 10 {
+		t.Errorf("capacity %d beyond the entry limit", c)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Grow(-1) did not panic")
+		}
+	}()
+	v.Grow(-1)
+}
+
 func BenchmarkBatch(b *testing.B) {
 	for _, n := range []int{1, 8, 64, 1024, 4096, 16384} {
 		b.Run(fmt.Sprint(n), func(b *testing.B) {
@@ -108,9 +565,32 @@ func BenchmarkPreallocatedBatch(b *testing.B) {
 	}
 }
 
-// populateBatchVerifier populates a verifier with multiple entries
+func BenchmarkCreateBatch(b *testing.B) {
+	for _, n := range []int{64, 1024, 16384} {
+		entries := testAddAllEntries(b, n)
+		b.Run(fmt.Sprintf("AddEntry/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				v := NewPreallocatedBatchVerifier(n)
+				for _, e := range entries {
+					v.AddEntry(e)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("AddAll/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				v := NewPreallocatedBatchVerifier(n)
+				if err := v.AddAll(entries); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// populateBatchVerifier replaces the entries of a verifier with multiple valid
+// entries, keeping its configuration
 func populateBatchVerifier(t *testing.T, v *BatchVerifier) {
-	*v = NewBatchVerifier()
+	v.entries = nil
 	for i := 0; i <= 38; i++ {
 
 		pub, priv, _ := ed25519.GenerateKey(nil)
@@ -0,0 +1,121 @@
+package ed25519consensus
+
+import (
+	"errors"
+	"strings"
+)
+
+// This file implements the Bech32 encoding of BIP 173, as used for keys by
+// Cosmos SDK chains. Unlike BIP 173, strings are not limited to 90
+// characters, so that signatures can be encoded too.
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var errInvalidBech32 = errors.New("ed25519consensus: invalid Bech32 string")
+
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := range gen {
+			if (top>>i)&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	out := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+// convertBits regroups data from groups of from bits into groups of to bits.
+// Without pad, leftover bits must be zero and fewer than from.
+func convertBits(data []byte, from, to uint, pad bool) ([]byte, bool) {
+	var acc, bits uint
+	maxv := uint(1)<<to - 1
+	out := make([]byte, 0, len(data)*int(from)/int(to)+1)
+	for _, b := range data {
+		if uint(b)>>from != 0 {
+			return nil, false
+		}
+		acc = acc<<from | uint(b)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(to-bits)&maxv))
+		}
+	} else if bits >= from || acc<<(to-bits)&maxv != 0 {
+		return nil, false
+	}
+	return out, true
+}
+
+// encodeBech32 encodes data with the human-readable part hrp, which must be
+// lowercase.
+func encodeBech32(hrp string, data []byte) (string, error) {
+	if len(hrp) == 0 || strings.ToLower(hrp) != hrp {
+		return "", errInvalidBech32
+	}
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", errInvalidBech32
+		}
+	}
+	values, _ := convertBits(data, 8, 5, true)
+	polymod := bech32Polymod(append(append(bech32HRPExpand(hrp), values...), 0, 0, 0, 0, 0, 0)) ^ 1
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, v := range values {
+		sb.WriteByte(bech32Charset[v])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(bech32Charset[polymod>>(5*(5-i))&31])
+	}
+	return sb.String(), nil
+}
+
+// decodeBech32 decodes s, checking that its human-readable part is hrp.
+func decodeBech32(s, hrp string) ([]byte, error) {
+	lower := strings.ToLower(s)
+	if lower != s && strings.ToUpper(s) != s {
+		return nil, errInvalidBech32
+	}
+	sep := strings.LastIndexByte(lower, '1')
+	if sep < 1 || sep+7 > len(lower) || lower[:sep] != hrp {
+		return nil, errInvalidBech32
+	}
+	values := make([]byte, 0, len(lower)-sep-1)
+	for i := sep + 1; i < len(lower); i++ {
+		v := strings.IndexByte(bech32Charset, lower[i])
+		if v < 0 {
+			return nil, errInvalidBech32
+		}
+		values = append(values, byte(v))
+	}
+	if bech32Polymod(append(bech32HRPExpand(lower[:sep]), values...)) != 1 {
+		return nil, errInvalidBech32
+	}
+	data, ok := convertBits(values[:len(values)-6], 5, 8, false)
+	if !ok {
+		return nil, errInvalidBech32
+	}
+	return data, nil
+}
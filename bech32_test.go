@@ -0,0 +1,41 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"testing"
+)
+
+func TestBech32(t *testing.T) {
+	// Valid checksums from BIP 173.
+	for _, s := range []string{"a12uel5l", "A12UEL5L", "abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw"} {
+		hrp := strings.ToLower(s[:strings.LastIndexByte(s, '1')])
+		if _, err := decodeBech32(s, hrp); err != nil {
+			t.Errorf("decodeBech32(%q): %v", s, err)
+		}
+	}
+	for _, s := range []string{"a12uel5m", "A12uEL5L", "a1uel5l", "abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxx"} {
+		if _, err := decodeBech32(s, "a"); err == nil {
+			t.Errorf("decodeBech32(%q) succeeded", s)
+		}
+	}
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	pk, _ := NewPublicKey(pub)
+	s, err := pk.Bech32("cosmosvalconspub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ParsePublicKeyBech32(s, "cosmosvalconspub"); err != nil || got != pk {
+		t.Errorf("ParsePublicKeyBech32(%q) = %v, %v", s, got, err)
+	}
+	if _, err := ParsePublicKeyBech32(s, "cosmos"); err == nil {
+		t.Error("ParsePublicKeyBech32 accepted the wrong prefix")
+	}
+
+	sig, _ := NewSignature(ed25519.Sign(priv, []byte("bech32")))
+	s, _ = sig.Bech32("sig")
+	if got, err := ParseSignatureBech32(s, "sig"); err != nil || got != sig {
+		t.Errorf("ParseSignatureBech32(%q) = %v, %v", s, got, err)
+	}
+}
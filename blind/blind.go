@@ -0,0 +1,152 @@
+//go:build go1.24
+
+// Package blind implements Ed25519 key blinding, as used by Tor onion
+// services (rend-spec-v3, Appendix A.2), which derives from a long-term key a
+// blinded key for each time period. Blinded keys for different periods cannot
+// be linked without the long-term public key, but anyone who knows it can
+// derive them and verify signatures.
+//
+// The blinding factor is computed with SHA3-256 from crypto/sha3, so this
+// package requires Go 1.24.
+package blind
+
+import (
+	"crypto/ed25519"
+	"crypto/sha3"
+	"crypto/sha512"
+	"encoding/binary"
+
+	"filippo.io/edwards25519"
+	"github.com/hdevalence/ed25519consensus"
+)
+
+const (
+	blindString      = "Derive temporary signing key\x00"
+	blindHashString  = "Derive temporary signing key hash input"
+	blindBasepointID = "(15112221349535400772501151409588531511454012693041857206046113283949847762202, 46316835694926478169428394003475163141307993866256225615783033603165251855960)"
+)
+
+// Factor returns the clamped blinding factor for the long-term key
+// pub in the given time period, as computed by Tor:
+//
+//	h = SHA3-256(BLIND_STRING | A | secret | B | "key-blind" | INT_8(period) | INT_8(periodLength))
+//
+// The optional secret is empty for Tor onion services.
+func Factor(pub ed25519.PublicKey, secret []byte, period, periodLength uint64) [32]byte {
+	h := sha3.New256()
+	h.Write([]byte(blindString))
+	h.Write(pub)
+	h.Write(secret)
+	h.Write([]byte(blindBasepointID))
+	h.Write([]byte("key-blind"))
+	h.Write(binary.BigEndian.AppendUint64(nil, period))
+	h.Write(binary.BigEndian.AppendUint64(nil, periodLength))
+
+	var factor [32]byte
+	h.Sum(factor[:0])
+	factor[0] &= 248
+	factor[31] &= 63
+	factor[31] |= 64
+	return factor
+}
+
+// PublicKey returns the blinded public key [h]A for the blinding factor h,
+// which must be clamped as by Factor.
+//
+// Like ed25519consensus.Verify, it accepts non-canonical encodings of A. Since h is a multiple
+// of 8, the blinded key never has a small-order component.
+func PublicKey(pub ed25519.PublicKey, blindingFactor [32]byte) (ed25519.PublicKey, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, ed25519consensus.ErrInvalidKeyLength
+	}
+	A, err := new(edwards25519.Point).SetBytes(pub)
+	if err != nil {
+		return nil, ed25519consensus.ErrInvalidKeyEncoding
+	}
+
+	// A clamped h is 8 * (h >> 3), and h >> 3 < 2^252 < l is a canonical
+	// scalar, so [h]A = [h >> 3][8]A without reducing h modulo l.
+	var eighth [32]byte
+	for i := range eighth {
+		eighth[i] = blindingFactor[i] >> 3
+		if i+1 < len(blindingFactor) {
+			eighth[i] |= blindingFactor[i+1] << 5
+		}
+	}
+	s, err := new(edwards25519.Scalar).SetCanonicalBytes(eighth[:])
+	if err != nil {
+		return nil, err
+	}
+	A.MultByCofactor(A)
+	return A.ScalarMult(s, A).Bytes(), nil
+}
+
+// Verify reports whether sig is a valid signature of message by the blinded
+// key derived from pub with blindingFactor, using the same validation
+// criteria as ed25519consensus.Verify.
+func Verify(pub ed25519.PublicKey, blindingFactor [32]byte, message, sig []byte) bool {
+	blinded, err := PublicKey(pub, blindingFactor)
+	if err != nil {
+		return false
+	}
+	return ed25519consensus.Verify(blinded, message, sig)
+}
+
+// PrivateKey is a private key blinded with NewPrivateKey. Unlike an
+// ed25519.PrivateKey it has no seed, only an expanded secret scalar and nonce
+// prefix.
+type PrivateKey struct {
+	s      edwards25519.Scalar
+	prefix [32]byte
+	public [32]byte
+}
+
+// NewPrivateKey blinds priv with the blinding factor h, which must be clamped
+// as by Factor:
+//
+//	a' = h * a mod l
+//	RH' = SHA-512(RH_BLIND_STRING | RH)[:32]
+func NewPrivateKey(priv ed25519.PrivateKey, blindingFactor [32]byte) *PrivateKey {
+	digest := sha512.Sum512(priv.Seed())
+	a, _ := new(edwards25519.Scalar).SetBytesWithClamping(digest[:32])
+	h, _ := new(edwards25519.Scalar).SetBytesWithClamping(blindingFactor[:])
+
+	k := new(PrivateKey)
+	k.s.Multiply(h, a)
+	prefixHash := sha512.New()
+	prefixHash.Write([]byte(blindHashString))
+	prefixHash.Write(digest[32:])
+	var prefix [64]byte
+	copy(k.prefix[:], prefixHash.Sum(prefix[:0]))
+	copy(k.public[:], new(edwards25519.Point).ScalarBaseMult(&k.s).Bytes())
+	return k
+}
+
+// Public returns the blinded public key, which equals the result of
+// PublicKey for the corresponding long-term public key.
+func (k *PrivateKey) Public() ed25519.PublicKey {
+	return append(ed25519.PublicKey(nil), k.public[:]...)
+}
+
+// Sign returns an Ed25519 signature of message by the blinded key.
+func (k *PrivateKey) Sign(message []byte) []byte {
+	var digest [64]byte
+	h := sha512.New()
+	h.Write(k.prefix[:])
+	h.Write(message)
+	h.Sum(digest[:0])
+	r, _ := new(edwards25519.Scalar).SetUniformBytes(digest[:])
+	R := new(edwards25519.Point).ScalarBaseMult(r)
+
+	h.Reset()
+	h.Write(R.Bytes())
+	h.Write(k.public[:])
+	h.Write(message)
+	h.Sum(digest[:0])
+	c, _ := new(edwards25519.Scalar).SetUniformBytes(digest[:])
+
+	S := new(edwards25519.Scalar).MultiplyAdd(c, &k.s, r)
+	sig := make([]byte, 0, ed25519.SignatureSize)
+	sig = append(sig, R.Bytes()...)
+	return append(sig, S.Bytes()...)
+}
@@ -0,0 +1,131 @@
+//go:build go1.24
+
+package blind
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha3"
+	"crypto/sha512"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/hdevalence/ed25519consensus"
+)
+
+func TestBlinding(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("onion service descriptor")
+
+	factor := Factor(pub, nil, 1234, 1440)
+	if factor == Factor(pub, nil, 1235, 1440) {
+		t.Error("blinding factors of different periods are equal")
+	}
+
+	blindedPub, err := PublicKey(pub, factor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blindedPriv := NewPrivateKey(priv, factor)
+	if !bytes.Equal(blindedPriv.Public(), blindedPub) {
+		t.Fatalf("blinded public keys differ: %x and %x", blindedPriv.Public(), blindedPub)
+	}
+
+	sig := blindedPriv.Sign(msg)
+	if !Verify(pub, factor, msg, sig) {
+		t.Error("blinded signature failed to verify")
+	}
+	if !ed25519.Verify(blindedPub, msg, sig) {
+		t.Error("blinded signature rejected by crypto/ed25519")
+	}
+	if Verify(pub, Factor(pub, nil, 1235, 1440), msg, sig) {
+		t.Error("blinded signature verified for another period")
+	}
+	if ed25519consensus.Verify(pub, msg, sig) {
+		t.Error("blinded signature verified under the long-term key")
+	}
+
+	// A torsion component of the long-term key does not change the blinded
+	// key, since the blinding factor is a multiple of 8.
+	order2, _ := hex.DecodeString("ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	A, _ := new(edwards25519.Point).SetBytes(pub)
+	T, _ := new(edwards25519.Point).SetBytes(order2)
+	mixed, err := PublicKey(A.Add(A, T).Bytes(), factor)
+	if err != nil || !bytes.Equal(mixed, blindedPub) {
+		t.Errorf("blinding a mixed-order key: got %x, %v", mixed, err)
+	}
+}
+
+// TestBlindingReference checks the blinding against a direct transcription
+// of rend-spec-v3, Appendix A.2, computed with math/big instead of the
+// edwards25519 scalar arithmetic and the cofactor shortcut used above.
+func TestBlindingReference(t *testing.T) {
+	l, _ := new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+	leInt := func(b []byte) *big.Int {
+		be := make([]byte, len(b))
+		for i := range b {
+			be[len(b)-1-i] = b[i]
+		}
+		return new(big.Int).SetBytes(be)
+	}
+	leBytes := func(x *big.Int) []byte {
+		b := make([]byte, 32)
+		x.FillBytes(b)
+		for i, j := 0, 31; i < j; i, j = i+1, j-1 {
+			b[i], b[j] = b[j], b[i]
+		}
+		return b
+	}
+
+	for i := 0; i < 8; i++ {
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		period := uint64(19000 + i)
+
+		// h = SHA3-256(BLIND_STRING | A | s | B | N), clamped.
+		var in []byte
+		in = append(in, "Derive temporary signing key\x00"...)
+		in = append(in, pub...)
+		in = append(in, "(15112221349535400772501151409588531511454012693041857206046113283949847762202, 46316835694926478169428394003475163141307993866256225615783033603165251855960)"...)
+		in = append(in, "key-blind"...)
+		in = append(in, 0, 0, 0, 0, 0, 0, byte(period>>8), byte(period))
+		in = append(in, 0, 0, 0, 0, 0, 0, 0x05, 0xa0)
+		h := sha3.Sum256(in)
+		h[0] &= 248
+		h[31] &= 63
+		h[31] |= 64
+		if got := Factor(pub, nil, period, 1440); got != h {
+			t.Fatalf("Factor = %x, want %x", got, h)
+		}
+
+		// a' = h * a mod l, A' = [a']B.
+		expanded := sha512.Sum512(priv.Seed())
+		expanded[0] &= 248
+		expanded[31] &= 63
+		expanded[31] |= 64
+		a := leInt(expanded[:32])
+		blindedScalar := new(big.Int).Mul(leInt(h[:]), a)
+		blindedScalar.Mod(blindedScalar, l)
+		s, err := new(edwards25519.Scalar).SetCanonicalBytes(leBytes(blindedScalar))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := new(edwards25519.Point).ScalarBaseMult(s).Bytes()
+
+		k := NewPrivateKey(priv, h)
+		if k.s.Equal(s) != 1 {
+			t.Errorf("blinded scalar = %x, want %x", k.s.Bytes(), s.Bytes())
+		}
+		if got, err := PublicKey(pub, h); err != nil || !bytes.Equal(got, want) {
+			t.Errorf("PublicKey = %x, %v, want %x", got, err, want)
+		}
+
+		// RH' = SHA-512(RH_BLIND_STRING | RH)[:32].
+		seedHash := sha512.Sum512(priv.Seed())
+		prefix := sha512.Sum512(append([]byte("Derive temporary signing key hash input"), seedHash[32:]...))
+		if !bytes.Equal(k.prefix[:], prefix[:32]) {
+			t.Errorf("blinded nonce prefix = %x, want %x", k.prefix, prefix[:32])
+		}
+	}
+}
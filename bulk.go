@@ -0,0 +1,174 @@
+package ed25519consensus
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+// BulkVerifier verifies more signatures than fit in memory, as in audits of
+// airdrops or chain snapshots with tens of millions of signatures. Add
+// stages entries in a temporary file, and Verify reads them back in passes
+// of a bounded number of entries, each verified as one batch.
+//
+// A BulkVerifier must not be used concurrently, and must be closed to remove
+// its file.
+type BulkVerifier struct {
+	file     *os.File
+	w        *bufio.Writer
+	passSize int
+	n        int
+	err      error
+}
+
+// maxBulkField bounds the length of a staged field, so that a corrupted file
+// cannot make Verify allocate without bound.
+const maxBulkField = 1 << 30
+
+var errBulkCorrupt = errors.New("ed25519consensus: corrupted bulk verification file")
+
+// NewBulkVerifier creates an empty BulkVerifier staging entries in a new file
+// in dir, or in the default directory for temporary files if dir is empty,
+// and verifying them in passes of passSize entries. It panics if passSize is
+// not positive.
+func NewBulkVerifier(dir string, passSize int) (*BulkVerifier, error) {
+	if passSize < 1 {
+		panic("ed25519consensus: non-positive pass size")
+	}
+	f, err := os.CreateTemp(dir, "ed25519consensus-bulk-")
+	if err != nil {
+		return nil, err
+	}
+	return &BulkVerifier{file: f, w: bufio.NewWriter(f), passSize: passSize}, nil
+}
+
+// Add stages a (public key, message, sig) triple. It retains no reference to
+// its arguments. Malformed entries are staged too, and make Verify fail.
+// Add returns the first error writing the file, which Verify also returns.
+func (b *BulkVerifier) Add(publicKey ed25519.PublicKey, message, sig []byte) error {
+	if b.err != nil {
+		return b.err
+	}
+	for _, field := range [][]byte{publicKey, sig, message} {
+		var n [binary.MaxVarintLen64]byte
+		if _, err := b.w.Write(n[:binary.PutUvarint(n[:], uint64(len(field)))]); err != nil {
+			b.err = err
+			return err
+		}
+		if _, err := b.w.Write(field); err != nil {
+			b.err = err
+			return err
+		}
+	}
+	b.n++
+	return nil
+}
+
+// Len returns the number of entries staged.
+func (b *BulkVerifier) Len() int {
+	return b.n
+}
+
+// Verify verifies every staged entry, holding at most one pass of entries in
+// memory, and reports whether they are all valid. Like BatchVerifier.Verify,
+// it returns false if no entry was staged.
+//
+// If failed is nil, Verify stops at the first pass that fails. Otherwise it
+// verifies every entry of each failed pass individually, calls failed with
+// the index of each invalid one, in order, and continues with the next pass.
+func (b *BulkVerifier) Verify(failed func(i int)) (bool, error) {
+	if b.err != nil {
+		return false, b.err
+	}
+	if err := b.w.Flush(); err != nil {
+		b.err = err
+		return false, err
+	}
+	if b.n == 0 {
+		return false, nil
+	}
+	if _, err := b.file.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	// Appending after Verify must continue at the end of the file.
+	defer b.file.Seek(0, io.SeekEnd)
+
+	r := bufio.NewReader(b.file)
+	v := NewPreallocatedBatchVerifier(b.passSize)
+	var pub, sig, msg []byte
+	var offset int64
+	valid := true
+	for start := 0; start < b.n; start += b.passSize {
+		end := start + b.passSize
+		if end > b.n {
+			end = b.n
+		}
+		passOffset := offset
+		v.Reset()
+		for i := start; i < end; i++ {
+			var err error
+			var read int64
+			if pub, sig, msg, read, err = readBulkEntry(r, pub, sig, msg); err != nil {
+				return false, err
+			}
+			offset += read
+			v.Add(pub, msg, sig)
+		}
+		if v.Verify() {
+			continue
+		}
+		valid = false
+		if failed == nil {
+			return false, nil
+		}
+
+		// Read the pass again from its start to find the invalid entries.
+		pr := bufio.NewReader(io.NewSectionReader(b.file, passOffset, offset-passOffset))
+		for i := start; i < end; i++ {
+			var err error
+			if pub, sig, msg, _, err = readBulkEntry(pr, pub, sig, msg); err != nil {
+				return false, err
+			}
+			if !Verify(pub, msg, sig) {
+				failed(i)
+			}
+		}
+	}
+	return valid, nil
+}
+
+// readBulkEntry reads a staged entry from r, reusing the storage of pub, sig
+// and msg, and returns it with the number of bytes read.
+func readBulkEntry(r *bufio.Reader, pub, sig, msg []byte) ([]byte, []byte, []byte, int64, error) {
+	var read int64
+	var fields [3][]byte
+	for j, buf := range [3][]byte{pub, sig, msg} {
+		n, err := binary.ReadUvarint(r)
+		if err != nil || n > maxBulkField {
+			return nil, nil, nil, 0, errBulkCorrupt
+		}
+		var l [binary.MaxVarintLen64]byte
+		read += int64(binary.PutUvarint(l[:], n)) + int64(n)
+		if uint64(cap(buf)) < n {
+			buf = make([]byte, n)
+		}
+		buf = buf[:n]
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, nil, nil, 0, errBulkCorrupt
+		}
+		fields[j] = buf
+	}
+	return fields[0], fields[1], fields[2], read, nil
+}
+
+// Close removes the file of the BulkVerifier.
+func (b *BulkVerifier) Close() error {
+	err := b.file.Close()
+	if rmErr := os.Remove(b.file.Name()); err == nil {
+		err = rmErr
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestBulkVerifier(t *testing.T) {
+	dir := t.TempDir()
+	b, err := NewBulkVerifier(dir, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := b.Verify(nil); ok || err != nil {
+		t.Errorf("empty: Verify = %v, %v", ok, err)
+	}
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	add := func(i int, valid bool) {
+		msg := []byte(fmt.Sprintf("snapshot entry %d", i))
+		sig := ed25519.Sign(priv, msg)
+		if !valid {
+			sig[0] ^= 1
+		}
+		if err := b.Add(pub, msg, sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 30; i++ {
+		add(i, true)
+	}
+	if b.Len() != 30 {
+		t.Errorf("Len = %d, want 30", b.Len())
+	}
+	if ok, err := b.Verify(nil); !ok || err != nil {
+		t.Fatalf("valid entries: Verify = %v, %v", ok, err)
+	}
+
+	// Entries added after Verify are appended.
+	add(30, false)
+	add(31, true)
+	if err := b.Add(pub[:5], nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	for i := 33; i < 41; i++ {
+		add(i, i != 37)
+	}
+	if ok, err := b.Verify(nil); ok || err != nil {
+		t.Errorf("invalid entries: Verify = %v, %v", ok, err)
+	}
+	var failed []int
+	ok, err := b.Verify(func(i int) { failed = append(failed, i) })
+	if ok || err != nil {
+		t.Errorf("invalid entries: Verify = %v, %v", ok, err)
+	}
+	if fmt.Sprint(failed) != "[30 32 37]" {
+		t.Errorf("failed entries %v, want [30 32 37]", failed)
+	}
+
+	name := b.file.Name()
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("file not removed: %v", err)
+	}
+}
+
+func TestBulkVerifierCorrupt(t *testing.T) {
+	b, err := NewBulkVerifier(t.TempDir(), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	b.Add(pub, []byte("msg"), ed25519.Sign(priv, []byte("msg")))
+	b.w.Flush()
+	if err := b.file.Truncate(40); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := b.Verify(nil); ok || err == nil {
+		t.Errorf("truncated file: Verify = %v, %v", ok, err)
+	}
+}
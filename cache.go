@@ -0,0 +1,144 @@
+package ed25519consensus
+
+import (
+	"container/list"
+	"crypto/ed25519"
+	"sync"
+)
+
+// VerificationCache remembers the outcome of signature verifications, so
+// that a node verifies a signature seen in gossip, in the mempool and again
+// in block execution only once. It evicts the least recently used outcomes
+// beyond its capacity, and is safe for concurrent use.
+//
+// Outcomes are keyed by the public key, the signature, and the challenge
+// digest SHA-512(dom2 || R || A || M), which binds the message. The
+// challenge digest is computed by verification anyway, so a cache lookup
+// costs no more hashing than verifying.
+type VerificationCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[cacheKey]*list.Element
+	lru      list.List // of *cacheEntry, most recently used first
+}
+
+type cacheKey struct {
+	pubkey    [ed25519.PublicKeySize]byte
+	signature [ed25519.SignatureSize]byte
+	digest    [64]byte
+}
+
+type cacheEntry struct {
+	key   cacheKey
+	valid bool
+}
+
+// NewVerificationCache creates a VerificationCache holding at most capacity
+// outcomes. It panics if capacity is not positive.
+func NewVerificationCache(capacity int) *VerificationCache {
+	if capacity < 1 {
+		panic("ed25519consensus: non-positive cache capacity")
+	}
+	return &VerificationCache{
+		capacity: capacity,
+		entries:  make(map[cacheKey]*list.Element),
+	}
+}
+
+// Verify is like the package-level Verify, but returns the cached outcome
+// if the signature was already verified, and caches the outcome otherwise.
+func (c *VerificationCache) Verify(publicKey ed25519.PublicKey, message, sig []byte) bool {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return false
+	}
+	if len(sig) != ed25519.SignatureSize || sig[63]&224 != 0 {
+		return false
+	}
+
+	var key cacheKey
+	key.set(publicKey, sig, nil, message)
+	if valid, ok := c.lookup(&key); ok {
+		return valid
+	}
+	valid := verifyDigest(publicKey, sig, &key.digest, false)
+	c.store(&key, valid)
+	return valid
+}
+
+// Len returns the number of cached outcomes.
+func (c *VerificationCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// set fills in k for a well-formed entry.
+func (k *cacheKey) set(publicKey ed25519.PublicKey, sig, dom, message []byte) {
+	copy(k.pubkey[:], publicKey)
+	copy(k.signature[:], sig)
+	e := entry{dom: dom, pubkey: k.pubkey, signature: k.signature}
+	e.computeDigest(message)
+	k.digest = e.digest
+}
+
+// lookup returns the cached outcome for k, if any.
+func (c *VerificationCache) lookup(k *cacheKey) (valid, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[*k]
+	if !ok {
+		return false, false
+	}
+	c.lru.MoveToFront(el)
+	return el.Value.(*cacheEntry).valid, true
+}
+
+// store caches the outcome for k, evicting the least recently used outcome
+// if the cache is full.
+func (c *VerificationCache) store(k *cacheKey, valid bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[*k]; ok {
+		el.Value.(*cacheEntry).valid = valid
+		c.lru.MoveToFront(el)
+		return
+	}
+	if len(c.entries) >= c.capacity {
+		oldest := c.lru.Back()
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+		c.lru.Remove(oldest)
+	}
+	c.entries[*k] = c.lru.PushFront(&cacheEntry{key: *k, valid: valid})
+}
+
+// SetCache makes the batch consult c when adding entries, and record in c
+// the entries of batches that verify. An entry that c knows to be valid is
+// not added to the batch at all, so it does not count towards the limits of
+// SetLimits or the indices passed to a failure callback, and a batch whose
+// entries were all known to be valid verifies without any computation.
+//
+// While a cache is set, the challenge digest of every entry is computed by
+// Add, even if hashing is deferred. Entries added with AddWithChallenge or
+// as a Hashed Entry bypass the cache, since their digest is not computed by
+// the batch. So do all entries while Policy.Cofactorless is set, since the
+// cache records outcomes under the ZIP215 rules. A nil c disables it.
+func (v *BatchVerifier) SetCache(c *VerificationCache) {
+	v.cache = c
+}
+
+// cacheStoreValid records every entry of the batch as valid in the cache.
+func (v *BatchVerifier) cacheStoreValid() {
+	for i := range v.entries {
+		v.cacheStoreEntry(&v.entries[i])
+	}
+}
+
+// cacheStoreEntry records the valid entry e in the cache, unless its digest
+// was provided by the caller or it was checked under the cofactorless rules.
+func (v *BatchVerifier) cacheStoreEntry(e *entry) {
+	if !e.bound || e.cofactorless {
+		return
+	}
+	k := cacheKey{pubkey: e.pubkey, signature: e.signature, digest: e.digest}
+	v.cache.store(&k, true)
+}
@@ -0,0 +1,132 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"testing"
+
+	"github.com/hdevalence/ed25519consensus/testvectors"
+)
+
+func TestVerificationCache(t *testing.T) {
+	c := NewVerificationCache(2)
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("cached")
+	sig := ed25519.Sign(priv, msg)
+
+	for i := 0; i < 2; i++ {
+		if !c.Verify(pub, msg, sig) {
+			t.Error("valid signature rejected")
+		}
+		if c.Verify(pub, []byte("other"), sig) {
+			t.Error("invalid signature accepted")
+		}
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", c.Len())
+	}
+
+	// Caching a third outcome evicts the least recently used one.
+	c.Verify(pub, msg, sig)
+	c.Verify(pub, []byte("third"), sig)
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", c.Len())
+	}
+	k := cacheKey{}
+	k.set(pub, sig, nil, []byte("other"))
+	if _, ok := c.lookup(&k); ok {
+		t.Error("least recently used outcome was not evicted")
+	}
+}
+
+func TestBatchCache(t *testing.T) {
+	c := NewVerificationCache(100)
+	type triple struct{ pub, msg, sig []byte }
+	var triples []triple
+	for i := 0; i < 10; i++ {
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		msg := []byte(fmt.Sprintf("message %d", i))
+		triples = append(triples, triple{pub, msg, ed25519.Sign(priv, msg)})
+	}
+
+	v := NewBatchVerifier()
+	v.SetCache(c)
+	for _, tr := range triples[:6] {
+		v.Add(tr.pub, tr.msg, tr.sig)
+	}
+	if !v.Verify() {
+		t.Fatal("valid batch rejected")
+	}
+	if c.Len() != 6 {
+		t.Errorf("cache holds %d outcomes, want 6", c.Len())
+	}
+
+	// Known entries are skipped; the others are verified and cached.
+	v = NewBatchVerifier()
+	v.SetCache(c)
+	v.SetDeferredHashing(2)
+	for _, tr := range triples {
+		v.Add(tr.pub, tr.msg, tr.sig)
+	}
+	if len(v.entries) != 4 {
+		t.Errorf("batch has %d entries, want 4", len(v.entries))
+	}
+	if !v.Verify() {
+		t.Fatal("valid batch rejected")
+	}
+	if c.Len() != 10 {
+		t.Errorf("cache holds %d outcomes, want 10", c.Len())
+	}
+
+	// A batch of only known entries verifies.
+	v = NewBatchVerifier()
+	v.SetCache(c)
+	v.AddTagged("tx", triples[0].pub, triples[0].msg, triples[0].sig)
+	if ok, failed := v.VerifyTagged(); !ok || failed != nil {
+		t.Errorf("VerifyTagged() = %v, %v", ok, failed)
+	}
+
+	// An invalid entry still fails, and is not cached.
+	v = NewBatchVerifier()
+	v.SetCache(c)
+	v.Add(triples[0].pub, triples[0].msg, triples[0].sig)
+	v.Add(triples[1].pub, triples[0].msg, triples[1].sig)
+	if v.Verify() {
+		t.Error("batch with an invalid entry accepted")
+	}
+	if c.Len() != 10 {
+		t.Errorf("cache holds %d outcomes, want 10", c.Len())
+	}
+
+	// Cached outcomes are bound to the message.
+	v = NewBatchVerifier()
+	v.SetCache(c)
+	v.Add(triples[0].pub, []byte("other"), triples[0].sig)
+	if v.Verify() {
+		t.Error("known signature accepted for another message")
+	}
+}
+
+func TestBatchCacheCofactorless(t *testing.T) {
+	c := NewVerificationCache(100)
+	rejected := 0
+	for _, tv := range testvectors.MixedOrder() {
+		if !c.Verify(tv.PublicKey, tv.Message, tv.Signature) {
+			t.Fatalf("%s: rejected by cache.Verify", tv.Comment)
+		}
+		v := NewBatchVerifier()
+		v.SetPolicy(Policy{Cofactorless: true})
+		v.SetCache(c)
+		v.Add(tv.PublicKey, tv.Message, tv.Signature)
+		want := VerifyWithOptions(tv.PublicKey, tv.Message, tv.Signature, &Options{Policy: Policy{Cofactorless: true}}) == nil
+		if got := v.Verify(); got != want {
+			t.Errorf("%s: cofactorless batch with cache = %v, want %v", tv.Comment, got, want)
+		}
+		if !want {
+			rejected++
+		}
+	}
+	if rejected == 0 {
+		t.Error("no mixed-order vector is rejected by the cofactorless rules")
+	}
+}
@@ -0,0 +1,87 @@
+package ed25519consensus
+
+import (
+	"bytes"
+	"crypto/ed25519"
+
+	"filippo.io/edwards25519"
+)
+
+// IsCanonicalPointEncoding reports whether enc is the canonical encoding of a
+// curve point, that is, whether it decodes to a point and the encoded
+// y-coordinate is reduced and the sign bit is clear when x is zero.
+//
+// ZIP215 accepts non-canonical encodings of A and R, so this is not a
+// validity check; it is meant for auditing keys and signatures.
+func IsCanonicalPointEncoding(enc [32]byte) bool {
+	p, err := new(edwards25519.Point).SetBytes(enc[:])
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(p.Bytes(), enc[:])
+}
+
+// IsCanonicalScalar reports whether enc is the canonical encoding of a
+// scalar, that is, a little-endian integer less than the group order. Verify
+// rejects signatures whose s is not canonical.
+func IsCanonicalScalar(enc [32]byte) bool {
+	_, err := new(edwards25519.Scalar).SetCanonicalBytes(enc[:])
+	return err == nil
+}
+
+// HasSmallOrderComponent reports whether the point encoded by pub has a
+// non-zero component in the torsion subgroup of order 8, which includes every
+// small-order point other than the identity. It returns false if pub is not a
+// valid point encoding.
+func HasSmallOrderComponent(pub ed25519.PublicKey) bool {
+	if len(pub) != ed25519.PublicKeySize {
+		return false
+	}
+	p, err := new(edwards25519.Point).SetBytes(pub)
+	if err != nil {
+		return false
+	}
+	return !isTorsionFree(p)
+}
+
+// isTorsionFree reports whether p is in the prime-order subgroup, by checking
+// that [l]p is the identity.
+func isTorsionFree(p *edwards25519.Point) bool {
+	// The scalar l - 1 is the negation of one.
+	var one [32]byte
+	one[0] = 1
+	lMinusOne, _ := new(edwards25519.Scalar).SetCanonicalBytes(one[:])
+	lMinusOne.Negate(lMinusOne)
+
+	q := new(edwards25519.Point).ScalarMult(lMinusOne, p)
+	q.Add(q, p)
+	return q.Equal(edwards25519.NewIdentityPoint()) == 1
+}
+
+// CanonicalizeSignature returns the canonical encoding of a signature that is
+// valid under Verify: the same signature with R re-encoded canonically. Since
+// Verify requires s to be reduced, only R can be malleated.
+//
+// Changing the encoding of R changes the challenge, so the re-encoded
+// signature is not necessarily valid. CanonicalizeSignature returns false if
+// sig is invalid, or if its canonical re-encoding is.
+func CanonicalizeSignature(pub ed25519.PublicKey, msg, sig []byte) ([]byte, bool) {
+	if !Verify(pub, msg, sig) {
+		return nil, false
+	}
+	R, err := new(edwards25519.Point).SetBytes(sig[:32])
+	if err != nil {
+		return nil, false
+	}
+
+	canonical := make([]byte, 0, ed25519.SignatureSize)
+	canonical = append(canonical, R.Bytes()...)
+	canonical = append(canonical, sig[32:]...)
+	if bytes.Equal(canonical, sig) {
+		return canonical, true
+	}
+	if !Verify(pub, msg, canonical) {
+		return nil, false
+	}
+	return canonical, true
+}
@@ -0,0 +1,114 @@
+package ed25519consensus
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"filippo.io/edwards25519"
+)
+
+func decodeHex32(t *testing.T, s string) [32]byte {
+	var b [32]byte
+	if n, err := hex.Decode(b[:], []byte(s)); err != nil || n != 32 {
+		t.Fatalf("invalid test encoding %q", s)
+	}
+	return b
+}
+
+func TestIsCanonicalPointEncoding(t *testing.T) {
+	for _, c := range []struct {
+		enc       string
+		canonical bool
+	}{
+		// identity
+		{"0100000000000000000000000000000000000000000000000000000000000000", true},
+		// identity with the sign bit set
+		{"0100000000000000000000000000000000000000000000000000000000000080", false},
+		// y = p - 1, the point of order 2
+		{"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", true},
+		// y = p, a non-canonical encoding of a point of order 4
+		{"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", false},
+		// y = 2, not on the curve
+		{"0200000000000000000000000000000000000000000000000000000000000000", false},
+		// the basepoint
+		{"5866666666666666666666666666666666666666666666666666666666666666", true},
+	} {
+		if got := IsCanonicalPointEncoding(decodeHex32(t, c.enc)); got != c.canonical {
+			t.Errorf("IsCanonicalPointEncoding(%s) = %v, want %v", c.enc, got, c.canonical)
+		}
+	}
+}
+
+func TestIsCanonicalScalar(t *testing.T) {
+	for _, c := range []struct {
+		enc       string
+		canonical bool
+	}{
+		{"0000000000000000000000000000000000000000000000000000000000000000", true},
+		// l - 1
+		{"ecd3f55c1a631258d69cf7a2def9de1400000000000000000000000000000010", true},
+		// l
+		{"edd3f55c1a631258d69cf7a2def9de1400000000000000000000000000000010", false},
+		{"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff0f", true},
+		{"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff1f", false},
+	} {
+		if got := IsCanonicalScalar(decodeHex32(t, c.enc)); got != c.canonical {
+			t.Errorf("IsCanonicalScalar(%s) = %v, want %v", c.enc, got, c.canonical)
+		}
+	}
+}
+
+func TestHasSmallOrderComponent(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(nil)
+	if HasSmallOrderComponent(pub) {
+		t.Error("generated key has a small-order component")
+	}
+
+	identity := decodeHex32(t, "0100000000000000000000000000000000000000000000000000000000000000")
+	if HasSmallOrderComponent(identity[:]) {
+		t.Error("identity has a small-order component")
+	}
+
+	order2 := decodeHex32(t, "ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	if !HasSmallOrderComponent(order2[:]) {
+		t.Error("point of order 2 has no small-order component")
+	}
+
+	A, _ := new(edwards25519.Point).SetBytes(pub)
+	T, _ := new(edwards25519.Point).SetBytes(order2[:])
+	if !HasSmallOrderComponent(new(edwards25519.Point).Add(A, T).Bytes()) {
+		t.Error("mixed-order key has no small-order component")
+	}
+
+	if HasSmallOrderComponent(pub[:31]) {
+		t.Error("short key has a small-order component")
+	}
+}
+
+func TestCanonicalizeSignature(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("message")
+	sig := ed25519.Sign(priv, msg)
+	if got, ok := CanonicalizeSignature(pub, msg, sig); !ok || !bytes.Equal(got, sig) {
+		t.Error("canonical signature was not returned unchanged")
+	}
+	if _, ok := CanonicalizeSignature(pub, []byte("other"), sig); ok {
+		t.Error("invalid signature was canonicalized")
+	}
+
+	// With the identity as the key and s = 0, any small-order R verifies,
+	// including a non-canonical encoding of the identity.
+	identity := decodeHex32(t, "0100000000000000000000000000000000000000000000000000000000000000")
+	sig = make([]byte, 64)
+	copy(sig, identity[:])
+	sig[31] |= 0x80
+	got, ok := CanonicalizeSignature(identity[:], msg, sig)
+	if !ok {
+		t.Fatal("signature with non-canonical R was not canonicalized")
+	}
+	if !bytes.Equal(got[:32], identity[:]) || !bytes.Equal(got[32:], sig[32:]) {
+		t.Errorf("unexpected canonical signature %x", got)
+	}
+}
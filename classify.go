@@ -0,0 +1,84 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+
+	"filippo.io/edwards25519"
+)
+
+// Classification describes how a signature fares under the different Ed25519
+// validation rules found in the wild, and which of its encodings are unusual.
+type Classification struct {
+	// Malformed is set if the inputs have the wrong length, or A or R is
+	// not a valid point encoding. No rule accepts malformed signatures, and
+	// the other fields are then false.
+	Malformed bool
+
+	// ZIP215 is whether Verify accepts the signature: any point encoding,
+	// canonical s, and the cofactored equation [8][s]B = [8]R + [8][k]A.
+	ZIP215 bool
+	// RFC8032 is whether the signature is valid under the strict reading of
+	// RFC 8032, Section 5.1.7: canonical encodings of A and R, canonical s,
+	// and the cofactored equation.
+	RFC8032 bool
+	// Cofactorless is whether the signature satisfies the cofactorless
+	// equation [s]B = R + [k]A with canonical s, as checked by most
+	// implementations including crypto/ed25519, regardless of encodings.
+	Cofactorless bool
+
+	// NonCanonicalA, NonCanonicalR and NonCanonicalS are set if the
+	// corresponding encoding is valid but not canonical; for s, this means
+	// it is not reduced modulo the group order.
+	NonCanonicalA, NonCanonicalR, NonCanonicalS bool
+	// SmallOrderA and SmallOrderR are set if the corresponding point has
+	// order at most 8, including the identity.
+	SmallOrderA, SmallOrderR bool
+}
+
+// Classify evaluates sig against several validation rules at once, for
+// auditing divergence between implementations. Only the ZIP215 field
+// reflects the rules of this package; use Verify for validation.
+func Classify(publicKey ed25519.PublicKey, message, sig []byte) Classification {
+	var c Classification
+	if len(publicKey) != ed25519.PublicKeySize || len(sig) != ed25519.SignatureSize {
+		c.Malformed = true
+		return c
+	}
+	var encA, encR, encS [32]byte
+	copy(encA[:], publicKey)
+	copy(encR[:], sig[:32])
+	copy(encS[:], sig[32:])
+
+	A, errA := new(edwards25519.Point).SetBytes(encA[:])
+	R, errR := new(edwards25519.Point).SetBytes(encR[:])
+	if errA != nil || errR != nil {
+		c.Malformed = true
+		return c
+	}
+	c.NonCanonicalA = !IsCanonicalPointEncoding(encA)
+	c.NonCanonicalR = !IsCanonicalPointEncoding(encR)
+	c.NonCanonicalS = !IsCanonicalScalar(encS)
+	c.SmallOrderA = isSmallOrder(A)
+	c.SmallOrderR = isSmallOrder(R)
+	if c.NonCanonicalS {
+		return c
+	}
+
+	s, _ := new(edwards25519.Scalar).SetCanonicalBytes(encS[:])
+	k := ComputeChallenge(encR[:], publicKey, message)
+	minusA := new(edwards25519.Point).Negate(A)
+	// diff = [s]B - [k]A - R
+	diff := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(k, minusA, s)
+	diff.Subtract(diff, R)
+
+	c.Cofactorless = diff.Equal(edwards25519.NewIdentityPoint()) == 1
+	c.ZIP215 = isSmallOrder(diff)
+	c.RFC8032 = c.ZIP215 && !c.NonCanonicalA && !c.NonCanonicalR
+	return c
+}
+
+// isSmallOrder reports whether [8]p is the identity.
+func isSmallOrder(p *edwards25519.Point) bool {
+	q := new(edwards25519.Point).MultByCofactor(p)
+	return q.Equal(edwards25519.NewIdentityPoint()) == 1
+}
@@ -0,0 +1,66 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/hdevalence/ed25519consensus/testvectors"
+)
+
+func TestClassify(t *testing.T) {
+	for _, v := range testvectors.All() {
+		c := Classify(v.PublicKey, v.Message, v.Signature)
+		if c.ZIP215 != Verify(v.PublicKey, v.Message, v.Signature) {
+			t.Errorf("%s: ZIP215 = %v, disagrees with Verify", v.Comment, c.ZIP215)
+		}
+		if c.RFC8032 && (!c.ZIP215 || c.NonCanonicalA || c.NonCanonicalR) {
+			t.Errorf("%s: inconsistent classification %+v", v.Comment, c)
+		}
+		if c.Cofactorless && !c.ZIP215 {
+			t.Errorf("%s: accepted without the cofactor but not with it: %+v", v.Comment, c)
+		}
+	}
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("classify me")
+	want := Classification{ZIP215: true, RFC8032: true, Cofactorless: true}
+	if c := Classify(pub, msg, ed25519.Sign(priv, msg)); c != want {
+		t.Errorf("ordinary signature: got %+v, want %+v", c, want)
+	}
+
+	// Signatures by keys with a torsion component only satisfy the
+	// cofactored equation, unless the challenge happens to cancel the
+	// component, which is not the case for this vector.
+	mixed := testvectors.MixedOrder()[2]
+	c := Classify(mixed.PublicKey, mixed.Message, mixed.Signature)
+	if !c.ZIP215 || !c.RFC8032 || c.Cofactorless || c.SmallOrderA {
+		t.Errorf("mixed-order signature: got %+v", c)
+	}
+
+	// The ZIP215 vectors use small-order A and R, some non-canonical.
+	var sawNonCanonical bool
+	for _, v := range testvectors.ZIP215() {
+		c := Classify(v.PublicKey, v.Message, v.Signature)
+		if !c.SmallOrderA || !c.SmallOrderR {
+			t.Errorf("%s: expected small-order A and R: %+v", v.Comment, c)
+		}
+		if c.NonCanonicalA || c.NonCanonicalR {
+			sawNonCanonical = true
+			if c.RFC8032 {
+				t.Errorf("%s: RFC 8032 accepted a non-canonical encoding", v.Comment)
+			}
+		}
+	}
+	if !sawNonCanonical {
+		t.Error("no non-canonical encodings found in the ZIP215 vectors")
+	}
+
+	if c := Classify(pub[:31], msg, make([]byte, 64)); !c.Malformed {
+		t.Error("short key not reported as malformed")
+	}
+	sig := ed25519.Sign(priv, msg)
+	sig[63] |= 0x10
+	if c := Classify(pub, msg, sig); !c.NonCanonicalS || c.ZIP215 || c.Cofactorless {
+		t.Errorf("unreduced s: got %+v", c)
+	}
+}
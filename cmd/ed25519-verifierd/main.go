@@ -0,0 +1,198 @@
+// Command ed25519-verifierd serves batch verification of Ed25519 signatures
+// with the ZIP215 validation criteria of
+// github.com/hdevalence/ed25519consensus, so that consensus components not
+// written in Go can share one implementation of the rules.
+//
+// Usage:
+//
+//	ed25519-verifierd [-tcp ADDR | -unix PATH] [-max-frame BYTES]
+//
+// Clients send requests and read responses over a stream connection, one at
+// a time. All integers are unsigned and big-endian. A request is
+//
+//	length  uint32  number of bytes that follow
+//	count   uint32  number of entries
+//	count entries of
+//	  pubkey     [32]byte
+//	  signature  [64]byte
+//	  msglen     uint32
+//	  message    [msglen]byte
+//
+// and its response is
+//
+//	length  uint32  number of bytes that follow, equal to count
+//	count codes of one byte, one per entry, in order:
+//	  0  valid
+//	  1  invalid signature
+//	  2  malformed signature, such as a non-canonical s
+//	  3  public key that is not a valid point encoding
+//
+// The entries of a request are verified as one batch, and individually
+// only if the batch fails. A request that is not well-formed, or longer
+// than -max-frame, closes the connection.
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+
+	"github.com/hdevalence/ed25519consensus"
+)
+
+// Result codes.
+const (
+	codeValid = iota
+	codeInvalid
+	codeMalformed
+	codeInvalidKey
+)
+
+// entrySize is the size of an entry with an empty message.
+const entrySize = 32 + 64 + 4
+
+var errMalformedRequest = errors.New("malformed request")
+
+func main() {
+	tcp := flag.String("tcp", "", "listen on TCP `address`")
+	unix := flag.String("unix", "", "listen on the Unix socket at `path`")
+	maxFrame := flag.Int("max-frame", 64<<20, "maximum request size in `bytes`")
+	flag.Parse()
+
+	var l net.Listener
+	var err error
+	switch {
+	case *tcp != "" && *unix != "":
+		err = errors.New("at most one of -tcp and -unix may be given")
+	case *unix != "":
+		l, err = net.Listen("unix", *unix)
+	default:
+		if *tcp == "" {
+			*tcp = "127.0.0.1:7215"
+		}
+		l, err = net.Listen("tcp", *tcp)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ed25519-verifierd: %v\n", err)
+		os.Exit(2)
+	}
+	log.Printf("listening on %v", l.Addr())
+	log.Fatal(serve(l, *maxFrame))
+}
+
+// serve accepts connections on l until it fails, serving each in its own
+// goroutine.
+func serve(l net.Listener, maxFrame int) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			if err := handle(conn, maxFrame); err != nil && err != io.EOF {
+				log.Printf("%v: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// handle serves the requests of conn until it is closed or sends a
+// malformed request.
+func handle(conn io.ReadWriter, maxFrame int) error {
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	v := ed25519consensus.NewBatchVerifier()
+	var frame []byte
+	for {
+		var length [4]byte
+		if _, err := io.ReadFull(r, length[:]); err != nil {
+			return err
+		}
+		n := binary.BigEndian.Uint32(length[:])
+		if uint64(n) > uint64(maxFrame) {
+			return fmt.Errorf("request of %d bytes exceeds the maximum of %d", n, maxFrame)
+		}
+		if cap(frame) < int(n) {
+			frame = make([]byte, n)
+		}
+		frame = frame[:n]
+		if _, err := io.ReadFull(r, frame); err != nil {
+			return err
+		}
+
+		codes, err := verifyRequest(&v, frame)
+		if err != nil {
+			return err
+		}
+		binary.BigEndian.PutUint32(length[:], uint32(len(codes)))
+		w.Write(length[:])
+		w.Write(codes)
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+// verifyRequest decodes the entries of a request, verifies them with v, and
+// returns their result codes.
+func verifyRequest(v *ed25519consensus.BatchVerifier, frame []byte) ([]byte, error) {
+	if len(frame) < 4 {
+		return nil, errMalformedRequest
+	}
+	count := binary.BigEndian.Uint32(frame)
+	frame = frame[4:]
+	if uint64(count)*entrySize > uint64(len(frame)) {
+		return nil, errMalformedRequest
+	}
+
+	type entry struct{ pub, sig, msg []byte }
+	entries := make([]entry, count)
+	for i := range entries {
+		if len(frame) < entrySize {
+			return nil, errMalformedRequest
+		}
+		e := &entries[i]
+		e.pub, e.sig = frame[:32], frame[32:96]
+		msglen := binary.BigEndian.Uint32(frame[96:100])
+		frame = frame[entrySize:]
+		if uint64(msglen) > uint64(len(frame)) {
+			return nil, errMalformedRequest
+		}
+		e.msg, frame = frame[:msglen], frame[msglen:]
+	}
+	if len(frame) != 0 {
+		return nil, errMalformedRequest
+	}
+
+	codes := make([]byte, count)
+	if count == 0 {
+		return codes, nil
+	}
+	v.Reset()
+	for _, e := range entries {
+		v.Add(e.pub, e.msg, e.sig)
+	}
+	if v.Verify() {
+		return codes, nil
+	}
+	for i, e := range entries {
+		switch ed25519consensus.VerifyWithOptions(e.pub, e.msg, e.sig, &ed25519consensus.Options{}) {
+		case nil:
+			codes[i] = codeValid
+		case ed25519consensus.ErrInvalidKeyEncoding:
+			codes[i] = codeInvalidKey
+		case ed25519consensus.ErrMalformedSignature:
+			codes[i] = codeMalformed
+		default:
+			codes[i] = codeInvalid
+		}
+	}
+	return codes, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+)
+
+type testEntry struct {
+	pub, sig, msg []byte
+}
+
+func encodeRequest(entries []testEntry) []byte {
+	body := binary.BigEndian.AppendUint32(nil, uint32(len(entries)))
+	for _, e := range entries {
+		body = append(body, e.pub...)
+		body = append(body, e.sig...)
+		body = binary.BigEndian.AppendUint32(body, uint32(len(e.msg)))
+		body = append(body, e.msg...)
+	}
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(body))), body...)
+}
+
+func roundTrip(t *testing.T, conn net.Conn, request []byte) []byte {
+	t.Helper()
+	if _, err := conn.Write(request); err != nil {
+		t.Fatal(err)
+	}
+	var length [4]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		t.Fatal(err)
+	}
+	codes := make([]byte, binary.BigEndian.Uint32(length[:]))
+	if _, err := io.ReadFull(conn, codes); err != nil {
+		t.Fatal(err)
+	}
+	return codes
+}
+
+func testEntries() []testEntry {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	var entries []testEntry
+	for i := 0; i < 4; i++ {
+		msg := []byte{byte(i)}
+		entries = append(entries, testEntry{pub, ed25519.Sign(priv, msg), msg})
+	}
+	return entries
+}
+
+func TestHandle(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	done := make(chan error, 1)
+	go func() { done <- handle(server, 1<<20) }()
+
+	entries := testEntries()
+	if codes := roundTrip(t, client, encodeRequest(entries)); !bytes.Equal(codes, []byte{0, 0, 0, 0}) {
+		t.Errorf("valid entries: codes %v", codes)
+	}
+
+	entries[1].msg = []byte("tampered")
+	entries[2].sig = append([]byte(nil), entries[2].sig...)
+	entries[2].sig[63] |= 0xe0
+	bad := make([]byte, 32)
+	bad[0] = 2 // y = 2 is not on the curve
+	entries[3].pub = bad
+	if codes := roundTrip(t, client, encodeRequest(entries)); !bytes.Equal(codes, []byte{0, 1, 2, 3}) {
+		t.Errorf("invalid entries: codes %v", codes)
+	}
+	if codes := roundTrip(t, client, encodeRequest(nil)); len(codes) != 0 {
+		t.Errorf("empty request: codes %v", codes)
+	}
+
+	// A truncated entry closes the connection.
+	request := encodeRequest(entries[:1])
+	binary.BigEndian.PutUint32(request, uint32(len(request)-4-1))
+	client.Write(request[:len(request)-1])
+	if err := <-done; err != errMalformedRequest {
+		t.Errorf("malformed request: %v", err)
+	}
+}
+
+func TestHandleMaxFrame(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	done := make(chan error, 1)
+	go func() { done <- handle(server, 100) }()
+	client.Write(encodeRequest(testEntries()))
+	if err := <-done; err == nil {
+		t.Error("oversized request accepted")
+	}
+}
+
+func TestServeTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer l.Close()
+	go serve(l, 1<<20)
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if codes := roundTrip(t, conn, encodeRequest(testEntries())); !bytes.Equal(codes, []byte{0, 0, 0, 0}) {
+		t.Errorf("codes %v", codes)
+	}
+}
@@ -0,0 +1,470 @@
+// Command ed25519consensus verifies and creates Ed25519 signatures with the
+// ZIP215 validation criteria of github.com/hdevalence/ed25519consensus.
+//
+// Usage:
+//
+//	ed25519consensus verify -pub KEY -sig SIG [-msg TEXT | -msg-hex HEX | -msg-file PATH]
+//	ed25519consensus classify -pub KEY -sig SIG [-msg TEXT | -msg-hex HEX | -msg-file PATH]
+//	ed25519consensus batch [-format jsonl|csv] [-workers N] [-chunk N] [-stats] [FILE]
+//	ed25519consensus sign -seed SEED [-msg TEXT | -msg-hex HEX | -msg-file PATH]
+//
+// The classify command prints the result of ed25519consensus.Classify: whether
+// the signature is valid under ZIP215, strict RFC 8032 and cofactorless rules,
+// and which of its encodings are non-canonical or of small order.
+//
+// Keys, seeds and signatures are given in hex or base64. The batch command
+// reads JSON lines of the form
+//
+//	{"pubkey": KEY, "message": BASE64, "signature": SIG}
+//
+// or, with -format csv, rows of the form
+//
+//	KEY,BASE64,SIG
+//
+// from FILE or standard input. Instead of the message, a record may give the
+// path of a file holding it, as "message_file" in JSON or as a fourth CSV
+// field. The records are read as a stream and batch-verified in chunks of
+// -chunk entries by -workers goroutines, and the lines of any invalid
+// entries are reported with the reason they are invalid. With -stats, the
+// throughput is printed to standard error.
+//
+// The exit status is 0 if every signature is valid, 1 if any is invalid, and
+// 2 on usage or input errors.
+package main
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hdevalence/ed25519consensus"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+const usage = `usage:
+	ed25519consensus verify -pub KEY -sig SIG [-msg TEXT | -msg-hex HEX | -msg-file PATH]
+	ed25519consensus classify -pub KEY -sig SIG [-msg TEXT | -msg-hex HEX | -msg-file PATH]
+	ed25519consensus batch [-format jsonl|csv] [-workers N] [-chunk N] [-stats] [FILE]
+	ed25519consensus sign -seed SEED [-msg TEXT | -msg-hex HEX | -msg-file PATH]
+`
+
+// errInvalid reports that some signature did not verify.
+var errInvalid = errors.New("invalid signature")
+
+// run executes the command line args and returns the exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "verify", "classify":
+		err = runVerify(args[0], args[1:], stdout, stderr)
+	case "batch":
+		err = runBatch(args[1:], stdin, stdout, stderr)
+	case "sign":
+		err = runSign(args[1:], stdout, stderr)
+	default:
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errInvalid):
+		return 1
+	case errors.Is(err, flag.ErrHelp):
+		return 2
+	default:
+		fmt.Fprintf(stderr, "ed25519consensus: %v\n", err)
+		return 2
+	}
+}
+
+// messageFlags registers the flags selecting the message.
+type messageFlags struct {
+	text, hex, file string
+}
+
+func (m *messageFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&m.text, "msg", "", "message `text`")
+	fs.StringVar(&m.hex, "msg-hex", "", "hex-encoded message")
+	fs.StringVar(&m.file, "msg-file", "", "read the message from `path`")
+}
+
+func (m *messageFlags) message() ([]byte, error) {
+	switch {
+	case m.file != "" && (m.text != "" || m.hex != ""), m.text != "" && m.hex != "":
+		return nil, errors.New("at most one of -msg, -msg-hex and -msg-file may be given")
+	case m.file != "":
+		return os.ReadFile(m.file)
+	case m.hex != "":
+		return hex.DecodeString(m.hex)
+	default:
+		return []byte(m.text), nil
+	}
+}
+
+func runVerify(cmd string, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	pubFlag := fs.String("pub", "", "public `key` in hex or base64")
+	sigFlag := fs.String("sig", "", "`signature` in hex or base64")
+	var msgFlags messageFlags
+	msgFlags.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	pub, err := decodeFixed(*pubFlag, ed25519.PublicKeySize, "public key")
+	if err != nil {
+		return err
+	}
+	sig, err := decodeFixed(*sigFlag, ed25519.SignatureSize, "signature")
+	if err != nil {
+		return err
+	}
+	msg, err := msgFlags.message()
+	if err != nil {
+		return err
+	}
+
+	if cmd == "classify" {
+		c := ed25519consensus.Classify(pub, msg, sig)
+		fmt.Fprintf(stdout, "malformed: %v\n", c.Malformed)
+		fmt.Fprintf(stdout, "zip215: %v\n", c.ZIP215)
+		fmt.Fprintf(stdout, "rfc8032: %v\n", c.RFC8032)
+		fmt.Fprintf(stdout, "cofactorless: %v\n", c.Cofactorless)
+		fmt.Fprintf(stdout, "non-canonical A: %v\n", c.NonCanonicalA)
+		fmt.Fprintf(stdout, "non-canonical R: %v\n", c.NonCanonicalR)
+		fmt.Fprintf(stdout, "non-canonical s: %v\n", c.NonCanonicalS)
+		fmt.Fprintf(stdout, "small-order A: %v\n", c.SmallOrderA)
+		fmt.Fprintf(stdout, "small-order R: %v\n", c.SmallOrderR)
+		if !c.ZIP215 {
+			return errInvalid
+		}
+		return nil
+	}
+
+	if !ed25519consensus.Verify(pub, msg, sig) {
+		fmt.Fprintln(stdout, "invalid")
+		return errInvalid
+	}
+	fmt.Fprintln(stdout, "valid")
+	return nil
+}
+
+// record is a line of JSON batch input.
+type record struct {
+	PublicKey   string `json:"pubkey"`
+	Message     []byte `json:"message"`
+	MessageFile string `json:"message_file"`
+	Signature   string `json:"signature"`
+}
+
+// batchEntry is a decoded record with its line number.
+type batchEntry struct {
+	line          int
+	pub, msg, sig []byte
+}
+
+// failure is an invalid entry and the reason it is invalid.
+type failure struct {
+	line   int
+	reason error
+}
+
+func runBatch(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "jsonl", "input `format`, jsonl or csv")
+	workers := fs.Int("workers", runtime.GOMAXPROCS(0), "number of batches verified in parallel")
+	chunk := fs.Int("chunk", 4096, "number of entries per batch")
+	stats := fs.Bool("stats", false, "print statistics to standard error")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *workers < 1 || *chunk < 1 {
+		return errors.New("-workers and -chunk must be positive")
+	}
+
+	in := stdin
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	default:
+		return errors.New("batch takes at most one file")
+	}
+
+	var next func() (batchEntry, error)
+	switch *format {
+	case "jsonl":
+		next = jsonReader(in)
+	case "csv":
+		next = csvReader(in)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	start := time.Now()
+	chunks := make(chan []batchEntry, *workers)
+	results := make(chan []failure, *workers)
+	var wg sync.WaitGroup
+	for w := 0; w < *workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entries := range chunks {
+				results <- verifyChunk(entries)
+			}
+		}()
+	}
+	var failures []failure
+	collected := make(chan struct{})
+	go func() {
+		for f := range results {
+			failures = append(failures, f...)
+		}
+		close(collected)
+	}()
+
+	total, batches := 0, 0
+	var readErr error
+	entries := make([]batchEntry, 0, *chunk)
+	for {
+		e, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+		entries = append(entries, e)
+		total++
+		if len(entries) == *chunk {
+			chunks <- entries
+			batches++
+			entries = make([]batchEntry, 0, *chunk)
+		}
+	}
+	if len(entries) > 0 {
+		chunks <- entries
+		batches++
+	}
+	close(chunks)
+	wg.Wait()
+	close(results)
+	<-collected
+	if readErr != nil {
+		return readErr
+	}
+	if total == 0 {
+		return errors.New("no entries")
+	}
+
+	sort.Slice(failures, func(i, j int) bool { return failures[i].line < failures[j].line })
+	for _, f := range failures {
+		fmt.Fprintf(stdout, "line %d: invalid: %v\n", f.line, f.reason)
+	}
+	if *stats {
+		elapsed := time.Since(start)
+		fmt.Fprintf(stderr, "%d entries in %d batches, %d workers, %v, %.0f signatures/s\n",
+			total, batches, *workers, elapsed.Round(time.Millisecond), float64(total)/elapsed.Seconds())
+	}
+	if len(failures) == 0 {
+		fmt.Fprintf(stdout, "%d valid\n", total)
+		return nil
+	}
+	fmt.Fprintf(stdout, "%d valid, %d invalid\n", total-len(failures), len(failures))
+	return errInvalid
+}
+
+// verifyChunk batch-verifies entries, and returns the invalid ones.
+func verifyChunk(entries []batchEntry) []failure {
+	v := ed25519consensus.NewPreallocatedBatchVerifier(len(entries))
+	for _, e := range entries {
+		v.Add(e.pub, e.msg, e.sig)
+	}
+	if v.Verify() {
+		return nil
+	}
+
+	// The batch failed, so find the invalid entries individually.
+	var failures []failure
+	for _, e := range entries {
+		if err := ed25519consensus.VerifyWithOptions(e.pub, e.msg, e.sig, &ed25519consensus.Options{}); err != nil {
+			failures = append(failures, failure{e.line, err})
+		}
+	}
+	return failures
+}
+
+// jsonReader returns a function reading the records of JSON lines from in,
+// skipping empty lines, and returning io.EOF at the end.
+func jsonReader(in io.Reader) func() (batchEntry, error) {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, 64<<20)
+	line := 0
+	return func() (batchEntry, error) {
+		for scanner.Scan() {
+			line++
+			if len(scanner.Bytes()) == 0 {
+				continue
+			}
+			var r record
+			if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+				return batchEntry{}, fmt.Errorf("line %d: %v", line, err)
+			}
+			e, err := decodeEntry(r.PublicKey, r.Message, r.MessageFile, r.Signature)
+			if err != nil {
+				return batchEntry{}, fmt.Errorf("line %d: %v", line, err)
+			}
+			e.line = line
+			return e, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return batchEntry{}, err
+		}
+		return batchEntry{}, io.EOF
+	}
+}
+
+// csvReader returns a function reading records of CSV from in, and
+// returning io.EOF at the end. Each row has the fields pubkey, message in
+// base64, signature, and optionally message_file, and a first row starting
+// with "pubkey" is a header.
+func csvReader(in io.Reader) func() (batchEntry, error) {
+	r := csv.NewReader(in)
+	r.FieldsPerRecord = -1
+	return func() (batchEntry, error) {
+		for {
+			fields, err := r.Read()
+			if err == io.EOF {
+				return batchEntry{}, io.EOF
+			}
+			line, _ := r.FieldPos(0)
+			if err != nil {
+				return batchEntry{}, err
+			}
+			if line == 1 && fields[0] == "pubkey" {
+				continue
+			}
+			if len(fields) != 3 && len(fields) != 4 {
+				return batchEntry{}, fmt.Errorf("line %d: want 3 or 4 fields, got %d", line, len(fields))
+			}
+			msg, err := base64.StdEncoding.DecodeString(fields[1])
+			if err != nil {
+				return batchEntry{}, fmt.Errorf("line %d: invalid message: %v", line, err)
+			}
+			file := ""
+			if len(fields) == 4 {
+				file = fields[3]
+			}
+			e, err := decodeEntry(fields[0], msg, file, fields[2])
+			if err != nil {
+				return batchEntry{}, fmt.Errorf("line %d: %v", line, err)
+			}
+			e.line = line
+			return e, nil
+		}
+	}
+}
+
+// decodeEntry decodes the fields of a record. If file is not empty, the
+// message is read from it, and msg must be empty.
+func decodeEntry(pubText string, msg []byte, file, sigText string) (batchEntry, error) {
+	pub, err := decodeFixed(pubText, ed25519.PublicKeySize, "public key")
+	if err != nil {
+		return batchEntry{}, err
+	}
+	sig, err := decodeFixed(sigText, ed25519.SignatureSize, "signature")
+	if err != nil {
+		return batchEntry{}, err
+	}
+	if file != "" {
+		if len(msg) != 0 {
+			return batchEntry{}, errors.New("both a message and a message file are given")
+		}
+		if msg, err = os.ReadFile(file); err != nil {
+			return batchEntry{}, err
+		}
+	}
+	return batchEntry{pub: pub, msg: msg, sig: sig}, nil
+}
+
+func runSign(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("sign", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	seedFlag := fs.String("seed", "", "private key `seed` in hex or base64")
+	var msgFlags messageFlags
+	msgFlags.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	seed, err := decodeFixed(*seedFlag, ed25519.SeedSize, "seed")
+	if err != nil {
+		return err
+	}
+	msg, err := msgFlags.message()
+	if err != nil {
+		return err
+	}
+
+	priv := ed25519.NewKeyFromSeed(seed)
+	fmt.Fprintf(stdout, "public key: %x\n", priv.Public())
+	fmt.Fprintf(stdout, "signature: %x\n", ed25519.Sign(priv, msg))
+	return nil
+}
+
+// decodeFixed decodes s from hex or base64, requiring a length of n bytes.
+// The encodings are distinguished by the length of s.
+func decodeFixed(s string, n int, name string) ([]byte, error) {
+	var b []byte
+	var err error
+	switch len(s) {
+	case hex.EncodedLen(n):
+		b, err = hex.DecodeString(s)
+	case base64.StdEncoding.EncodedLen(n):
+		b, err = base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			b, err = base64.URLEncoding.DecodeString(s)
+		}
+	case base64.RawStdEncoding.EncodedLen(n):
+		b, err = base64.RawStdEncoding.DecodeString(s)
+		if err != nil {
+			b, err = base64.RawURLEncoding.DecodeString(s)
+		}
+	default:
+		return nil, fmt.Errorf("%s must be %d bytes in hex or base64", name, n)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return b, nil
+}
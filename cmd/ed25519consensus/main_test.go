@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runCommand(t *testing.T, stdin string, args ...string) (int, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String() + stderr.String()
+}
+
+func TestSignAndVerify(t *testing.T) {
+	seed := strings.Repeat("01", 32)
+	code, out := runCommand(t, "", "sign", "-seed", seed, "-msg", "hello")
+	if code != 0 {
+		t.Fatalf("sign failed with status %d: %s", code, out)
+	}
+	priv := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, 32))
+	pub := priv.Public().(ed25519.PublicKey)
+	sig := ed25519.Sign(priv, []byte("hello"))
+	if want := fmt.Sprintf("public key: %x\nsignature: %x\n", pub, sig); out != want {
+		t.Errorf("sign output %q, want %q", out, want)
+	}
+
+	pubB64 := base64.StdEncoding.EncodeToString(pub)
+	if code, out := runCommand(t, "", "verify", "-pub", pubB64, "-sig", hex.EncodeToString(sig), "-msg", "hello"); code != 0 || out != "valid\n" {
+		t.Errorf("verify: status %d, output %q", code, out)
+	}
+	if code, out := runCommand(t, "", "verify", "-pub", pubB64, "-sig", hex.EncodeToString(sig), "-msg-hex", "00"); code != 1 || out != "invalid\n" {
+		t.Errorf("verify of wrong message: status %d, output %q", code, out)
+	}
+
+	path := filepath.Join(t.TempDir(), "msg")
+	if err := os.WriteFile(path, []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if code, out := runCommand(t, "", "verify", "-pub", pubB64, "-sig", hex.EncodeToString(sig), "-msg-file", path); code != 0 {
+		t.Errorf("verify of message file: status %d, output %q", code, out)
+	}
+
+	if code, _ := runCommand(t, "", "verify", "-pub", "00", "-sig", hex.EncodeToString(sig)); code != 2 {
+		t.Errorf("verify with a short key: status %d, want 2", code)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	// A ZIP215 vector: the identity as A, a non-canonical encoding of the
+	// identity as R, and s = 0.
+	pub := "0100000000000000000000000000000000000000000000000000000000000000"
+	sig := "0100000000000000000000000000000000000000000000000000000000000080" + strings.Repeat("00", 32)
+	code, out := runCommand(t, "", "classify", "-pub", pub, "-sig", sig, "-msg", "Zcash")
+	want := "malformed: false\nzip215: true\nrfc8032: false\ncofactorless: true\n" +
+		"non-canonical A: false\nnon-canonical R: true\nnon-canonical s: false\n" +
+		"small-order A: true\nsmall-order R: true\n"
+	if code != 0 || out != want {
+		t.Errorf("classify: status %d, output %q, want %q", code, out, want)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	var input strings.Builder
+	for i := 0; i < 5; i++ {
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		msg := []byte(fmt.Sprint("message ", i))
+		sig := ed25519.Sign(priv, msg)
+		if i == 3 {
+			msg = []byte("tampered")
+		}
+		fmt.Fprintf(&input, `{"pubkey": %q, "message": %q, "signature": %q}`+"\n",
+			hex.EncodeToString(pub), base64.StdEncoding.EncodeToString(msg), base64.StdEncoding.EncodeToString(sig))
+	}
+	lines := strings.SplitAfter(input.String(), "\n")
+
+	if code, out := runCommand(t, strings.Join(lines[:3], ""), "batch"); code != 0 || out != "3 valid\n" {
+		t.Errorf("batch of valid entries: status %d, output %q", code, out)
+	}
+	if code, out := runCommand(t, input.String(), "batch"); code != 1 || out != "line 4: invalid: ed25519consensus: invalid signature\n4 valid, 1 invalid\n" {
+		t.Errorf("batch with an invalid entry: status %d, output %q", code, out)
+	}
+	if code, out := runCommand(t, input.String(), "batch", "-chunk", "2", "-workers", "3"); code != 1 || out != "line 4: invalid: ed25519consensus: invalid signature\n4 valid, 1 invalid\n" {
+		t.Errorf("batch in chunks: status %d, output %q", code, out)
+	}
+	if code, out := runCommand(t, input.String(), "batch", "-stats"); code != 1 || !strings.Contains(out, "5 entries in 1 batches") {
+		t.Errorf("batch with statistics: status %d, output %q", code, out)
+	}
+	if code, _ := runCommand(t, "{", "batch"); code != 2 {
+		t.Errorf("batch of malformed input: status %d, want 2", code)
+	}
+}
+
+func TestBatchCSV(t *testing.T) {
+	dir := t.TempDir()
+	input := "pubkey,message,signature,message_file\n"
+	for i := 0; i < 4; i++ {
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		msg := []byte(fmt.Sprint("message ", i))
+		sig := ed25519.Sign(priv, msg)
+		switch i {
+		case 1:
+			path := filepath.Join(dir, "msg")
+			if err := os.WriteFile(path, msg, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			input += fmt.Sprintf("%x,,%x,%s\n", pub, sig, path)
+			continue
+		case 2:
+			sig[63] |= 0xe0
+		}
+		input += fmt.Sprintf("%x,%s,%x\n", pub, base64.StdEncoding.EncodeToString(msg), sig)
+	}
+
+	code, out := runCommand(t, input, "batch", "-format", "csv")
+	if code != 1 || out != "line 4: invalid: ed25519consensus: malformed signature\n3 valid, 1 invalid\n" {
+		t.Errorf("CSV batch: status %d, output %q", code, out)
+	}
+	if code, _ := runCommand(t, "a,b\n", "batch", "-format", "csv"); code != 2 {
+		t.Errorf("CSV batch with too few fields: status %d, want 2", code)
+	}
+	if code, _ := runCommand(t, input, "batch", "-format", "xml"); code != 2 {
+		t.Errorf("unknown format: status %d, want 2", code)
+	}
+}
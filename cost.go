@@ -0,0 +1,69 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"sync/atomic"
+	"time"
+)
+
+// defaultVerifyDuration is the duration of one Verify as measured by
+// BenchmarkVerification on a server-class amd64 core.
+const defaultVerifyDuration = 50 * time.Microsecond
+
+// verifyDuration is the duration measured by CalibrateCost, or zero.
+var verifyDuration atomic.Int64
+
+// EstimateCost estimates the work of verifying n signatures with one
+// BatchVerifier, for budgeting verification per block or choosing batch
+// boundaries. It returns the number of bytes hashed besides the messages
+// themselves, the number of scalar-point terms multiplied, and the
+// expected duration on one core if every signature is valid.
+//
+// The duration follows the model of AdaptiveVerifier: a batch equation
+// costs about as much as three quarters of a Verify, plus half of one per
+// entry, and batches too small for ShouldBatch cost one Verify per entry.
+// It assumes the duration of a Verify measured on a typical server unless
+// CalibrateCost is called, and ignores chunking and deferred hashing, which
+// change the latency but not the total work.
+func EstimateCost(n int) (hashBytes, scalarMuls int, approxDuration time.Duration) {
+	if n <= 0 {
+		return 0, 0, 0
+	}
+	unit := time.Duration(verifyDuration.Load())
+	if unit == 0 {
+		unit = defaultVerifyDuration
+	}
+	// Each challenge hashes R and A before the message.
+	hashBytes = n * 64
+	if !ShouldBatch(n) {
+		return hashBytes, 2 * n, time.Duration(n) * unit
+	}
+	units := adaptiveChunkCost + adaptiveEntryCost*float64(n)
+	return hashBytes, 2*n + 1, time.Duration(units * float64(unit))
+}
+
+// CalibrateCost measures the duration of Verify on the running machine, in
+// a few milliseconds, and makes EstimateCost use it. It returns the
+// measured duration.
+func CalibrateCost() time.Duration {
+	const rounds = 32
+	priv := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	pub := priv.Public().(ed25519.PublicKey)
+	msg := []byte("ed25519consensus calibration")
+	sig := ed25519.Sign(priv, msg)
+
+	// Verify without the hooks of SetTracer and SetMetricsCollector, which
+	// must not see calibration signatures.
+	start := time.Now()
+	for i := 0; i < rounds; i++ {
+		e := entry{pubkey: *(*[32]byte)(pub), signature: *(*[64]byte)(sig)}
+		e.computeDigest(msg)
+		verifyDigest(pub, sig, &e.digest, false)
+	}
+	d := time.Since(start) / rounds
+	if d <= 0 {
+		d = 1
+	}
+	verifyDuration.Store(int64(d))
+	return d
+}
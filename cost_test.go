@@ -0,0 +1,36 @@
+package ed25519consensus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEstimateCost(t *testing.T) {
+	if h, m, d := EstimateCost(0); h != 0 || m != 0 || d != 0 {
+		t.Errorf("EstimateCost(0) = %d, %d, %v", h, m, d)
+	}
+	if h, m, d := EstimateCost(1); h != 64 || m != 2 || d != defaultVerifyDuration {
+		t.Errorf("EstimateCost(1) = %d, %d, %v", h, m, d)
+	}
+	h, m, d := EstimateCost(100)
+	if h != 6400 || m != 201 {
+		t.Errorf("EstimateCost(100) = %d, %d, %v", h, m, d)
+	}
+	if d <= 0 || d >= 100*defaultVerifyDuration {
+		t.Errorf("batch of 100 estimated at %v, not faster than individual verification", d)
+	}
+	if _, _, d2 := EstimateCost(200); d2 <= d {
+		t.Errorf("batch of 200 estimated at %v, batch of 100 at %v", d2, d)
+	}
+}
+
+func TestCalibrateCost(t *testing.T) {
+	t.Cleanup(func() { verifyDuration.Store(0) })
+	unit := CalibrateCost()
+	if unit <= 0 || unit > time.Second {
+		t.Fatalf("calibrated Verify duration %v", unit)
+	}
+	if _, _, d := EstimateCost(1); d != unit {
+		t.Errorf("EstimateCost(1) = %v after calibrating to %v", d, unit)
+	}
+}
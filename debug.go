@@ -0,0 +1,67 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+
+	"filippo.io/edwards25519"
+)
+
+// DebugResult is the outcome of VerifyDebug.
+type DebugResult struct {
+	// Valid is the decision of Verify.
+	Valid bool
+	// Err is nil if the signature is valid, and otherwise one of
+	// ErrInvalidKeyLength, ErrInvalidKeyEncoding, ErrMalformedSignature or
+	// ErrInvalidSignature.
+	Err error
+
+	// Challenge is k = SHA-512(R || A || M) reduced modulo the group order.
+	Challenge *edwards25519.Scalar
+	// R is the decoded R component of the signature.
+	R *edwards25519.Point
+	// RecomputedR is R' = [s]B - [k]A. The signature is valid if and only if
+	// R' - R has small order; for signatures produced by a correct signer,
+	// R' = R.
+	//
+	// The fields above are nil if the inputs could not be decoded far
+	// enough to compute them.
+	RecomputedR *edwards25519.Point
+}
+
+// VerifyDebug is like Verify, but also returns the intermediate values of the
+// verification equation, for tracking down where implementations disagree
+// on a signature. It is slower than Verify and meant for diagnostics only.
+func VerifyDebug(publicKey ed25519.PublicKey, message, sig []byte) DebugResult {
+	var res DebugResult
+	if len(publicKey) != ed25519.PublicKeySize {
+		res.Err = ErrInvalidKeyLength
+		return res
+	}
+	A, err := new(edwards25519.Point).SetBytes(publicKey)
+	if err != nil {
+		res.Err = ErrInvalidKeyEncoding
+		return res
+	}
+	if len(sig) != ed25519.SignatureSize || sig[63]&224 != 0 {
+		res.Err = ErrMalformedSignature
+		return res
+	}
+	res.Challenge = ComputeChallenge(sig[:32], publicKey, message)
+	if res.R, err = new(edwards25519.Point).SetBytes(sig[:32]); err != nil {
+		res.Err = ErrMalformedSignature
+		return res
+	}
+	s, err := new(edwards25519.Scalar).SetCanonicalBytes(sig[32:])
+	if err != nil {
+		res.Err = ErrMalformedSignature
+		return res
+	}
+
+	minusA := new(edwards25519.Point).Negate(A)
+	res.RecomputedR = new(edwards25519.Point).VarTimeDoubleScalarBaseMult(res.Challenge, minusA, s)
+	res.Valid = isSmallOrder(new(edwards25519.Point).Subtract(res.RecomputedR, res.R))
+	if !res.Valid {
+		res.Err = ErrInvalidSignature
+	}
+	return res
+}
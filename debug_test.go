@@ -0,0 +1,47 @@
+package ed25519consensus
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/hdevalence/ed25519consensus/testvectors"
+)
+
+func TestVerifyDebug(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("message")
+	sig := ed25519.Sign(priv, msg)
+
+	res := VerifyDebug(pub, msg, sig)
+	if !res.Valid || res.Err != nil {
+		t.Fatalf("valid signature: got %v, %v", res.Valid, res.Err)
+	}
+	if !bytes.Equal(res.RecomputedR.Bytes(), sig[:32]) {
+		t.Error("R' differs from R for an honest signature")
+	}
+
+	bad := append([]byte(nil), sig...)
+	bad[40] ^= 1
+	res = VerifyDebug(pub, msg, bad)
+	if res.Valid || res.Err != ErrInvalidSignature || res.RecomputedR == nil {
+		t.Errorf("tampered signature: got %v, %v, R' %v", res.Valid, res.Err, res.RecomputedR)
+	}
+
+	malformed := append([]byte(nil), sig...)
+	malformed[63] |= 0xe0
+	if res := VerifyDebug(pub, msg, malformed); res.Err != ErrMalformedSignature || res.RecomputedR != nil {
+		t.Errorf("malformed signature: got %v", res.Err)
+	}
+	if res := VerifyDebug(pub[:31], msg, sig); res.Err != ErrInvalidKeyLength {
+		t.Errorf("short key: got %v", res.Err)
+	}
+
+	// The ZIP215 vectors are valid, but R' only matches R up to torsion.
+	for _, v := range testvectors.ZIP215() {
+		res := VerifyDebug(v.PublicKey, v.Message, v.Signature)
+		if res.Valid != Verify(v.PublicKey, v.Message, v.Signature) {
+			t.Errorf("%s: VerifyDebug and Verify disagree", v.Comment)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package ed25519consensus
+
+import "crypto/ed25519"
+
+// DiffResult records the decisions of two verification rules on the same
+// signature.
+type DiffResult struct {
+	// ZIP215 is whether Verify accepts the signature.
+	ZIP215 bool
+	// Stdlib is whether crypto/ed25519.Verify accepts the signature.
+	Stdlib bool
+}
+
+// Disagree reports whether the two rules reached different decisions.
+func (r DiffResult) Disagree() bool {
+	return r.ZIP215 != r.Stdlib
+}
+
+// DiffVerify verifies a signature both with Verify and with
+// crypto/ed25519.Verify, to measure how many historical signatures would be
+// affected by switching from one to the other.
+//
+// The criteria of crypto/ed25519 are those of the Go version this package is
+// built with, and have changed between Go releases.
+func DiffVerify(publicKey ed25519.PublicKey, message, sig []byte) DiffResult {
+	r := DiffResult{ZIP215: Verify(publicKey, message, sig)}
+	// crypto/ed25519.Verify panics on keys of the wrong length.
+	if len(publicKey) == ed25519.PublicKeySize {
+		r.Stdlib = ed25519.Verify(publicKey, message, sig)
+	}
+	return r
+}
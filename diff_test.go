@@ -0,0 +1,34 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/hdevalence/ed25519consensus/testvectors"
+)
+
+func TestDiffVerify(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("message")
+	if r := DiffVerify(pub, msg, ed25519.Sign(priv, msg)); !r.ZIP215 || !r.Stdlib || r.Disagree() {
+		t.Errorf("valid signature: got %+v", r)
+	}
+	if r := DiffVerify(pub[:5], msg, ed25519.Sign(priv, msg)); r.ZIP215 || r.Stdlib {
+		t.Errorf("short key: got %+v", r)
+	}
+
+	// crypto/ed25519 rejects the small-order R values of the ZIP215 vectors.
+	disagreements := 0
+	for _, v := range testvectors.ZIP215() {
+		r := DiffVerify(v.PublicKey, v.Message, v.Signature)
+		if !r.ZIP215 {
+			t.Errorf("%s: rejected by Verify", v.Comment)
+		}
+		if r.Disagree() {
+			disagreements++
+		}
+	}
+	if disagreements == 0 {
+		t.Error("no disagreement found on the ZIP215 vectors")
+	}
+}
@@ -0,0 +1,192 @@
+// Package differential compares the decisions of Ed25519 verifiers on the
+// same inputs, so that a disagreement that could fork a chain is caught by a
+// test or a fuzzer rather than in production.
+//
+// Two kinds of disagreement matter. The verifiers of ed25519consensus, such
+// as Verify, VerifyConstantTime and BatchVerifier, must agree exactly, with
+// each other and with ed25519-zebra, which implements the same ZIP215 rules.
+// Other implementations, such as crypto/ed25519, are expected to reject some
+// signatures that ZIP215 accepts, but only for the documented reasons; a
+// signature they accept and ZIP215 rejects is always a bug.
+package differential
+
+import (
+	"crypto/ed25519"
+	cryptorand "crypto/rand"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/hdevalence/ed25519consensus"
+	"github.com/hdevalence/ed25519consensus/testgen"
+	"github.com/hdevalence/ed25519consensus/testvectors"
+)
+
+// Input is a (public key, message, signature) triple.
+type Input struct {
+	PublicKey []byte
+	Message   []byte
+	Signature []byte
+}
+
+// Verifier is a named verification function.
+type Verifier struct {
+	Name   string
+	Verify func(publicKey ed25519.PublicKey, message, sig []byte) bool
+}
+
+// Consensus returns the verifiers of ed25519consensus that implement ZIP215,
+// which must all agree on every input.
+func Consensus() []Verifier {
+	return []Verifier{
+		{"Verify", ed25519consensus.Verify},
+		{"VerifyV1", ed25519consensus.VerifyV1},
+		{"VerifyConstantTime", ed25519consensus.VerifyConstantTime},
+		{"Policy.Verify", ed25519consensus.Policy{}.Verify},
+		{"VerifyWithOptions", func(publicKey ed25519.PublicKey, message, sig []byte) bool {
+			return ed25519consensus.VerifyWithOptions(publicKey, message, sig, &ed25519consensus.Options{}) == nil
+		}},
+		{"BatchVerifier", func(publicKey ed25519.PublicKey, message, sig []byte) bool {
+			v := ed25519consensus.NewBatchVerifier()
+			v.Add(publicKey, message, sig)
+			return v.Verify()
+		}},
+	}
+}
+
+// Disagreement is an input on which verifiers reached different decisions.
+type Disagreement struct {
+	Input
+	// Decisions maps the name of each verifier to whether it accepted the
+	// input.
+	Decisions map[string]bool
+}
+
+func (d *Disagreement) Error() string {
+	return fmt.Sprintf("differential: verifiers disagree on key %x, message %x, signature %x: %v",
+		d.PublicKey, d.Message, d.Signature, d.Decisions)
+}
+
+// Compare runs every verifier on in, and returns a Disagreement if they did
+// not all reach the same decision.
+func Compare(verifiers []Verifier, in Input) *Disagreement {
+	d := &Disagreement{Input: in, Decisions: make(map[string]bool, len(verifiers))}
+	agree := true
+	for _, v := range verifiers {
+		d.Decisions[v.Name] = v.Verify(in.PublicKey, in.Message, in.Signature)
+		if d.Decisions[v.Name] != d.Decisions[verifiers[0].Name] {
+			agree = false
+		}
+	}
+	if agree {
+		return nil
+	}
+	return d
+}
+
+// CompareStdlib compares Verify against crypto/ed25519.Verify on in. It
+// returns nil if they agree, or if Verify accepts a signature that
+// crypto/ed25519 rejects because R is not canonically encoded or because A
+// or R has a small-order component, the documented differences between the
+// two rules. Otherwise it returns the Disagreement.
+func CompareStdlib(in Input) *Disagreement {
+	r := ed25519consensus.DiffVerify(in.PublicKey, in.Message, in.Signature)
+	if !r.Disagree() {
+		return nil
+	}
+	if r.ZIP215 && explained(in) {
+		return nil
+	}
+	return &Disagreement{
+		Input:     in,
+		Decisions: map[string]bool{"Verify": r.ZIP215, "crypto/ed25519": r.Stdlib},
+	}
+}
+
+// explained reports whether in, which Verify accepts, exercises one of the documented reasons for
+// crypto/ed25519 to reject a signature that ZIP215 accepts. For any other
+// signature valid under the cofactored equation, R equals [s]B - [k]A
+// exactly, which the cofactorless equation of crypto/ed25519 accepts.
+func explained(in Input) bool {
+	c := ed25519consensus.Classify(in.PublicKey, in.Message, in.Signature)
+	return c.NonCanonicalR ||
+		ed25519consensus.HasSmallOrderComponent(in.PublicKey) || c.SmallOrderA ||
+		ed25519consensus.HasSmallOrderComponent(in.Signature[:32]) || c.SmallOrderR
+}
+
+// ZebraVectors returns inputs with the decisions of ed25519-zebra, the
+// reference implementation of ZIP215: the vectors of the testvectors
+// package, whose ZIP215 vectors were produced by it.
+func ZebraVectors() ([]Input, []bool) {
+	vectors := testvectors.All()
+	inputs := make([]Input, len(vectors))
+	valid := make([]bool, len(vectors))
+	for i, v := range vectors {
+		inputs[i] = Input{v.PublicKey, v.Message, v.Signature}
+		valid[i] = v.Valid
+	}
+	return inputs, valid
+}
+
+// Generate returns n inputs exercising the boundary conditions of ZIP215:
+// the cases of testgen.Generate, and copies of them with a random bit
+// flipped. Randomness is drawn from rand, or from crypto/rand if rand is
+// nil.
+func Generate(rand io.Reader, n int) ([]Input, error) {
+	if rand == nil {
+		rand = cryptorand.Reader
+	}
+	var inputs []Input
+	for len(inputs) < n {
+		cases, err := testgen.Generate(rand)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range cases {
+			in := Input{c.PublicKey, c.Message, c.Signature}
+			mutated, err := mutate(rand, in)
+			if err != nil {
+				return nil, err
+			}
+			inputs = append(inputs, in, mutated)
+		}
+	}
+	return inputs[:n], nil
+}
+
+// mutate returns a copy of in with one random bit of the public key or the
+// signature flipped.
+func mutate(rand io.Reader, in Input) (Input, error) {
+	var b [2]byte
+	if _, err := io.ReadFull(rand, b[:]); err != nil {
+		return Input{}, err
+	}
+	out := Input{
+		PublicKey: append([]byte(nil), in.PublicKey...),
+		Message:   in.Message,
+		Signature: append([]byte(nil), in.Signature...),
+	}
+	i := int(b[0]) % (len(out.PublicKey) + len(out.Signature))
+	bit := byte(1) << (b[1] % 8)
+	if i < len(out.PublicKey) {
+		out.PublicKey[i] ^= bit
+	} else {
+		out.Signature[i-len(out.PublicKey)] ^= bit
+	}
+	return out, nil
+}
+
+// Check reports on t every disagreement among the Consensus verifiers, and
+// every unexplained disagreement with crypto/ed25519, on inputs.
+func Check(t testing.TB, inputs []Input) {
+	t.Helper()
+	verifiers := Consensus()
+	for _, in := range inputs {
+		if d := Compare(verifiers, in); d != nil {
+			t.Error(d)
+		}
+		if d := CompareStdlib(in); d != nil {
+			t.Error(d)
+		}
+	}
+}
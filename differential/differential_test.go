@@ -0,0 +1,74 @@
+package differential
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/hdevalence/ed25519consensus/testvectors"
+)
+
+func TestZebraVectors(t *testing.T) {
+	inputs, valid := ZebraVectors()
+	for _, v := range Consensus() {
+		for i, in := range inputs {
+			if got := v.Verify(in.PublicKey, in.Message, in.Signature); got != valid[i] {
+				t.Errorf("%s: input %d: got %v, want %v", v.Name, i, got, valid[i])
+			}
+		}
+	}
+	Check(t, inputs)
+}
+
+func TestGenerate(t *testing.T) {
+	inputs, err := Generate(nil, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) != 100 {
+		t.Fatalf("got %d inputs, want 100", len(inputs))
+	}
+	Check(t, inputs)
+}
+
+func TestCompare(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("message")
+	in := Input{pub, msg, ed25519.Sign(priv, msg)}
+	accept := Verifier{"accept", func(ed25519.PublicKey, []byte, []byte) bool { return true }}
+	reject := Verifier{"reject", func(ed25519.PublicKey, []byte, []byte) bool { return false }}
+
+	if d := Compare(append(Consensus(), accept), in); d != nil {
+		t.Errorf("unexpected disagreement: %v", d)
+	}
+	d := Compare(append(Consensus(), reject), in)
+	if d == nil {
+		t.Fatal("disagreement not detected")
+	}
+	if !d.Decisions["Verify"] || d.Decisions["reject"] {
+		t.Errorf("wrong decisions: %v", d.Decisions)
+	}
+}
+
+func TestCompareStdlib(t *testing.T) {
+	// The ZIP215 vectors are rejected by crypto/ed25519 for documented
+	// reasons.
+	for _, v := range testvectors.ZIP215() {
+		if d := CompareStdlib(Input{v.PublicKey, v.Message, v.Signature}); d != nil {
+			t.Errorf("%s: %v", v.Comment, d)
+		}
+	}
+}
+
+func FuzzDifferential(f *testing.F) {
+	inputs, _ := ZebraVectors()
+	generated, err := Generate(nil, 28)
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, in := range append(inputs, generated...) {
+		f.Add(in.PublicKey, in.Message, in.Signature)
+	}
+	f.Fuzz(func(t *testing.T, publicKey, message, sig []byte) {
+		Check(t, []Input{{publicKey, message, sig}})
+	})
+}
@@ -9,6 +9,7 @@ package ed25519consensus
 import (
 	"crypto/ed25519"
 	"crypto/sha512"
+	"hash"
 
 	"filippo.io/edwards25519"
 )
@@ -17,28 +18,310 @@ import (
 // publicKey, using precisely-specified validation criteria (ZIP 215) suitable
 // for use in consensus-critical contexts.
 func Verify(publicKey ed25519.PublicKey, message, sig []byte) bool {
+	if m := metrics.Load(); m != nil {
+		return m.observeVerify(publicKey, message, sig)
+	}
+	return verify(publicKey, message, sig)
+}
+
+// verify implements Verify.
+func verify(publicKey ed25519.PublicKey, message, sig []byte) bool {
+	if l := len(publicKey); l != ed25519.PublicKeySize {
+		return false
+	}
+
+	if len(sig) != ed25519.SignatureSize || sig[63]&224 != 0 {
+		return false
+	}
+
+	// The hash state, digest, points and scalars all stay on the stack, so
+	// Verify does not allocate; TestVerifyAllocations guards against
+	// regressions that would make them escape.
+	h := sha512.New()
+	h.Write(sig[:32])
+	h.Write(publicKey[:])
+	h.Write(message)
+	var digest [64]byte
+	h.Sum(digest[:0])
+
+	valid := verifyDigest(publicKey, sig, &digest, false)
+	traceDigest(publicKey, sig, nil, &digest, nil, valid)
+	return valid
+}
+
+// VerifyConstantTime is like Verify, but computes the verification equation
+// with constant-time scalar multiplications, so that its timing does not
+// depend on the public key, the message or a well-formed signature. This is
+// useful where the choice of what to verify depends on secrets, such as in
+// blind signature protocols. It is several times slower than Verify.
+//
+// Malformed inputs, such as undecodable points or a non-canonical s, are
+// still rejected early.
+func VerifyConstantTime(publicKey ed25519.PublicKey, message, sig []byte) bool {
+	if l := len(publicKey); l != ed25519.PublicKeySize {
+		return false
+	}
+
+	if len(sig) != ed25519.SignatureSize || sig[63]&224 != 0 {
+		return false
+	}
+
+	h := sha512.New()
+	h.Write(sig[:32])
+	h.Write(publicKey[:])
+	h.Write(message)
+	var digest [64]byte
+	h.Sum(digest[:0])
+
+	valid := verifyDigest(publicKey, sig, &digest, true)
+	traceDigest(publicKey, sig, nil, &digest, nil, valid)
+	return valid
+}
+
+// ParsedSignature is a signature whose R point and s scalar have been decoded
+// once by ParseSignature, so that verifying it, possibly several times as
+// when re-checking a failed batch, skips the decompression of R.
+type ParsedSignature struct {
+	encoding [ed25519.SignatureSize]byte
+	R        edwards25519.Point
+	s        edwards25519.Scalar
+}
+
+// ParseSignature decodes sig with the rules of Verify: R may be any valid
+// point encoding, including non-canonical ones, and s must be reduced. It
+// returns ErrMalformedSignature if sig has the wrong length or does not
+// decode.
+func ParseSignature(sig []byte) (*ParsedSignature, error) {
+	if len(sig) != ed25519.SignatureSize || sig[63]&224 != 0 {
+		return nil, ErrMalformedSignature
+	}
+	ps := new(ParsedSignature)
+	copy(ps.encoding[:], sig)
+	if _, err := ps.R.SetBytes(sig[:32]); err != nil {
+		return nil, ErrMalformedSignature
+	}
+	if _, err := ps.s.SetCanonicalBytes(sig[32:]); err != nil {
+		return nil, ErrMalformedSignature
+	}
+	return ps, nil
+}
+
+// Bytes returns the encoding sig was parsed from.
+func (ps *ParsedSignature) Bytes() []byte {
+	b := ps.encoding
+	return b[:]
+}
+
+// VerifyParsed is like Verify, for a signature decoded with ParseSignature.
+func VerifyParsed(publicKey ed25519.PublicKey, message []byte, sig *ParsedSignature) bool {
 	if l := len(publicKey); l != ed25519.PublicKeySize {
 		return false
 	}
+	A := new(edwards25519.Point)
+	if err := decodeKey(A, publicKey); err != nil {
+		return false
+	}
 
+	h := sha512.New()
+	h.Write(sig.encoding[:32])
+	h.Write(publicKey[:])
+	h.Write(message)
+	var digest [64]byte
+	k, _ := new(edwards25519.Scalar).SetUniformBytes(h.Sum(digest[:0]))
+
+	return verifyEquation(A, &sig.R, &sig.s, k, false)
+}
+
+// VerifyDecoded is like Verify, for a public key already decoded to the point
+// A by the caller, for example from a cache of its own, so that it is not
+// decoded again. minusA may be nil, or the precomputed -A to save negating A.
+//
+// publicKey must be the encoding A was decoded from, which is hashed into the
+// challenge and, under ZIP215, need not be canonical, so that it cannot be
+// recovered from A. VerifyDecoded does not check that they match.
+func VerifyDecoded(publicKey ed25519.PublicKey, A, minusA *edwards25519.Point, message, sig []byte) bool {
+	if l := len(publicKey); l != ed25519.PublicKeySize {
+		return false
+	}
 	if len(sig) != ed25519.SignatureSize || sig[63]&224 != 0 {
 		return false
 	}
 
+	h := sha512.New()
+	h.Write(sig[:32])
+	h.Write(publicKey[:])
+	h.Write(message)
+	var digest [64]byte
+	k, _ := new(edwards25519.Scalar).SetUniformBytes(h.Sum(digest[:0]))
+
 	// ZIP215: this works because SetBytes does not check that encodings are canonical.
-	A, err := new(edwards25519.Point).SetBytes(publicKey)
+	R, err := new(edwards25519.Point).SetBytes(sig[:32])
+	if err != nil {
+		return false
+	}
+	s, err := new(edwards25519.Scalar).SetCanonicalBytes(sig[32:])
 	if err != nil {
 		return false
 	}
-	A.Negate(A)
+
+	if minusA == nil {
+		return verifyEquation(A, R, s, k, false)
+	}
+	return verifyNegatedEquation(minusA, R, s, k, false)
+}
+
+// VerifyPH reports whether sig is a valid Ed25519ph signature by publicKey of
+// the message whose SHA-512 hash is digest, under the given context string,
+// using the same validation criteria as Verify.
+func VerifyPH(publicKey ed25519.PublicKey, digest, sig []byte, context string) bool {
+	if len(digest) != sha512.Size {
+		return false
+	}
+	return verifyWithDom2(publicKey, digest, sig, dom2(1, context))
+}
+
+// VerifyWithContext reports whether sig is a valid Ed25519ctx signature of
+// message by publicKey under the given context string, using the same
+// validation criteria as Verify. As in crypto/ed25519, an empty context
+// selects plain Ed25519.
+func VerifyWithContext(publicKey ed25519.PublicKey, message, sig []byte, context string) bool {
+	if context == "" {
+		return Verify(publicKey, message, sig)
+	}
+	return verifyWithDom2(publicKey, message, sig, dom2(0, context))
+}
+
+// ComputeChallenge returns the Ed25519 challenge scalar k = SHA-512(R || A ||
+// M) mod L for the R component R of a signature, the public key A and the
+// message M, exactly as computed by Verify. It returns nil if R or publicKey
+// do not have a length of 32 bytes.
+//
+// The encodings are hashed as given, without decoding or canonicalizing them.
+func ComputeChallenge(R []byte, publicKey ed25519.PublicKey, message []byte) *edwards25519.Scalar {
+	if len(R) != 32 || len(publicKey) != ed25519.PublicKeySize {
+		return nil
+	}
+	h := sha512.New()
+	h.Write(R)
+	h.Write(publicKey)
+	h.Write(message)
+	var digest [64]byte
+	k, _ := new(edwards25519.Scalar).SetUniformBytes(h.Sum(digest[:0]))
+	return k
+}
+
+// domPrefix is the prefix of the RFC 8032 dom2 domain separator.
+const domPrefix = "SigEd25519 no Ed25519 collisions"
+
+// dom2 returns the RFC 8032 domain separator for the Ed25519ph (phflag 1) and
+// Ed25519ctx (phflag 0) variants, or nil if context is too long.
+func dom2(phflag byte, context string) []byte {
+	if len(context) > 255 {
+		return nil
+	}
+	dom := make([]byte, 0, len(domPrefix)+2+len(context))
+	dom = append(dom, domPrefix...)
+	dom = append(dom, phflag, byte(len(context)))
+	return append(dom, context...)
+}
+
+// verifyWithDom2 is like Verify, but prepends dom to the challenge hash input.
+func verifyWithDom2(publicKey ed25519.PublicKey, message, sig, dom []byte) bool {
+	if dom == nil {
+		return false
+	}
+
+	if l := len(publicKey); l != ed25519.PublicKeySize {
+		return false
+	}
+
+	if len(sig) != ed25519.SignatureSize || sig[63]&224 != 0 {
+		return false
+	}
 
 	h := sha512.New()
+	h.Write(dom)
 	h.Write(sig[:32])
 	h.Write(publicKey[:])
 	h.Write(message)
 	var digest [64]byte
 	h.Sum(digest[:0])
 
+	valid := verifyDigest(publicKey, sig, &digest, false)
+	traceDigest(publicKey, sig, dom, &digest, nil, valid)
+	return valid
+}
+
+// Verifier verifies signatures exactly like Verify, but reuses its hash state
+// across calls, so that verification performs no heap allocations regardless
+// of how the compiler's escape analysis treats Verify. The zero value is ready
+// to use.
+//
+// A Verifier must not be used concurrently by multiple goroutines.
+type Verifier struct {
+	h      hash.Hash
+	digest [64]byte
+}
+
+// NewVerifierWithHash returns a Verifier that computes the challenge with the
+// hash returned by newHash instead of SHA-512, for example to use a hardware
+// SHA-512 engine or an experimental hash on a private network. newHash must
+// return hashes with a 64-byte output, or NewVerifierWithHash panics.
+//
+// Signatures checked with any hash other than SHA-512 are not Ed25519
+// signatures, and Verifiers for them must not be used where consensus with
+// other implementations is required.
+func NewVerifierWithHash(newHash func() hash.Hash) *Verifier {
+	h := newHash()
+	if h.Size() != sha512.Size {
+		panic("ed25519consensus: challenge hash must have a 64-byte output")
+	}
+	return &Verifier{h: h}
+}
+
+// Verify reports whether sig is a valid signature of message by publicKey,
+// with the same semantics as the package-level Verify.
+func (v *Verifier) Verify(publicKey ed25519.PublicKey, message, sig []byte) bool {
+	if l := len(publicKey); l != ed25519.PublicKeySize {
+		return false
+	}
+
+	if len(sig) != ed25519.SignatureSize || sig[63]&224 != 0 {
+		return false
+	}
+
+	if v.h == nil {
+		v.h = sha512.New()
+	} else {
+		v.h.Reset()
+	}
+	v.h.Write(sig[:32])
+	v.h.Write(publicKey[:])
+	v.h.Write(message)
+	v.h.Sum(v.digest[:0])
+
+	valid := verifyDigest(publicKey, sig, &v.digest, false)
+	traceDigest(publicKey, sig, nil, &v.digest, nil, valid)
+	return valid
+}
+
+// verifyDigest checks the ZIP215 verification equation for a public key and
+// signature of the correct lengths, given the SHA-512 digest of R || A || M.
+// If constantTime is set, the equation is computed in constant time.
+func verifyDigest(publicKey, sig []byte, digest *[64]byte, constantTime bool) bool {
+	// ZIP215: this works because SetBytes does not check that encodings are canonical.
+	// The key cache is skipped in constant time, as hits would be faster.
+	A := new(edwards25519.Point)
+	var err error
+	if constantTime {
+		_, err = A.SetBytes(publicKey)
+	} else {
+		err = decodeKey(A, publicKey)
+	}
+	if err != nil {
+		return false
+	}
+
 	hReduced, err := new(edwards25519.Scalar).SetUniformBytes(digest[:])
 	if err != nil {
 		return false
@@ -58,7 +341,37 @@ func Verify(publicKey ed25519.PublicKey, message, sig []byte) bool {
 		return false
 	}
 
-	R := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(hReduced, A, s)
+	return verifyEquation(A, checkR, s, hReduced, constantTime)
+}
+
+// VerifyExpanded reports whether the decoded signature (R, s) is valid for
+// the decoded public key A and the challenge k, by checking the ZIP215
+// cofactored equation [8]([s]B - R - [k]A) = 0. It is meant for callers that
+// decode and cache curve elements themselves, and compute k for example with
+// ComputeChallenge.
+//
+// VerifyExpanded does not modify its arguments. Since they are already
+// decoded, the encoding checks performed by Verify, including that s is
+// canonical, are the responsibility of the caller.
+func VerifyExpanded(A, R *edwards25519.Point, s, k *edwards25519.Scalar) bool {
+	return verifyEquation(A, R, s, k, false)
+}
+
+// verifyEquation checks [8]([s]B - [k]A - R) == 0.
+func verifyEquation(A, checkR *edwards25519.Point, s, k *edwards25519.Scalar, constantTime bool) bool {
+	minusA := new(edwards25519.Point).Negate(A)
+	return verifyNegatedEquation(minusA, checkR, s, k, constantTime)
+}
+
+// verifyNegatedEquation is verifyEquation for the negated public key -A.
+func verifyNegatedEquation(minusA, checkR *edwards25519.Point, s, k *edwards25519.Scalar, constantTime bool) bool {
+	var R *edwards25519.Point
+	if constantTime {
+		R = new(edwards25519.Point).ScalarMult(k, minusA)
+		R.Add(R, new(edwards25519.Point).ScalarBaseMult(s))
+	} else {
+		R = selectedBackend{}.varTimeDoubleScalarBaseMult(new(edwards25519.Point), k, minusA, s)
+	}
 
 	// ZIP215: We want to check [8](R - checkR) == 0
 	p := new(edwards25519.Point).Subtract(R, checkR) // p = R - checkR
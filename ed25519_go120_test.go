@@ -0,0 +1,58 @@
+//go:build go1.20
+
+package ed25519consensus_test
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"strings"
+	"testing"
+
+	"github.com/hdevalence/ed25519consensus"
+)
+
+// These tests sign with ed25519.Options.Context, which was added in Go 1.20.
+
+func TestVerifyPH(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	digest := sha512.Sum512([]byte("prehashed message"))
+	for _, context := range []string{"", "ctx"} {
+		sig, err := priv.Sign(nil, digest[:], &ed25519.Options{Hash: crypto.SHA512, Context: context})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ed25519consensus.VerifyPH(pub, digest[:], sig, context) {
+			t.Errorf("Ed25519ph signature with context %q failed to verify", context)
+		}
+		if ed25519consensus.VerifyPH(pub, digest[:], sig, "other") {
+			t.Errorf("Ed25519ph signature with context %q verified under another context", context)
+		}
+		if ed25519consensus.Verify(pub, digest[:], sig) {
+			t.Errorf("Ed25519ph signature with context %q verified as plain Ed25519", context)
+		}
+	}
+	if ed25519consensus.VerifyPH(pub, digest[:32], make([]byte, 64), "") {
+		t.Error("Ed25519ph signature verified with a short digest")
+	}
+}
+
+func TestVerifyWithContext(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("message with context")
+	for _, context := range []string{"", "ctx", strings.Repeat("x", 255)} {
+		sig, err := priv.Sign(nil, msg, &ed25519.Options{Context: context})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ed25519consensus.VerifyWithContext(pub, msg, sig, context) {
+			t.Errorf("Ed25519ctx signature with context %q failed to verify", context)
+		}
+		if ed25519consensus.VerifyWithContext(pub, msg, sig, "other") {
+			t.Errorf("Ed25519ctx signature with context %q verified under another context", context)
+		}
+	}
+	if ed25519consensus.VerifyWithContext(pub, msg, make([]byte, 64), strings.Repeat("x", 256)) {
+		t.Error("signature verified with an overlong context")
+	}
+}
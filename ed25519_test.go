@@ -1,12 +1,287 @@
 package ed25519consensus_test
 
 import (
+	"bytes"
 	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
 	"testing"
 
+	"filippo.io/edwards25519"
 	"github.com/hdevalence/ed25519consensus"
+	"github.com/hdevalence/ed25519consensus/testvectors"
 )
 
+func TestVerifyAllocations(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("Single key verification")
+	sig := ed25519.Sign(priv, msg)
+	if allocs := testing.AllocsPerRun(100, func() {
+		if !ed25519consensus.Verify(pub, msg, sig) {
+			t.Fatal("signature failed to verify")
+		}
+	}); allocs > 0 {
+		t.Errorf("expected zero allocations, got %0.1f", allocs)
+	}
+}
+
+func TestVerifyConstantTime(t *testing.T) {
+	testvectors.Run(t, ed25519consensus.VerifyConstantTime)
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("blinded message")
+	sig := ed25519.Sign(priv, msg)
+	if !ed25519consensus.VerifyConstantTime(pub, msg, sig) {
+		t.Error("signature failed to verify")
+	}
+	if ed25519consensus.VerifyConstantTime(pub, []byte("other"), sig) {
+		t.Error("signature verified for the wrong message")
+	}
+}
+
+func TestComputeChallengeAndVerifyExpanded(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("challenge")
+	sig := ed25519.Sign(priv, msg)
+
+	// [s]B = R + [k]A for signatures made by crypto/ed25519.
+	k := ed25519consensus.ComputeChallenge(sig[:32], pub, msg)
+	A, _ := new(edwards25519.Point).SetBytes(pub)
+	R, _ := new(edwards25519.Point).SetBytes(sig[:32])
+	s, _ := new(edwards25519.Scalar).SetCanonicalBytes(sig[32:])
+	want := new(edwards25519.Point).ScalarBaseMult(s)
+	got := new(edwards25519.Point).ScalarMult(k, A)
+	if got.Add(got, R).Equal(want) != 1 {
+		t.Error("challenge does not satisfy the verification equation")
+	}
+
+	if !ed25519consensus.VerifyExpanded(A, R, s, k) {
+		t.Error("VerifyExpanded rejected a valid signature")
+	}
+	if ed25519consensus.VerifyExpanded(A, R, s, ed25519consensus.ComputeChallenge(sig[:32], pub, nil)) {
+		t.Error("VerifyExpanded accepted the wrong challenge")
+	}
+
+	if ed25519consensus.ComputeChallenge(sig[:31], pub, msg) != nil {
+		t.Error("challenge computed for a short R")
+	}
+}
+
+func TestParseSignature(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("parse once")
+	sig := ed25519.Sign(priv, msg)
+
+	ps, err := ed25519consensus.ParseSignature(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ps.Bytes(), sig) {
+		t.Error("Bytes does not return the parsed encoding")
+	}
+	if !ed25519consensus.VerifyParsed(pub, msg, ps) {
+		t.Error("parsed signature failed to verify")
+	}
+	if ed25519consensus.VerifyParsed(pub, []byte("other"), ps) {
+		t.Error("parsed signature verified for the wrong message")
+	}
+
+	v := ed25519consensus.NewBatchVerifier()
+	v.AddParsed(pub, msg, ps)
+	v.Add(pub, msg, sig)
+	if !v.Verify() {
+		t.Error("batch with a parsed signature failed to verify")
+	}
+	v.AddParsed(pub, []byte("other"), ps)
+	if v.Verify() {
+		t.Error("batch with a parsed signature verified for the wrong message")
+	}
+	v = ed25519consensus.NewBatchVerifier()
+	v.AddParsed(pub, msg, nil)
+	if v.Verify() {
+		t.Error("batch with a nil parsed signature verified")
+	}
+
+	for _, v := range testvectors.All() {
+		ps, err := ed25519consensus.ParseSignature(v.Signature)
+		got := err == nil && ed25519consensus.VerifyParsed(v.PublicKey, v.Message, ps)
+		if got != v.Valid {
+			t.Errorf("%s: got %v, want %v", v.Comment, got, v.Valid)
+		}
+	}
+
+	badS := append([]byte(nil), sig...)
+	badS[63] |= 0x10
+	for _, bad := range [][]byte{sig[:63], badS} {
+		if _, err := ed25519consensus.ParseSignature(bad); err != ed25519consensus.ErrMalformedSignature {
+			t.Errorf("ParseSignature(%x) = %v", bad, err)
+		}
+	}
+}
+
+func TestVerifyDecoded(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("decoded key")
+	sig := ed25519.Sign(priv, msg)
+	A, err := new(edwards25519.Point).SetBytes(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	minusA := new(edwards25519.Point).Negate(A)
+
+	if !ed25519consensus.VerifyDecoded(pub, A, nil, msg, sig) {
+		t.Error("signature failed to verify")
+	}
+	if !ed25519consensus.VerifyDecoded(pub, A, minusA, msg, sig) {
+		t.Error("signature failed to verify with a precomputed -A")
+	}
+	if ed25519consensus.VerifyDecoded(pub, A, nil, []byte("other"), sig) {
+		t.Error("signature verified for the wrong message")
+	}
+	if allocs := testing.AllocsPerRun(100, func() {
+		ed25519consensus.VerifyDecoded(pub, A, minusA, msg, sig)
+	}); allocs > 0 {
+		t.Errorf("expected zero allocations, got %0.1f", allocs)
+	}
+
+	v := ed25519consensus.NewBatchVerifier()
+	v.AddDecoded(pub, A, msg, sig)
+	v.Add(pub, msg, sig)
+	if !v.Verify() {
+		t.Error("batch with a decoded key failed to verify")
+	}
+	v.AddDecoded(pub, A, []byte("other"), sig)
+	if v.Verify() {
+		t.Error("batch with a decoded key verified for the wrong message")
+	}
+	v = ed25519consensus.NewBatchVerifier()
+	v.AddDecoded(pub, nil, msg, sig)
+	if v.Verify() {
+		t.Error("batch with a nil key verified")
+	}
+
+	// The decoded key is used even when the batch falls back to checking
+	// entries one by one.
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	otherA, _ := new(edwards25519.Point).SetBytes(otherPub)
+	v = ed25519consensus.NewBatchVerifier()
+	v.AddDecoded(pub, otherA, msg, sig)
+	if v.Verify() {
+		t.Error("single entry verified with the encoded key instead of the decoded one")
+	}
+
+	// Non-canonical keys are hashed as encoded, not as A.Bytes().
+	for _, v := range testvectors.ZIP215() {
+		A, err := new(edwards25519.Point).SetBytes(v.PublicKey)
+		if err != nil {
+			t.Fatalf("%s: %v", v.Comment, err)
+		}
+		if !ed25519consensus.VerifyDecoded(v.PublicKey, A, nil, v.Message, v.Signature) {
+			t.Errorf("%s: rejected", v.Comment)
+		}
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("Single key verification")
+	sig := ed25519.Sign(priv, msg)
+
+	var v ed25519consensus.Verifier
+	for i := 0; i < 2; i++ {
+		if !v.Verify(pub, msg, sig) {
+			t.Error("signature failed to verify")
+		}
+		if v.Verify(pub, []byte("Another message"), sig) {
+			t.Error("signature verified for the wrong message")
+		}
+	}
+}
+
+func TestVerifierAllocations(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("Single key verification")
+	sig := ed25519.Sign(priv, msg)
+	var v ed25519consensus.Verifier
+	if allocs := testing.AllocsPerRun(100, func() {
+		if !v.Verify(pub, msg, sig) {
+			t.Fatal("signature failed to verify")
+		}
+	}); allocs > 0 {
+		t.Errorf("expected zero allocations, got %0.1f", allocs)
+	}
+}
+
+func TestNewVerifierWithHash(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("Single key verification")
+	sig := ed25519.Sign(priv, msg)
+
+	if v := ed25519consensus.NewVerifierWithHash(sha512.New); !v.Verify(pub, msg, sig) {
+		t.Error("signature failed to verify with an explicit SHA-512")
+	}
+
+	v := ed25519consensus.NewVerifierWithHash(newAltHash)
+	if v.Verify(pub, msg, sig) {
+		t.Error("SHA-512 signature verified with another hash")
+	}
+	pubAlt, sigAlt := signWithHash(priv.Seed(), msg, newAltHash)
+	for i := 0; i < 2; i++ {
+		if !v.Verify(pubAlt, msg, sigAlt) {
+			t.Error("signature with another hash failed to verify")
+		}
+	}
+	if ed25519consensus.Verify(pubAlt, msg, sigAlt) {
+		t.Error("signature with another hash verified with SHA-512")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("NewVerifierWithHash accepted a 32-byte hash")
+		}
+	}()
+	ed25519consensus.NewVerifierWithHash(sha256.New)
+}
+
+// altHash is SHA-512 with a prefix, standing in for another 64-byte hash.
+type altHash struct{ hash.Hash }
+
+func newAltHash() hash.Hash {
+	h := altHash{sha512.New()}
+	h.Reset()
+	return h
+}
+
+func (h altHash) Reset() {
+	h.Hash.Reset()
+	h.Hash.Write([]byte("alternative hash"))
+}
+
+// signWithHash derives a key from seed and signs like RFC 8032 Ed25519,
+// replacing SHA-512 with newHash.
+func signWithHash(seed, message []byte, newHash func() hash.Hash) (ed25519.PublicKey, []byte) {
+	h := newHash()
+	h.Write(seed)
+	digest := h.Sum(nil)
+	a, _ := new(edwards25519.Scalar).SetBytesWithClamping(digest[:32])
+	A := new(edwards25519.Point).ScalarBaseMult(a)
+
+	h.Reset()
+	h.Write(digest[32:])
+	h.Write(message)
+	r, _ := new(edwards25519.Scalar).SetUniformBytes(h.Sum(nil))
+	R := new(edwards25519.Point).ScalarBaseMult(r)
+
+	h.Reset()
+	h.Write(R.Bytes())
+	h.Write(A.Bytes())
+	h.Write(message)
+	k, _ := new(edwards25519.Scalar).SetUniformBytes(h.Sum(nil))
+	s := new(edwards25519.Scalar).MultiplyAdd(k, a, r)
+	return A.Bytes(), append(R.Bytes(), s.Bytes()...)
+}
+
 func BenchmarkVerification(b *testing.B) {
 	b.ReportAllocs()
 	pub, priv, _ := ed25519.GenerateKey(nil)
@@ -17,3 +292,19 @@ func BenchmarkVerification(b *testing.B) {
 		ed25519consensus.Verify(pub, hash, signature)
 	}
 }
+
+func BenchmarkVerifier(b *testing.B) {
+	b.ReportAllocs()
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	hash := []byte("Single key verification")
+	signature := ed25519.Sign(priv, hash)
+	var v ed25519consensus.Verifier
+	v.Verify(pub, hash, signature)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v.Verify(pub, hash, signature)
+	}
+	if allocs := testing.AllocsPerRun(10, func() { v.Verify(pub, hash, signature) }); allocs > 0 {
+		b.Errorf("expected zero allocations, got %0.1f", allocs)
+	}
+}
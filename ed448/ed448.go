@@ -0,0 +1,168 @@
+//go:build go1.24
+
+// Package ed448 implements Ed448 signature verification (EdDSA over
+// edwards448, RFC 8032) with precisely specified validation criteria, in the
+// spirit of ZIP215, suitable for consensus-critical contexts.
+//
+// A signature (R, s) of message M by public key A is valid if and only if
+//
+//   - A and R are canonical encodings of curve points, as required by the
+//     decoding procedure of RFC 8032, Section 5.2.3;
+//   - s is less than the group order l;
+//   - the cofactored equation [4][s]B = [4]R + [4][k]A holds, where
+//     k = SHAKE256(dom4(0, context) || R || A || M, 114) mod l.
+//
+// These are exactly the rules of RFC 8032, Section 5.2.7, which unlike for
+// Ed25519 mandates the cofactored equation. Individual and batch
+// verification therefore always agree. Small-order public keys are accepted.
+//
+// The field arithmetic is that of math/big and the hash is SHAKE256 from
+// crypto/sha3, so that the only arithmetic written here is the Edwards group
+// law, which is checked against the RFC 8032 test vectors, signing included.
+// It is much slower than the Ed25519 implementation, and not constant time,
+// which is fine for verification.
+//
+// The challenge is computed with SHAKE256 from crypto/sha3, so this package
+// requires Go 1.24.
+package ed448
+
+import (
+	"crypto/rand"
+	"crypto/sha3"
+	"math/big"
+)
+
+const (
+	// PublicKeySize is the size, in bytes, of public keys.
+	PublicKeySize = 57
+	// SignatureSize is the size, in bytes, of signatures.
+	SignatureSize = 114
+)
+
+// Verify reports whether sig is a valid Ed448 signature of message by
+// publicKey, with an empty context string.
+func Verify(publicKey, message, sig []byte) bool {
+	return VerifyWithContext(publicKey, message, sig, "")
+}
+
+// VerifyWithContext reports whether sig is a valid Ed448 signature of message
+// by publicKey under the given context string, of at most 255 bytes.
+func VerifyWithContext(publicKey, message, sig []byte, context string) bool {
+	e, ok := decodeEntry(publicKey, message, sig, context)
+	if !ok {
+		return false
+	}
+	// [s]B - [k]A - R
+	check := multiScalarMult([]*big.Int{e.s, e.k}, []*point{basepoint, negate(e.A)})
+	return clearsCofactor(add(check, negate(e.R)))
+}
+
+// entry is a decoded signature with its challenge.
+type entry struct {
+	A, R *point
+	s, k *big.Int
+}
+
+// decodeEntry decodes and checks the encodings of a signature and computes
+// its challenge.
+func decodeEntry(publicKey, message, sig []byte, context string) (*entry, bool) {
+	if len(publicKey) != PublicKeySize || len(sig) != SignatureSize || len(context) > 255 {
+		return nil, false
+	}
+	A, ok := decode(publicKey)
+	if !ok {
+		return nil, false
+	}
+	R, ok := decode(sig[:57])
+	if !ok {
+		return nil, false
+	}
+	s := scalarFromBytes(sig[57:])
+	if s.Cmp(l) >= 0 {
+		return nil, false
+	}
+	return &entry{A: A, R: R, s: s, k: challenge(sig[:57], publicKey, message, context)}, true
+}
+
+// dom4Prefix is the prefix of the RFC 8032 dom4 domain separator.
+const dom4Prefix = "SigEd448"
+
+// challenge returns SHAKE256(dom4(0, context) || R || A || M, 114) mod l.
+func challenge(R, A, message []byte, context string) *big.Int {
+	h := sha3.NewSHAKE256()
+	h.Write([]byte(dom4Prefix))
+	h.Write([]byte{0, byte(len(context))})
+	h.Write([]byte(context))
+	h.Write(R)
+	h.Write(A)
+	h.Write(message)
+	digest := make([]byte, 114)
+	h.Read(digest)
+	k := scalarFromBytes(digest)
+	return k.Mod(k, l)
+}
+
+// BatchVerifier accumulates Ed448 signatures with Add, before checking them
+// all at once with Verify, which accepts exactly when every signature is
+// valid according to Verify.
+type BatchVerifier struct {
+	entries []*entry
+	bad     bool
+}
+
+// NewBatchVerifier creates an empty BatchVerifier.
+func NewBatchVerifier() BatchVerifier {
+	return BatchVerifier{}
+}
+
+// Add adds a signature with an empty context string to the batch.
+func (v *BatchVerifier) Add(publicKey, message, sig []byte) {
+	v.AddWithContext(publicKey, message, sig, "")
+}
+
+// AddWithContext adds a signature under the given context string to the
+// batch. It retains no reference to its arguments.
+func (v *BatchVerifier) AddWithContext(publicKey, message, sig []byte, context string) {
+	e, ok := decodeEntry(publicKey, message, sig, context)
+	if !ok {
+		v.bad = true
+		return
+	}
+	v.entries = append(v.entries, e)
+}
+
+// Verify checks all entries in the batch, returning true if all are valid
+// and false otherwise, without identifying the invalid ones. Calling Verify
+// on an empty batch returns false.
+func (v *BatchVerifier) Verify() bool {
+	if v.bad || len(v.entries) == 0 {
+		return false
+	}
+
+	// The batch equation is
+	//
+	//	[4]([sum(z_i * s_i)]B - sum([z_i]R_i) - sum([z_i * k_i]A_i)) = 0
+	//
+	// for random 128-bit z_i.
+	n := len(v.entries)
+	scalars := make([]*big.Int, 0, 1+2*n)
+	points := make([]*point, 0, 1+2*n)
+	Bcoeff := new(big.Int)
+	buf := make([]byte, 16*n)
+	if _, err := rand.Read(buf); err != nil {
+		return false
+	}
+	for i, e := range v.entries {
+		z := new(big.Int).SetBytes(buf[16*i : 16*(i+1)])
+		if z.Sign() == 0 {
+			return false
+		}
+		Bcoeff.Add(Bcoeff, new(big.Int).Mul(z, e.s))
+		scalars = append(scalars, z, new(big.Int).Mod(new(big.Int).Mul(z, e.k), l))
+		points = append(points, negate(e.R), negate(e.A))
+	}
+	scalars = append(scalars, Bcoeff.Mod(Bcoeff, l))
+	points = append(points, basepoint)
+
+	return clearsCofactor(multiScalarMult(scalars, points))
+}
@@ -0,0 +1,192 @@
+//go:build go1.24
+
+package ed448
+
+import (
+	"bytes"
+	"crypto/sha3"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+// sign implements Ed448 signing from RFC 8032, Section 5.2.6, to produce
+// test signatures.
+func sign(seed, message []byte, context string) (publicKey, sig []byte) {
+	h := make([]byte, 114)
+	copy(h, sha3.SumSHAKE256(seed, 114))
+	h[0] &= 0xfc
+	h[55] |= 0x80
+	h[56] = 0
+	a := scalarFromBytes(h[:57])
+	publicKey = encode(scalarMult(a, basepoint))
+
+	rh := sha3.NewSHAKE256()
+	rh.Write([]byte(dom4Prefix))
+	rh.Write([]byte{0, byte(len(context))})
+	rh.Write([]byte(context))
+	rh.Write(h[57:])
+	rh.Write(message)
+	digest := make([]byte, 114)
+	rh.Read(digest)
+	r := scalarFromBytes(digest)
+	r.Mod(r, l)
+	R := encode(scalarMult(r, basepoint))
+
+	k := challenge(R, publicKey, message, context)
+	s := new(big.Int).Mul(k, a)
+	s.Add(s, r).Mod(s, l)
+	return publicKey, append(R, scalarToBytes(s)...)
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestCurveConstants(t *testing.T) {
+	// B is on the curve: x^2 + y^2 = 1 + d*x^2*y^2.
+	x, y := basepoint.X, basepoint.Y
+	xx, yy := fmul(x, x), fmul(y, y)
+	if fadd(xx, yy).Cmp(fadd(big.NewInt(1), fmul(d, fmul(xx, yy)))) != 0 {
+		t.Error("basepoint is not on the curve")
+	}
+	// B has order l.
+	if !equal(scalarMult(l, basepoint), identity()) {
+		t.Error("basepoint does not have order l")
+	}
+	if equal(basepoint, identity()) {
+		t.Error("basepoint is the identity")
+	}
+	q, ok := decode(encode(basepoint))
+	if !ok || !equal(q, basepoint) {
+		t.Error("basepoint encoding does not round-trip")
+	}
+}
+
+// RFC 8032, Section 7.4.
+var rfcVectors = []struct {
+	seed, publicKey, message, context, signature string
+}{
+	{
+		"6c82a562cb808d10d632be89c8513ebf6c929f34ddfa8c9f63c9960ef6e348a3528c8a3fcc2f044e39a3fc5b94492f8f032e7549a20098f95b",
+		"5fd7449b59b461fd2ce787ec616ad46a1da1342485a70e1f8a0ea75d80e96778edf124769b46c7061bd6783df1e50f6cd1fa1abeafe8256180",
+		"",
+		"",
+		"533a37f6bbe457251f023c0d88f976ae2dfb504a843e34d2074fd823d41a591f2b233f034f628281f2fd7a22ddd47d7828c59bd0a21bfd3980ff0d2028d4b18a9df63e006c5d1c2d345b925d8dc00b4104852db99ac5c7cdda8530a113a0f4dbb61149f05a7363268c71d95808ff2e652600",
+	},
+	{
+		"c4eab05d357007c632f3dbb48489924d552b08fe0c353a0d4a1f00acda2c463afbea67c5e8d2877c5e3bc397a659949ef8021e954e0a12274e",
+		"43ba28f430cdff456ae531545f7ecd0ac834a55d9358c0372bfa0c6c6798c0866aea01eb00742802b8438ea4cb82169c235160627b4c3a9480",
+		"03",
+		"",
+		"26b8f91727bd62897af15e41eb43c377efb9c610d48f2335cb0bd0087810f4352541b143c4b981b7e18f62de8ccdf633fc1bf037ab7cd779805e0dbcc0aae1cbcee1afb2e027df36bc04dcecbf154336c19f0af7e0a6472905e799f1953d2a0ff3348ab21aa4adafd1d234441cf807c03a00",
+	},
+}
+
+func TestRFC8032Vectors(t *testing.T) {
+	for i, v := range rfcVectors {
+		seed, pub, msg, sig := decodeHex(t, v.seed), decodeHex(t, v.publicKey), decodeHex(t, v.message), decodeHex(t, v.signature)
+		gotPub, gotSig := sign(seed, msg, v.context)
+		if !bytes.Equal(gotPub, pub) {
+			t.Errorf("vector %d: public key %x, want %x", i, gotPub, pub)
+		}
+		if !bytes.Equal(gotSig, sig) {
+			t.Errorf("vector %d: signature %x, want %x", i, gotSig, sig)
+		}
+		if !VerifyWithContext(pub, msg, sig, v.context) {
+			t.Errorf("vector %d: signature failed to verify", i)
+		}
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	seed := make([]byte, 57)
+	msg := []byte("consensus-grade Ed448")
+	pub, sig := sign(seed, msg, "")
+	if !Verify(pub, msg, sig) {
+		t.Fatal("signature failed to verify")
+	}
+	if Verify(pub, []byte("other"), sig) {
+		t.Error("signature verified for the wrong message")
+	}
+	if VerifyWithContext(pub, msg, sig, "ctx") {
+		t.Error("signature verified under another context")
+	}
+	_, ctxSig := sign(seed, msg, "ctx")
+	if !VerifyWithContext(pub, msg, ctxSig, "ctx") || Verify(pub, msg, ctxSig) {
+		t.Error("context signature verified incorrectly")
+	}
+
+	// s + l is rejected.
+	s := scalarFromBytes(sig[57:])
+	malleated := append(append([]byte(nil), sig[:57]...), scalarToBytes(s.Add(s, l))...)
+	if Verify(pub, msg, malleated) {
+		t.Error("signature with s + l verified")
+	}
+
+	// y = p is a non-canonical encoding of y = 0, a valid coordinate.
+	if _, ok := decode(make([]byte, 57)); !ok {
+		t.Error("y = 0 did not decode")
+	}
+	if _, ok := decode(scalarToBytes(p)); ok {
+		t.Error("y = p decoded")
+	}
+	// The identity (x = 0, y = 1) with the sign bit set is non-canonical.
+	negZero := make([]byte, 57)
+	negZero[0], negZero[56] = 1, 0x80
+	if _, ok := decode(negZero); ok {
+		t.Error("identity with the sign bit set decoded")
+	}
+	negZero[56] = 0
+	if q, ok := decode(negZero); !ok || !equal(q, identity()) {
+		t.Error("identity did not decode")
+	}
+
+	if Verify(pub[:56], msg, sig) || Verify(pub, msg, sig[:113]) {
+		t.Error("truncated inputs verified")
+	}
+	if VerifyWithContext(pub, msg, sig, string(make([]byte, 256))) {
+		t.Error("overlong context verified")
+	}
+}
+
+func TestBatchVerifier(t *testing.T) {
+	v := NewBatchVerifier()
+	if v.Verify() {
+		t.Error("empty batch verified")
+	}
+	for i := 0; i < 8; i++ {
+		seed := make([]byte, 57)
+		seed[0] = byte(i)
+		msg := []byte{byte(i)}
+		pub, sig := sign(seed, msg, "")
+		v.Add(pub, msg, sig)
+	}
+	if !v.Verify() {
+		t.Error("valid batch failed to verify")
+	}
+
+	pub, sig := sign(make([]byte, 57), []byte("signed"), "")
+	v.Add(pub, []byte("forged"), sig)
+	if v.Verify() {
+		t.Error("batch with a forged signature verified")
+	}
+
+	v = NewBatchVerifier()
+	v.Add(pub, []byte("signed"), sig)
+	v.Add(pub, nil, sig[:10])
+	if v.Verify() {
+		t.Error("batch with a malformed signature verified")
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	pub, sig := sign(make([]byte, 57), []byte("message"), "")
+	for i := 0; i < b.N; i++ {
+		Verify(pub, []byte("message"), sig)
+	}
+}
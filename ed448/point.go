@@ -0,0 +1,209 @@
+//go:build go1.24
+
+package ed448
+
+import "math/big"
+
+// The edwards448 curve x^2 + y^2 = 1 + d*x^2*y^2 over GF(p), from RFC 8032,
+// Section 5.2. Points are kept in projective coordinates (X : Y : Z), with
+// x = X/Z and y = Y/Z, and use the complete formulas of Section 5.2.4.
+//
+// All arithmetic uses math/big and is not constant time. This package only
+// handles public values.
+
+var (
+	// p = 2^448 - 2^224 - 1
+	p = new(big.Int).Sub(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 448), new(big.Int).Lsh(big.NewInt(1), 224)), big.NewInt(1))
+	// d = -39081
+	d = new(big.Int).Sub(p, big.NewInt(39081))
+	// l = 2^446 - 13818066809895115352007386748515426880336692474882178609894547503885
+	l = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 446), mustInt("13818066809895115352007386748515426880336692474882178609894547503885"))
+
+	// sqrtExp = (p + 1) / 4, since p = 3 mod 4.
+	sqrtExp = new(big.Int).Rsh(new(big.Int).Add(p, big.NewInt(1)), 2)
+
+	basepoint = &point{
+		X: mustInt("224580040295924300187604334099896036246789641632564134246125461686950415467406032909029192869357953282578032075146446173674602635247710"),
+		Y: mustInt("298819210078481492676017930443930673437544040154080242095928241372331506189835876003536878655418784733982303233503462500531545062832660"),
+		Z: big.NewInt(1),
+	}
+)
+
+func mustInt(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("ed448: invalid constant")
+	}
+	return n
+}
+
+// point is a projective point on edwards448.
+type point struct {
+	X, Y, Z *big.Int
+}
+
+func identity() *point {
+	return &point{big.NewInt(0), big.NewInt(1), big.NewInt(1)}
+}
+
+// fmul returns a * b mod p.
+func fmul(a, b *big.Int) *big.Int {
+	r := new(big.Int).Mul(a, b)
+	return r.Mod(r, p)
+}
+
+// fadd returns a + b mod p.
+func fadd(a, b *big.Int) *big.Int {
+	r := new(big.Int).Add(a, b)
+	return r.Mod(r, p)
+}
+
+// fsub returns a - b mod p.
+func fsub(a, b *big.Int) *big.Int {
+	r := new(big.Int).Sub(a, b)
+	return r.Mod(r, p)
+}
+
+// add returns q + r.
+func add(q, r *point) *point {
+	A := fmul(q.Z, r.Z)
+	B := fmul(A, A)
+	C := fmul(q.X, r.X)
+	D := fmul(q.Y, r.Y)
+	E := fmul(d, fmul(C, D))
+	F := fsub(B, E)
+	G := fadd(B, E)
+	H := fmul(fadd(q.X, q.Y), fadd(r.X, r.Y))
+	return &point{
+		X: fmul(A, fmul(F, fsub(fsub(H, C), D))),
+		Y: fmul(A, fmul(G, fsub(D, C))),
+		Z: fmul(F, G),
+	}
+}
+
+// double returns q + q.
+func double(q *point) *point {
+	s := fadd(q.X, q.Y)
+	B := fmul(s, s)
+	C := fmul(q.X, q.X)
+	D := fmul(q.Y, q.Y)
+	E := fadd(C, D)
+	H := fmul(q.Z, q.Z)
+	J := fsub(E, fadd(H, H))
+	return &point{
+		X: fmul(fsub(B, E), J),
+		Y: fmul(E, fsub(C, D)),
+		Z: fmul(E, J),
+	}
+}
+
+// negate returns -q.
+func negate(q *point) *point {
+	return &point{fsub(big.NewInt(0), q.X), q.Y, q.Z}
+}
+
+// equal reports whether q and r are the same point.
+func equal(q, r *point) bool {
+	return fmul(q.X, r.Z).Cmp(fmul(r.X, q.Z)) == 0 &&
+		fmul(q.Y, r.Z).Cmp(fmul(r.Y, q.Z)) == 0
+}
+
+// clearsCofactor reports whether [4]q is the identity, that is, whether q is
+// in the torsion subgroup of order 4.
+func clearsCofactor(q *point) bool {
+	return equal(double(double(q)), identity())
+}
+
+// multiScalarMult returns sum(scalars[i] * points[i]) for non-negative
+// scalars, sharing the doublings between terms.
+func multiScalarMult(scalars []*big.Int, points []*point) *point {
+	bits := 0
+	for _, s := range scalars {
+		if n := s.BitLen(); n > bits {
+			bits = n
+		}
+	}
+	acc := identity()
+	for i := bits - 1; i >= 0; i-- {
+		acc = double(acc)
+		for j, s := range scalars {
+			if s.Bit(i) == 1 {
+				acc = add(acc, points[j])
+			}
+		}
+	}
+	return acc
+}
+
+// scalarMult returns s * q.
+func scalarMult(s *big.Int, q *point) *point {
+	return multiScalarMult([]*big.Int{s}, []*point{q})
+}
+
+// encode returns the 57-byte encoding of q from RFC 8032, Section 5.2.2.
+func encode(q *point) []byte {
+	zInv := new(big.Int).ModInverse(q.Z, p)
+	x, y := fmul(q.X, zInv), fmul(q.Y, zInv)
+	b := make([]byte, 57)
+	y.FillBytes(b[1:])
+	reverse(b)
+	b[56] |= byte(x.Bit(0)) << 7
+	return b
+}
+
+// decode decodes a 57-byte point encoding as specified by RFC 8032, Section
+// 5.2.3, rejecting non-canonical encodings: y must be reduced, and the sign
+// bit must be clear when x is zero.
+func decode(b []byte) (*point, bool) {
+	if len(b) != 57 {
+		return nil, false
+	}
+	le := make([]byte, 57)
+	copy(le, b)
+	sign := le[56] >> 7
+	le[56] &= 0x7f
+	reverse(le)
+	y := new(big.Int).SetBytes(le)
+	if y.Cmp(p) >= 0 {
+		return nil, false
+	}
+
+	// x^2 = (y^2 - 1) / (d*y^2 - 1)
+	yy := fmul(y, y)
+	u := fsub(yy, big.NewInt(1))
+	v := fsub(fmul(d, yy), big.NewInt(1))
+	x := new(big.Int).Exp(fmul(u, new(big.Int).ModInverse(v, p)), sqrtExp, p)
+	if fmul(v, fmul(x, x)).Cmp(u) != 0 {
+		return nil, false
+	}
+	if x.Sign() == 0 && sign == 1 {
+		return nil, false
+	}
+	if x.Bit(0) != uint(sign) {
+		x.Sub(p, x)
+	}
+	return &point{x, y, big.NewInt(1)}, true
+}
+
+// reverse reverses b in place, converting between little- and big-endian.
+func reverse(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
+
+// scalarFromBytes interprets b as a little-endian integer.
+func scalarFromBytes(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	copy(be, b)
+	reverse(be)
+	return new(big.Int).SetBytes(be)
+}
+
+// scalarToBytes returns the 57-byte little-endian encoding of s < l.
+func scalarToBytes(s *big.Int) []byte {
+	b := make([]byte, 57)
+	s.FillBytes(b)
+	reverse(b)
+	return b
+}
@@ -0,0 +1,41 @@
+//go:build go1.23
+
+package ed25519consensus
+
+import "iter"
+
+// EntryView is an entry of a batch as yielded by BatchVerifier.All.
+type EntryView struct {
+	PublicKey PublicKey
+	Signature Signature
+
+	// Message is the message passed to Add if the batch retains it, as
+	// with deferred hashing or a failure callback, and nil otherwise. It
+	// must not be modified.
+	Message []byte
+
+	// Malformed is set for entries whose inputs were rejected by Add, whose
+	// public key and signature are zero.
+	Malformed bool
+}
+
+// All returns an iterator over the entries of the batch and their indices,
+// in order, for inspecting a batch without copying it as Entries does. The
+// batch must not be modified during the iteration.
+func (v *BatchVerifier) All() iter.Seq2[int, EntryView] {
+	return func(yield func(int, EntryView) bool) {
+		for i := range v.entries {
+			e := &v.entries[i]
+			ev := EntryView{Message: e.retained, Malformed: !e.good && e.reason == nil}
+			if ev.Message == nil {
+				ev.Message = e.message
+			}
+			if !ev.Malformed {
+				ev.PublicKey, ev.Signature = e.pubkey, e.signature
+			}
+			if !yield(i, ev) {
+				return
+			}
+		}
+	}
+}
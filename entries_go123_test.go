@@ -0,0 +1,48 @@
+//go:build go1.23
+
+package ed25519consensus
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestBatchAll(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("iterate")
+	sig := ed25519.Sign(priv, msg)
+
+	v := NewBatchVerifier()
+	v.SetDeferredHashing(1)
+	v.Add(pub, msg, sig)
+	v.Add(pub[:5], msg, sig)
+	v.Add(pub, msg, sig)
+
+	n := 0
+	for i, e := range v.All() {
+		if i != n {
+			t.Errorf("got index %d, want %d", i, n)
+		}
+		n++
+		if i == 1 {
+			if !e.Malformed || e.PublicKey != (PublicKey{}) {
+				t.Errorf("entry 1: got %+v, want a malformed entry", e)
+			}
+			continue
+		}
+		if e.Malformed || !bytes.Equal(e.PublicKey[:], pub) || !bytes.Equal(e.Signature[:], sig) || !bytes.Equal(e.Message, msg) {
+			t.Errorf("entry %d: got %+v", i, e)
+		}
+	}
+	if n != 3 {
+		t.Errorf("got %d entries, want 3", n)
+	}
+
+	for i := range v.All() {
+		if i > 0 {
+			t.Fatal("iteration continued after break")
+		}
+		break
+	}
+}
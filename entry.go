@@ -0,0 +1,387 @@
+package ed25519consensus
+
+import (
+	"encoding/binary"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// Entry is a batch entry in a self-contained form, with stable binary and
+// CBOR encodings, so that verification work can be shipped between processes
+// or recorded and replayed with BatchVerifier.AddEntry.
+//
+// An entry carries either the signed message, or the challenge digest
+// SHA-512(R || A || M) that Verify reduces to the challenge scalar. For
+// Ed25519ctx and Ed25519ph entries, the digest also covers the dom2 prefix,
+// so that every kind of entry can be represented by its digest.
+//
+// A Hashed entry is not bound to any message: whoever chooses the digest can
+// make a valid entry for any public key, by picking s and setting
+// R = [s]B - [k]A. Hashed entries must therefore only be accepted from a
+// trusted producer, such as the same process or an authenticated peer that
+// is trusted to have hashed the right message. Entries received from
+// untrusted sources must carry the message.
+type Entry struct {
+	PublicKey PublicKey
+	Signature Signature
+
+	// Message is the signed message, unless Hashed is set.
+	Message []byte
+
+	// Digest is the challenge digest, used instead of Message if Hashed is
+	// set. It is trusted as is; see above.
+	Digest [64]byte
+	Hashed bool
+
+	// Malformed marks an entry whose inputs were rejected by Add, such as
+	// a public key of the wrong length. It makes any batch fail.
+	Malformed bool
+}
+
+// AddEntry adds e to the current batch. An entry with a message is added as
+// by Add, and retains no reference to e.Message unless hashing is deferred.
+//
+// For a Hashed entry, the batch does not hash anything, so the caller is
+// responsible for e.Digest matching the message, as with AddWithChallenge.
+// Adding Hashed entries from an untrusted source lets it forge signatures.
+func (v *BatchVerifier) AddEntry(e Entry) {
+	switch {
+	case e.Malformed:
+		v.dropIfFull(v.add(nil, e.Message, nil, nil, false))
+	case e.Hashed:
+		if err := v.reserve(1, 0); err != nil {
+			v.dropIfFull(err)
+			return
+		}
+		v.entries = append(v.entries, v.hashedEntry(&e))
+	default:
+		v.dropIfFull(v.add(e.PublicKey[:], e.Message, e.Signature[:], nil, true))
+	}
+}
+
+// hashedEntry returns the batch entry for the Hashed entry e.
+func (v *BatchVerifier) hashedEntry(e *Entry) entry {
+	reason := v.checkPolicy(e.PublicKey[:], e.Signature[:], nil)
+	return entry{
+		good:         reason == nil,
+		pubkey:       e.PublicKey,
+		signature:    e.Signature,
+		digest:       e.Digest,
+		reason:       reason,
+		cofactorless: v.policy.Cofactorless,
+	}
+}
+
+// addAllMinPerWorker is the smallest number of entries AddAll hands to a
+// goroutine, below which starting it costs more than it saves.
+const addAllMinPerWorker = 64
+
+// AddAll adds entries to the batch as by AddEntry, checking and hashing them
+// in parallel on up to GOMAXPROCS goroutines, which speeds up building large
+// batches. Messages are retained as by AddEntry.
+//
+// Unlike the Add methods, AddAll is all or nothing with respect to the
+// limits set with SetLimits: if the entries would exceed them, it returns
+// ErrBatchFull without adding any. Otherwise it returns nil, even if some
+// entries are malformed, which makes Verify fail as with Add.
+//
+// If a cache is set with SetCache, entries are looked up and added one at a
+// time instead.
+func (v *BatchVerifier) AddAll(entries []Entry) error {
+	retained := 0
+	if v.retains() {
+		for i := range entries {
+			if !entries[i].Hashed {
+				retained += len(entries[i].Message)
+			}
+		}
+	}
+	if v.cache != nil {
+		// Entries known to the cache are skipped, so they are added in turn.
+		if !v.fits(len(entries), retained) {
+			return ErrBatchFull
+		}
+		for i := range entries {
+			v.AddEntry(entries[i])
+		}
+		return nil
+	}
+	if err := v.reserve(len(entries), retained); err != nil {
+		return err
+	}
+
+	base := len(v.entries)
+	v.Grow(len(entries))
+	v.entries = v.entries[:base+len(entries)]
+	added := v.entries[base:]
+
+	workers := runtime.GOMAXPROCS(0)
+	if n := len(entries) / addAllMinPerWorker; n < workers {
+		workers = n
+	}
+	if workers < 2 {
+		v.fillEntries(added, entries)
+	} else {
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			start, end := w*len(entries)/workers, (w+1)*len(entries)/workers
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				v.fillEntries(added[start:end], entries[start:end])
+			}()
+		}
+		wg.Wait()
+	}
+
+	// The failure callback and the alias checker are not safe for
+	// concurrent use, so messages are recorded afterwards.
+	for i := range entries {
+		if entries[i].Hashed {
+			continue
+		}
+		if v.onFailure != nil {
+			added[i].retained = entries[i].Message
+		}
+		if v.onFailure != nil || added[i].message != nil {
+			v.aliases.record(base+i, entries[i].Message)
+		}
+	}
+	return nil
+}
+
+// fillEntries sets each of dst to the batch entry for the corresponding
+// entry of src, overwriting any previous contents.
+func (v *BatchVerifier) fillEntries(dst []entry, src []Entry) {
+	for i := range src {
+		e, in := &dst[i], &src[i]
+		switch {
+		case in.Malformed:
+			*e = entry{}
+		case in.Hashed:
+			*e = v.hashedEntry(in)
+		default:
+			*e = entry{}
+			reason := v.checkPolicy(in.PublicKey[:], in.Signature[:], nil)
+			v.fillEntry(e, in.PublicKey[:], in.Message, in.Signature[:], nil, reason, nil)
+		}
+	}
+}
+
+// Entries returns the entries of the batch, in order. Since the batch keeps
+// only the challenge digest of each entry, the returned entries are Hashed,
+// or Malformed. Digests whose computation was deferred by
+// SetDeferredHashing are computed by Entries. With Go 1.23 and later, All
+// iterates over the entries without copying them or computing digests.
+func (v *BatchVerifier) Entries() []Entry {
+	entries := make([]Entry, len(v.entries))
+	for i := range v.entries {
+		e := &v.entries[i]
+		if !e.good {
+			entries[i].Malformed = true
+			continue
+		}
+		if e.message != nil {
+			e.computeDigest(e.message)
+			e.message = nil
+		}
+		entries[i] = Entry{
+			PublicKey: e.pubkey,
+			Signature: e.signature,
+			Digest:    e.digest,
+			Hashed:    true,
+		}
+	}
+	return entries
+}
+
+const (
+	entryVersion = 1
+
+	entryFlagHashed    = 1 << 0
+	entryFlagMalformed = 1 << 1
+)
+
+var errInvalidEntryEncoding = errors.New("ed25519consensus: invalid entry encoding")
+
+func (e *Entry) flags() byte {
+	var flags byte
+	if e.Hashed {
+		flags |= entryFlagHashed
+	}
+	if e.Malformed {
+		flags |= entryFlagMalformed
+	}
+	return flags
+}
+
+// payload returns the digest or the message, whichever e carries.
+func (e *Entry) payload() []byte {
+	if e.Hashed {
+		return e.Digest[:]
+	}
+	return e.Message
+}
+
+// setPayload sets the digest or the message from the payload of an entry
+// with the given flags.
+func (e *Entry) setPayload(flags byte, payload []byte) error {
+	if flags&^(entryFlagHashed|entryFlagMalformed) != 0 {
+		return errInvalidEntryEncoding
+	}
+	e.Hashed = flags&entryFlagHashed != 0
+	e.Malformed = flags&entryFlagMalformed != 0
+	e.Message, e.Digest = nil, [64]byte{}
+	if e.Hashed {
+		if len(payload) != len(e.Digest) {
+			return errInvalidEntryEncoding
+		}
+		copy(e.Digest[:], payload)
+		return nil
+	}
+	e.Message = append([]byte{}, payload...)
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is a
+// version byte (1), a flags byte, the public key, the signature, and either
+// the 64-byte digest or the message prefixed with its length as an unsigned
+// varint.
+func (e Entry) MarshalBinary() ([]byte, error) {
+	payload := e.payload()
+	b := make([]byte, 0, 2+len(e.PublicKey)+len(e.Signature)+binary.MaxVarintLen64+len(payload))
+	b = append(b, entryVersion, e.flags())
+	b = append(b, e.PublicKey[:]...)
+	b = append(b, e.Signature[:]...)
+	if !e.Hashed {
+		b = binary.AppendUvarint(b, uint64(len(payload)))
+	}
+	return append(b, payload...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, decoding the
+// encoding produced by MarshalBinary.
+func (e *Entry) UnmarshalBinary(b []byte) error {
+	const header = 2 + 32 + 64
+	if len(b) < header || b[0] != entryVersion {
+		return errInvalidEntryEncoding
+	}
+	flags := b[1]
+	copy(e.PublicKey[:], b[2:34])
+	copy(e.Signature[:], b[34:98])
+	payload := b[header:]
+	if flags&entryFlagHashed == 0 {
+		n, l := binary.Uvarint(payload)
+		if l <= 0 || uint64(len(payload)-l) != n {
+			return errInvalidEntryEncoding
+		}
+		payload = payload[l:]
+	}
+	return e.setPayload(flags, payload)
+}
+
+// MarshalCBOR encodes e as a CBOR array of four items: the flags as an
+// unsigned integer, then the public key, the signature and the digest or
+// message as byte strings. The encoding is deterministic, as defined by
+// RFC 8949, Section 4.2.1.
+func (e Entry) MarshalCBOR() ([]byte, error) {
+	payload := e.payload()
+	b := make([]byte, 0, 16+len(e.PublicKey)+len(e.Signature)+len(payload))
+	b = appendCBORHead(b, cborArray, 4)
+	b = appendCBORHead(b, cborUint, uint64(e.flags()))
+	b = appendCBORBytes(b, e.PublicKey[:])
+	b = appendCBORBytes(b, e.Signature[:])
+	return appendCBORBytes(b, payload), nil
+}
+
+// UnmarshalCBOR decodes the encoding produced by MarshalCBOR. It accepts only
+// that deterministic encoding.
+func (e *Entry) UnmarshalCBOR(b []byte) error {
+	n, b, ok := readCBORHead(b, cborArray)
+	if !ok || n != 4 {
+		return errInvalidEntryEncoding
+	}
+	flags, b, ok := readCBORHead(b, cborUint)
+	if !ok || flags > 0xff {
+		return errInvalidEntryEncoding
+	}
+	pub, b, ok := readCBORBytes(b)
+	if !ok || len(pub) != len(e.PublicKey) {
+		return errInvalidEntryEncoding
+	}
+	sig, b, ok := readCBORBytes(b)
+	if !ok || len(sig) != len(e.Signature) {
+		return errInvalidEntryEncoding
+	}
+	payload, b, ok := readCBORBytes(b)
+	if !ok || len(b) != 0 {
+		return errInvalidEntryEncoding
+	}
+	copy(e.PublicKey[:], pub)
+	copy(e.Signature[:], sig)
+	return e.setPayload(byte(flags), payload)
+}
+
+// CBOR major types used by Entry.
+const (
+	cborUint  = 0
+	cborBytes = 2
+	cborArray = 4
+)
+
+// appendCBORHead appends the shortest head for the given major type and
+// argument.
+func appendCBORHead(b []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= 0xff:
+		return append(b, major|24, byte(n))
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, major|27), n)
+	}
+}
+
+func appendCBORBytes(b, data []byte) []byte {
+	return append(appendCBORHead(b, cborBytes, uint64(len(data))), data...)
+}
+
+// readCBORHead reads a head of the given major type, rejecting heads that are
+// not in their shortest form.
+func readCBORHead(b []byte, major byte) (n uint64, rest []byte, ok bool) {
+	if len(b) == 0 || b[0]>>5 != major {
+		return 0, nil, false
+	}
+	info := b[0] & 31
+	b = b[1:]
+	var min uint64
+	switch {
+	case info < 24:
+		return uint64(info), b, true
+	case info == 24 && len(b) >= 1:
+		n, b, min = uint64(b[0]), b[1:], 24
+	case info == 25 && len(b) >= 2:
+		n, b, min = uint64(binary.BigEndian.Uint16(b)), b[2:], 0x100
+	case info == 26 && len(b) >= 4:
+		n, b, min = uint64(binary.BigEndian.Uint32(b)), b[4:], 0x10000
+	case info == 27 && len(b) >= 8:
+		n, b, min = binary.BigEndian.Uint64(b), b[8:], 0x100000000
+	default:
+		return 0, nil, false
+	}
+	return n, b, n >= min
+}
+
+func readCBORBytes(b []byte) (data, rest []byte, ok bool) {
+	n, b, ok := readCBORHead(b, cborBytes)
+	if !ok || n > uint64(len(b)) {
+		return nil, nil, false
+	}
+	return b[:n], b[n:], true
+}
@@ -0,0 +1,54 @@
+//go:build go1.20
+
+package ed25519consensus
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"testing"
+)
+
+// This test signs with ed25519.Options.Context, which was added in Go 1.20.
+
+func TestEntriesReplay(t *testing.T) {
+	for _, deferred := range []bool{false, true} {
+		v := NewBatchVerifier()
+		if deferred {
+			v.SetDeferredHashing(2)
+		}
+		for i := 0; i < 4; i++ {
+			pub, priv, _ := ed25519.GenerateKey(nil)
+			msg := []byte{byte(i)}
+			v.Add(pub, msg, ed25519.Sign(priv, msg))
+
+			ctxSig, _ := priv.Sign(nil, msg, &ed25519.Options{Context: "replay"})
+			v.AddWithContext(pub, msg, ctxSig, "replay")
+
+			digest := sha512.Sum512(msg)
+			phSig, _ := priv.Sign(nil, digest[:], &ed25519.Options{Hash: crypto.SHA512, Context: "replay"})
+			v.AddPH(pub, digest[:], phSig, "replay")
+		}
+
+		replay := NewBatchVerifier()
+		for _, e := range v.Entries() {
+			if !e.Hashed || e.Malformed {
+				t.Fatalf("unexpected entry %+v", e)
+			}
+			replay.AddEntry(e)
+		}
+		if !replay.Verify() {
+			t.Errorf("deferred=%v: replayed batch failed to verify", deferred)
+		}
+
+		v.Add(make([]byte, 31), nil, nil)
+		entries := v.Entries()
+		if !entries[len(entries)-1].Malformed {
+			t.Error("malformed entry not marked as such")
+		}
+		replay.AddEntry(entries[len(entries)-1])
+		if replay.Verify() {
+			t.Error("replayed batch with a malformed entry verified")
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package ed25519consensus
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestAddEntryMessage(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("entry")
+	e := Entry{Message: msg}
+	e.PublicKey, _ = NewPublicKey(pub)
+	e.Signature, _ = NewSignature(ed25519.Sign(priv, msg))
+
+	v := NewBatchVerifier()
+	v.AddEntry(e)
+	if !v.Verify() {
+		t.Error("entry with a message failed to verify")
+	}
+	e.Message = []byte("other")
+	v.AddEntry(e)
+	if v.Verify() {
+		t.Error("entry with the wrong message verified")
+	}
+}
+
+// testAddAllEntries returns n valid entries, some of them Hashed.
+func testAddAllEntries(t testing.TB, n int) []Entry {
+	t.Helper()
+	entries := make([]Entry, n)
+	for i := range entries {
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		msg := []byte{byte(i), byte(i >> 8)}
+		e := &entries[i]
+		e.PublicKey, _ = NewPublicKey(pub)
+		e.Signature, _ = NewSignature(ed25519.Sign(priv, msg))
+		e.Message = msg
+		if i%3 == 0 {
+			e.Hashed = true
+			h := sha512.New()
+			h.Write(e.Signature[:32])
+			h.Write(pub)
+			h.Write(msg)
+			h.Sum(e.Digest[:0])
+			e.Message = nil
+		}
+	}
+	return entries
+}
+
+func TestAddAll(t *testing.T) {
+	// Use several goroutines even on a single CPU.
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	entries := testAddAllEntries(t, 300)
+
+	for _, deferred := range []bool{false, true} {
+		v := NewBatchVerifier()
+		if deferred {
+			v.SetDeferredHashing(2)
+		}
+		v.Add(entries[1].PublicKey[:], entries[1].Message, entries[1].Signature[:])
+		if err := v.AddAll(entries); err != nil {
+			t.Fatal(err)
+		}
+		if len(v.entries) != 1+len(entries) {
+			t.Fatalf("got %d entries, want %d", len(v.entries), 1+len(entries))
+		}
+		if !v.Verify() {
+			t.Errorf("deferred %v: batch failed to verify", deferred)
+		}
+
+		seq := NewBatchVerifier()
+		for _, e := range entries {
+			seq.AddEntry(e)
+		}
+		if got, want := v.Entries()[1:], seq.Entries(); !reflect.DeepEqual(got, want) {
+			t.Errorf("deferred %v: AddAll and AddEntry disagree", deferred)
+		}
+	}
+
+	bad := append([]Entry(nil), entries...)
+	bad[200].Message = []byte("other")
+	bad[250] = Entry{Malformed: true}
+	var failed []int
+	v := NewBatchVerifier()
+	v.SetFailureCallback(func(i int, _ ed25519.PublicKey, _, _ []byte, _ error) {
+		failed = append(failed, i)
+	})
+	if err := v.AddAll(bad); err != nil {
+		t.Fatal(err)
+	}
+	if v.Verify() || !reflect.DeepEqual(failed, []int{200, 250}) {
+		t.Errorf("got failures %v, want [200 250]", failed)
+	}
+
+	// Limits apply to the entries as a whole.
+	v = NewBatchVerifier()
+	v.SetLimits(len(entries)-1, 0)
+	if err := v.AddAll(entries); err != ErrBatchFull || len(v.entries) != 0 || v.MemoryUsage() != 0 {
+		t.Errorf("over the limit: got %v, %d entries", err, len(v.entries))
+	}
+	if err := v.AddAll(entries[:10]); err != nil || !v.Verify() {
+		t.Errorf("within the limit: got %v", err)
+	}
+
+	v = NewBatchVerifier()
+	v.SetCache(NewVerificationCache(len(entries)))
+	if err := v.AddAll(entries); err != nil || !v.Verify() {
+		t.Errorf("with a cache: got %v", err)
+	}
+}
+
+func TestEntryEncoding(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	var e Entry
+	e.PublicKey, _ = NewPublicKey(pub)
+	e.Signature, _ = NewSignature(ed25519.Sign(priv, nil))
+	hashed := e
+	hashed.Hashed = true
+	hashed.Digest[0] = 1
+	withMessage := e
+	withMessage.Message = bytes.Repeat([]byte{7}, 300)
+
+	for _, e := range []Entry{e, hashed, withMessage, {Malformed: true, Message: []byte{}}} {
+		if e.Message == nil && !e.Hashed {
+			e.Message = []byte{}
+		}
+		b, _ := e.MarshalBinary()
+		var got Entry
+		if err := got.UnmarshalBinary(b); err != nil || !reflect.DeepEqual(got, e) {
+			t.Errorf("binary round trip: got %+v, %v, want %+v", got, err, e)
+		}
+		if err := got.UnmarshalBinary(b[:len(b)-1]); err == nil {
+			t.Error("truncated binary encoding accepted")
+		}
+
+		c, _ := e.MarshalCBOR()
+		got = Entry{}
+		if err := got.UnmarshalCBOR(c); err != nil || !reflect.DeepEqual(got, e) {
+			t.Errorf("CBOR round trip: got %+v, %v, want %+v", got, err, e)
+		}
+		if err := got.UnmarshalCBOR(append(c, 0)); err == nil {
+			t.Error("CBOR encoding with trailing data accepted")
+		}
+	}
+
+	// The CBOR encoding of an entry with an empty message starts with the
+	// array head, the flags, and the head of a 32-byte byte string.
+	c, _ := e.MarshalCBOR()
+	if !bytes.HasPrefix(c, []byte{0x84, 0x00, 0x58, 0x20}) || c[len(c)-1] != 0x40 {
+		t.Errorf("unexpected CBOR encoding %x", c)
+	}
+	// A non-shortest head for the flags is rejected.
+	if err := new(Entry).UnmarshalCBOR(append([]byte{0x84, 0x18, 0x00}, c[2:]...)); err == nil {
+		t.Error("non-deterministic CBOR encoding accepted")
+	}
+	// Unknown flags are rejected.
+	if err := new(Entry).UnmarshalCBOR(append([]byte{0x84, 0x04}, c[2:]...)); err == nil {
+		t.Error("unknown flags accepted")
+	}
+}
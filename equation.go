@@ -0,0 +1,97 @@
+package ed25519consensus
+
+import (
+	"errors"
+
+	"filippo.io/edwards25519"
+)
+
+// BatchEquation is the randomized batch verification equation of a batch,
+// as computed by Verify before the multiscalar multiplication. The batch is
+// valid if and only if [8]sum([Scalars[i]]Points[i]) is the identity.
+//
+// Points[0] is the basepoint, followed by the R and then the A values of
+// the entries, with identical entries merged into a single pair of terms.
+type BatchEquation struct {
+	// Scalars holds the canonical encodings of the coefficients.
+	Scalars [][32]byte
+	// Points holds the canonical encodings of the points, even where the
+	// entries used non-canonical encodings, which decode to the same points.
+	Points [][32]byte
+}
+
+var (
+	errEmptyBatch          = errors.New("ed25519consensus: empty batch")
+	errCofactorlessBatch   = errors.New("ed25519consensus: cofactorless batches have no batch equation")
+	errBatchRandomizerRead = errors.New("ed25519consensus: could not randomize batch equation")
+)
+
+// ExportEquation returns the batch equation over all entries, so that an
+// auditor or another implementation can check the batch independently, for
+// example with BatchEquation.Check. The equation spans the whole batch,
+// regardless of SetChunkSize.
+//
+// Unless SetDeterministic is on, every call draws new coefficients, so the
+// equation differs from the one checked by Verify, while being as sound.
+//
+// ExportEquation returns an error if the batch is empty, has a malformed
+// entry, or uses the cofactorless equation (see Policy.Cofactorless).
+func (v *BatchVerifier) ExportEquation() (*BatchEquation, error) {
+	if v.overflowed {
+		return nil, ErrBatchFull
+	}
+	if len(v.entries) == 0 {
+		return nil, errEmptyBatch
+	}
+	if v.policy.Cofactorless {
+		return nil, errCofactorlessBatch
+	}
+	v.aliases.check()
+	for i := range v.entries {
+		if e := &v.entries[i]; !e.good {
+			return nil, e.rejection()
+		}
+	}
+
+	scalars, points, ok := v.batchEquation(v.entries)
+	if !ok {
+		for i := range v.entries {
+			if err := v.entries[i].check(); err == ErrInvalidKeyEncoding || err == ErrMalformedSignature {
+				return nil, err
+			}
+		}
+		return nil, errBatchRandomizerRead
+	}
+	eq := &BatchEquation{
+		Scalars: make([][32]byte, len(scalars)),
+		Points:  make([][32]byte, len(points)),
+	}
+	for i := range scalars {
+		copy(eq.Scalars[i][:], scalars[i].Bytes())
+		copy(eq.Points[i][:], points[i].Bytes())
+	}
+	return eq, nil
+}
+
+// Check reports whether the equation holds, computing it with the
+// multiscalar multiplication of filippo.io/edwards25519 rather than the
+// backend used by Verify.
+func (eq *BatchEquation) Check() bool {
+	if len(eq.Scalars) != len(eq.Points) || len(eq.Scalars) == 0 {
+		return false
+	}
+	scalars := make([]*edwards25519.Scalar, len(eq.Scalars))
+	points := make([]*edwards25519.Point, len(eq.Points))
+	for i := range scalars {
+		var err error
+		if scalars[i], err = new(edwards25519.Scalar).SetCanonicalBytes(eq.Scalars[i][:]); err != nil {
+			return false
+		}
+		if points[i], err = new(edwards25519.Point).SetBytes(eq.Points[i][:]); err != nil {
+			return false
+		}
+	}
+	check := new(edwards25519.Point).VarTimeMultiScalarMult(scalars, points)
+	check.MultByCofactor(check)
+	return check.Equal(edwards25519.NewIdentityPoint()) == 1
+}
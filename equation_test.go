@@ -0,0 +1,54 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestExportEquation(t *testing.T) {
+	v := NewBatchVerifier()
+	if _, err := v.ExportEquation(); err == nil {
+		t.Error("empty batch: no error")
+	}
+
+	populateBatchVerifier(t, &v)
+	eq, err := v.ExportEquation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := 1 + 2*len(v.entries); len(eq.Scalars) != n || len(eq.Points) != n {
+		t.Errorf("got %d scalars and %d points, want %d", len(eq.Scalars), len(eq.Points), n)
+	}
+	if !eq.Check() {
+		t.Error("equation of a valid batch does not hold")
+	}
+
+	// Deterministic coefficients make the exported equation reproducible.
+	v.SetDeterministic(true)
+	eq1, _ := v.ExportEquation()
+	eq2, _ := v.ExportEquation()
+	if eq1.Scalars[0] != eq2.Scalars[0] {
+		t.Error("deterministic equation changed between calls")
+	}
+
+	v.entries[4].signature[40] ^= 1
+	if eq, err := v.ExportEquation(); err != nil || eq.Check() {
+		t.Errorf("invalid signature: got error %v, or equation holds", err)
+	}
+	v.entries[4].signature[63] |= 0xf0
+	if _, err := v.ExportEquation(); err != ErrMalformedSignature {
+		t.Errorf("malformed signature: got %v", err)
+	}
+
+	pub, _, _ := ed25519.GenerateKey(nil)
+	v.Add(pub, []byte("message"), nil)
+	if _, err := v.ExportEquation(); err != ErrMalformedEntry {
+		t.Errorf("malformed entry: got %v", err)
+	}
+
+	// Tampering with the exported equation breaks it.
+	eq.Scalars[0][0] ^= 1
+	if eq.Check() {
+		t.Error("tampered equation holds")
+	}
+}
@@ -0,0 +1,128 @@
+package ed25519consensus
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+)
+
+// ConflictFunc reports whether two different messages signed by the same key
+// conflict under the rules of a consensus protocol, for example because they
+// are votes for different blocks at the same height and round. It must be
+// symmetric.
+type ConflictFunc func(message1, message2 []byte) bool
+
+// Evidence proves that the holder of a key signed two conflicting messages,
+// such as equivocating votes. It is self-contained: anyone knowing the
+// conflict rules can check it with VerifyEvidence.
+//
+// The messages are ordered so that Message1 sorts before Message2, which
+// makes the evidence of a given equivocation unique.
+type Evidence struct {
+	PublicKey  PublicKey
+	Message1   []byte
+	Signature1 Signature
+	Message2   []byte
+	Signature2 Signature
+}
+
+var (
+	// ErrNoConflict means that the messages of evidence are identical or
+	// do not conflict.
+	ErrNoConflict = errors.New("ed25519consensus: messages do not conflict")
+
+	errInvalidEvidenceEncoding = errors.New("ed25519consensus: invalid evidence encoding")
+)
+
+// evidenceVersion is the first byte of the binary encoding of Evidence.
+const evidenceVersion = 1
+
+// NewEvidence returns the evidence that publicKey signed the conflicting
+// messages message1 and message2. It returns an error if either signature
+// is invalid, with the rules of Verify, or if the messages are identical or
+// do not conflict. The evidence does not retain the arguments.
+func NewEvidence(publicKey ed25519.PublicKey, message1, sig1, message2, sig2 []byte, conflict ConflictFunc) (*Evidence, error) {
+	pk, err := NewPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	s1, err := NewSignature(sig1)
+	if err != nil {
+		return nil, err
+	}
+	s2, err := NewSignature(sig2)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Compare(message1, message2) > 0 {
+		message1, message2 = message2, message1
+		s1, s2 = s2, s1
+	}
+	e := &Evidence{
+		PublicKey:  pk,
+		Message1:   append([]byte{}, message1...),
+		Signature1: s1,
+		Message2:   append([]byte{}, message2...),
+		Signature2: s2,
+	}
+	if err := VerifyEvidence(e, conflict); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// VerifyEvidence checks that both signatures of e are valid, with the rules
+// of Verify, and that its messages are in order and conflict. It returns
+// ErrInvalidSignature, ErrNoConflict, or nil if e proves an equivocation.
+func VerifyEvidence(e *Evidence, conflict ConflictFunc) error {
+	if bytes.Compare(e.Message1, e.Message2) >= 0 || !conflict(e.Message1, e.Message2) {
+		return ErrNoConflict
+	}
+	if !e.PublicKey.Verify(e.Message1, &e.Signature1) || !e.PublicKey.Verify(e.Message2, &e.Signature2) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is a
+// version byte (1), the public key, both signatures, and both messages,
+// each prefixed with its length as an unsigned varint.
+func (e Evidence) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 1+len(e.PublicKey)+2*len(e.Signature1)+2*binary.MaxVarintLen64+len(e.Message1)+len(e.Message2))
+	b = append(b, evidenceVersion)
+	b = append(b, e.PublicKey[:]...)
+	b = append(b, e.Signature1[:]...)
+	b = append(b, e.Signature2[:]...)
+	b = binary.AppendUvarint(b, uint64(len(e.Message1)))
+	b = append(b, e.Message1...)
+	b = binary.AppendUvarint(b, uint64(len(e.Message2)))
+	return append(b, e.Message2...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, decoding the
+// encoding produced by MarshalBinary. It does not verify the evidence.
+func (e *Evidence) UnmarshalBinary(b []byte) error {
+	const header = 1 + 32 + 64 + 64
+	if len(b) < header || b[0] != evidenceVersion {
+		return errInvalidEvidenceEncoding
+	}
+	copy(e.PublicKey[:], b[1:33])
+	copy(e.Signature1[:], b[33:97])
+	copy(e.Signature2[:], b[97:161])
+	b = b[header:]
+	var msgs [2][]byte
+	for i := range msgs {
+		n, l := binary.Uvarint(b)
+		if l <= 0 || uint64(len(b)-l) < n {
+			return errInvalidEvidenceEncoding
+		}
+		msgs[i] = append([]byte{}, b[l:l+int(n)]...)
+		b = b[l+int(n):]
+	}
+	if len(b) != 0 {
+		return errInvalidEvidenceEncoding
+	}
+	e.Message1, e.Message2 = msgs[0], msgs[1]
+	return nil
+}
@@ -0,0 +1,68 @@
+package ed25519consensus
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"reflect"
+	"testing"
+)
+
+// sameHeight is a ConflictFunc for votes whose first byte is the height.
+func sameHeight(m1, m2 []byte) bool {
+	return len(m1) > 0 && len(m2) > 0 && m1[0] == m2[0]
+}
+
+func TestEvidence(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	vote1, vote2, later := []byte{7, 'a'}, []byte{7, 'b'}, []byte{8, 'a'}
+	sig1, sig2 := ed25519.Sign(priv, vote1), ed25519.Sign(priv, vote2)
+
+	e, err := NewEvidence(pub, vote2, sig2, vote1, sig1, sameHeight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(e.Message1, vote1) || !bytes.Equal(e.Signature1[:], sig1) {
+		t.Error("messages not ordered")
+	}
+	if err := VerifyEvidence(e, sameHeight); err != nil {
+		t.Error(err)
+	}
+	if e2, _ := NewEvidence(pub, vote1, sig1, vote2, sig2, sameHeight); !reflect.DeepEqual(e, e2) {
+		t.Error("evidence depends on the order of the messages")
+	}
+
+	if _, err := NewEvidence(pub, vote1, sig1, vote1, sig1, sameHeight); err != ErrNoConflict {
+		t.Errorf("identical messages: got %v", err)
+	}
+	if _, err := NewEvidence(pub, vote1, sig1, later, ed25519.Sign(priv, later), sameHeight); err != ErrNoConflict {
+		t.Errorf("non-conflicting messages: got %v", err)
+	}
+	if _, err := NewEvidence(pub, vote1, sig2, vote2, sig2, sameHeight); err != ErrInvalidSignature {
+		t.Errorf("invalid signature: got %v", err)
+	}
+	if _, err := NewEvidence(pub[:31], vote1, sig1, vote2, sig2, sameHeight); err != ErrInvalidKeyLength {
+		t.Errorf("short key: got %v", err)
+	}
+
+	b, _ := e.MarshalBinary()
+	var got Evidence
+	if err := got.UnmarshalBinary(b); err != nil || !reflect.DeepEqual(&got, e) {
+		t.Errorf("binary round trip: got %+v, %v", got, err)
+	}
+	for _, bad := range [][]byte{b[:len(b)-1], append(b, 0), b[:100]} {
+		if err := got.UnmarshalBinary(bad); err == nil {
+			t.Errorf("invalid encoding %x accepted", bad)
+		}
+	}
+
+	got = *e
+	got.Message1, got.Message2 = got.Message2, got.Message1
+	if err := VerifyEvidence(&got, sameHeight); err != ErrNoConflict {
+		t.Errorf("unordered evidence: got %v", err)
+	}
+	got = *e
+	got.Signature2[0] ^= 1
+	if err := VerifyEvidence(&got, sameHeight); err != ErrInvalidSignature {
+		t.Errorf("tampered evidence: got %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"runtime"
+
+	"filippo.io/edwards25519"
+)
+
+// ExpandedPrivateKey is an Ed25519 private key with its secret scalar and
+// nonce prefix derived once, so that signing many messages with the same
+// key does not hash the seed and clamp the scalar each time.
+type ExpandedPrivateKey struct {
+	s      edwards25519.Scalar
+	prefix [32]byte
+	public [32]byte
+}
+
+// NewExpandedPrivateKey expands priv as specified by RFC 8032, Section 5.1.5.
+// It panics if priv does not have the length of an ed25519.PrivateKey.
+func NewExpandedPrivateKey(priv ed25519.PrivateKey) *ExpandedPrivateKey {
+	if len(priv) != ed25519.PrivateKeySize {
+		panic("ed25519consensus: bad private key length")
+	}
+	digest := sha512.Sum512(priv[:32])
+	k := new(ExpandedPrivateKey)
+	k.s.SetBytesWithClamping(digest[:32])
+	copy(k.prefix[:], digest[32:])
+	Zeroize(digest[:])
+	copy(k.public[:], new(edwards25519.Point).ScalarBaseMult(&k.s).Bytes())
+	return k
+}
+
+// Public returns the public key corresponding to k.
+func (k *ExpandedPrivateKey) Public() ed25519.PublicKey {
+	return append(ed25519.PublicKey(nil), k.public[:]...)
+}
+
+// Sign returns the Ed25519 signature of message by k, which is identical to
+// the one returned by ed25519.Sign.
+func (k *ExpandedPrivateKey) Sign(message []byte) []byte {
+	return k.AppendSign(make([]byte, 0, ed25519.SignatureSize), message)
+}
+
+// SignTo writes the signature of message by k to the first
+// ed25519.SignatureSize bytes of dst, without allocating. It panics if dst
+// is too short.
+func (k *ExpandedPrivateKey) SignTo(dst, message []byte) {
+	if len(dst) < ed25519.SignatureSize {
+		panic("ed25519consensus: signature buffer too short")
+	}
+	appendSignExpanded(dst[:0], &k.s, k.prefix[:], nil, k.public[:], message)
+}
+
+// AppendSign appends the signature of message by k to dst and returns the
+// extended slice. It does not allocate if dst has enough capacity.
+func (k *ExpandedPrivateKey) AppendSign(dst, message []byte) []byte {
+	return appendSignExpanded(dst, &k.s, k.prefix[:], nil, k.public[:], message)
+}
+
+// SignTo writes the signature of message by priv to the first
+// ed25519.SignatureSize bytes of dst, without allocating. It panics if priv
+// does not have the length of an ed25519.PrivateKey or if dst is too short.
+//
+// Like ed25519.Sign, it expands priv for every signature; high-rate signers
+// should use an ExpandedPrivateKey.
+func SignTo(dst []byte, priv ed25519.PrivateKey, message []byte) {
+	if len(dst) < ed25519.SignatureSize {
+		panic("ed25519consensus: signature buffer too short")
+	}
+	AppendSign(dst[:0], priv, message)
+}
+
+// AppendSign appends the signature of message by priv to dst and returns the
+// extended slice, which is identical to dst followed by the result of
+// ed25519.Sign. It does not allocate if dst has enough capacity. It panics
+// if priv does not have the length of an ed25519.PrivateKey.
+func AppendSign(dst []byte, priv ed25519.PrivateKey, message []byte) []byte {
+	if len(priv) != ed25519.PrivateKeySize {
+		panic("ed25519consensus: bad private key length")
+	}
+	return appendSignPrivate(dst, priv, nil, message)
+}
+
+// appendSignPrivate is AppendSign with the given nonce randomness, clearing
+// the expansion of priv afterwards. priv must have the right length.
+func appendSignPrivate(dst []byte, priv ed25519.PrivateKey, noise, message []byte) []byte {
+	digest := sha512.Sum512(priv[:32])
+	var s edwards25519.Scalar
+	s.SetBytesWithClamping(digest[:32])
+	dst = appendSignExpanded(dst, &s, digest[32:], noise, priv[32:], message)
+	Zeroize(digest[:])
+	s = edwards25519.Scalar{}
+	return dst
+}
+
+// SignAndWipe returns the signature of message by priv, like ed25519.Sign,
+// and then overwrites priv with zeroes, for keys used only once, such as
+// ephemeral or one-time signing keys. Like AppendSign, it also clears the
+// expansion of priv it computes. It panics if priv does not have the length
+// of an ed25519.PrivateKey.
+//
+// Clearing is best effort: copies made by the Go runtime, such as when
+// growing stacks, and the internal state of crypto/sha512 are out of reach.
+func SignAndWipe(priv ed25519.PrivateKey, message []byte) []byte {
+	sig := AppendSign(make([]byte, 0, ed25519.SignatureSize), priv, message)
+	Zeroize(priv)
+	return sig
+}
+
+// Zeroize overwrites k with zeroes, so that its secret scalar and nonce
+// prefix do not linger in memory once it is no longer needed. k must not be
+// used afterwards. See SignAndWipe for the limits of clearing.
+func (k *ExpandedPrivateKey) Zeroize() {
+	*k = ExpandedPrivateKey{}
+}
+
+// Zeroize overwrites b with zeroes, for clearing secret material such as an
+// ed25519.PrivateKey or a seed once it is no longer needed. See SignAndWipe
+// for the limits of clearing.
+func Zeroize(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+	runtime.KeepAlive(b)
+}
+
+// appendSignExpanded appends an Ed25519 signature computed from an expanded
+// private key: the secret scalar s, the nonce prefix and the encoded public
+// key. If noise is not empty, the nonce is hashed from the noise, padded
+// with zeros to a full SHA-512 block, before the prefix and the message, as
+// in draft-irtf-cfrg-det-sigs-with-noise; otherwise the signature is the
+// RFC 8032 one. The padding keeps any noise from being confused with the
+// start of a message: with the noise after the prefix, a hedged signature
+// of M would share its nonce with the deterministic signature of noise||M.
+func appendSignExpanded(dst []byte, s *edwards25519.Scalar, prefix, noise, public, message []byte) []byte {
+	var digest [64]byte
+	h := sha512.New()
+	if len(noise) > 0 {
+		var pad [sha512.BlockSize]byte
+		h.Write(noise)
+		h.Write(pad[:sha512.BlockSize-len(noise)%sha512.BlockSize])
+	}
+	h.Write(prefix)
+	h.Write(message)
+	h.Sum(digest[:0])
+	r, _ := new(edwards25519.Scalar).SetUniformBytes(digest[:])
+	R := new(edwards25519.Point).ScalarBaseMult(r).Bytes()
+
+	h.Reset()
+	h.Write(R)
+	h.Write(public)
+	h.Write(message)
+	h.Sum(digest[:0])
+	k, _ := new(edwards25519.Scalar).SetUniformBytes(digest[:])
+
+	S := new(edwards25519.Scalar).MultiplyAdd(k, s, r)
+	dst = append(dst, R...)
+	dst = append(dst, S.Bytes()...)
+
+	// Knowing the nonce r of a signature reveals s.
+	*r = edwards25519.Scalar{}
+	return dst
+}
@@ -0,0 +1,92 @@
+package ed25519consensus
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestExpandedPrivateKey(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	k := NewExpandedPrivateKey(priv)
+	if !bytes.Equal(k.Public(), pub) {
+		t.Errorf("Public() = %x, want %x", k.Public(), pub)
+	}
+	for _, msg := range [][]byte{nil, []byte("vote"), bytes.Repeat([]byte("x"), 1000)} {
+		if got, want := k.Sign(msg), ed25519.Sign(priv, msg); !bytes.Equal(got, want) {
+			t.Errorf("Sign(%q) = %x, want %x", msg, got, want)
+		}
+	}
+}
+
+func TestAppendSign(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	k := NewExpandedPrivateKey(priv)
+	msg := []byte("vote extension")
+	want := ed25519.Sign(priv, msg)
+
+	prefix := []byte("prefix")
+	if got := AppendSign(prefix, priv, msg); !bytes.Equal(got[:6], prefix) || !bytes.Equal(got[6:], want) {
+		t.Errorf("AppendSign = %x, want prefix followed by %x", got, want)
+	}
+	if got := k.AppendSign(nil, msg); !bytes.Equal(got, want) {
+		t.Errorf("ExpandedPrivateKey.AppendSign = %x, want %x", got, want)
+	}
+
+	dst := make([]byte, 70)
+	SignTo(dst, priv, msg)
+	if !bytes.Equal(dst[:64], want) {
+		t.Errorf("SignTo wrote %x, want %x", dst[:64], want)
+	}
+	dst = make([]byte, 64)
+	k.SignTo(dst, msg)
+	if !bytes.Equal(dst, want) {
+		t.Errorf("ExpandedPrivateKey.SignTo wrote %x, want %x", dst, want)
+	}
+
+	if allocs := testing.AllocsPerRun(10, func() { SignTo(dst, priv, msg) }); allocs > 0 {
+		t.Errorf("SignTo allocated %v times", allocs)
+	}
+	if allocs := testing.AllocsPerRun(10, func() { k.AppendSign(dst[:0], msg) }); allocs > 0 {
+		t.Errorf("ExpandedPrivateKey.AppendSign allocated %v times", allocs)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("SignTo did not panic on a short buffer")
+		}
+	}()
+	SignTo(dst[:63], priv, msg)
+}
+
+func TestZeroize(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("one-time")
+	want := ed25519.Sign(priv, msg)
+
+	k := NewExpandedPrivateKey(priv)
+	k.Zeroize()
+	if *k != (ExpandedPrivateKey{}) {
+		t.Error("ExpandedPrivateKey.Zeroize left data behind")
+	}
+
+	if got := SignAndWipe(priv, msg); !bytes.Equal(got, want) {
+		t.Errorf("SignAndWipe = %x, want %x", got, want)
+	}
+	if !bytes.Equal(priv, make([]byte, ed25519.PrivateKeySize)) {
+		t.Error("SignAndWipe did not wipe the private key")
+	}
+	if !Verify(pub, msg, want) {
+		t.Error("signature failed to verify")
+	}
+}
+
+func BenchmarkExpandedSign(b *testing.B) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	k := NewExpandedPrivateKey(priv)
+	msg := []byte("vote")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		k.Sign(msg)
+	}
+}
@@ -0,0 +1,64 @@
+package ed25519consensus
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// KeyFingerprint is the SHA-256 hash of a public key encoding, for logging
+// and comparing keys. Since it hashes the encoding, the non-canonical
+// encodings of a point, which Verify accepts, have different fingerprints.
+type KeyFingerprint [sha256.Size]byte
+
+// Fingerprint returns the fingerprint of pk.
+func Fingerprint(pk PublicKey) KeyFingerprint {
+	return sha256.Sum256(pk[:])
+}
+
+// String returns the hexadecimal encoding of f.
+func (f KeyFingerprint) String() string {
+	return hex.EncodeToString(f[:])
+}
+
+// Short returns the first eight bytes of f in hexadecimal groups of two
+// bytes, such as "3f2a:91c0:5e7d:0b44", for display to humans. It is not
+// collision resistant, and must not be used to identify keys.
+func (f KeyFingerprint) Short() string {
+	b := make([]byte, 0, 19)
+	for i := 0; i < 8; i += 2 {
+		if i > 0 {
+			b = append(b, ':')
+		}
+		b = append(b, hex.EncodeToString(f[i:i+2])...)
+	}
+	return string(b)
+}
+
+// AddressScheme derives the address of an account or validator from its
+// public key, as defined by a chain.
+type AddressScheme func(pk PublicKey) []byte
+
+// TruncatedSHA256 returns the scheme deriving addresses as the first n
+// bytes of the SHA-256 hash of the key encoding. With n = 20, it is the
+// address of Ed25519 validator keys in CometBFT. It panics if n is not
+// between 1 and 32.
+func TruncatedSHA256(n int) AddressScheme {
+	if n < 1 || n > sha256.Size {
+		panic("ed25519consensus: invalid address length")
+	}
+	return func(pk PublicKey) []byte {
+		h := sha256.Sum256(pk[:])
+		return h[:n]
+	}
+}
+
+// RawKeyAddress is the scheme whose addresses are the key encodings
+// themselves, as on chains that identify accounts by their public keys.
+func RawKeyAddress(pk PublicKey) []byte {
+	return append([]byte(nil), pk[:]...)
+}
+
+// Address returns the address of pk under scheme.
+func (pk PublicKey) Address(scheme AddressScheme) []byte {
+	return scheme(pk)
+}
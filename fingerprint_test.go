@@ -0,0 +1,46 @@
+package ed25519consensus
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	var pk PublicKey
+	pk[0] = 1
+	f := Fingerprint(pk)
+	if want := sha256.Sum256(pk[:]); f != want {
+		t.Errorf("Fingerprint = %x, want %x", f, want)
+	}
+	if got := f.String(); len(got) != 64 || got[:16] != f.Short()[0:4]+f.Short()[5:9]+f.Short()[10:14]+f.Short()[15:19] {
+		t.Errorf("String = %q, Short = %q", got, f.Short())
+	}
+	if s := f.Short(); len(s) != 19 || s[4] != ':' || s[9] != ':' || s[14] != ':' {
+		t.Errorf("Short = %q", s)
+	}
+
+	// A non-canonical encoding of the identity has another fingerprint.
+	nonCanonical := NonCanonicalSmallOrderEncodings()
+	if Fingerprint(PublicKey(nonCanonical[0])) == Fingerprint(PublicKey(SmallOrderEncodings()[0])) {
+		t.Error("encodings of the same point share a fingerprint")
+	}
+}
+
+func TestAddress(t *testing.T) {
+	var pk PublicKey
+	pk[31] = 7
+	h := sha256.Sum256(pk[:])
+	if got := pk.Address(TruncatedSHA256(20)); !bytes.Equal(got, h[:20]) {
+		t.Errorf("TruncatedSHA256(20) address = %x, want %x", got, h[:20])
+	}
+	if got := pk.Address(RawKeyAddress); !bytes.Equal(got, pk[:]) {
+		t.Errorf("RawKeyAddress address = %x", got)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("TruncatedSHA256(33) did not panic")
+		}
+	}()
+	TruncatedSHA256(33)
+}
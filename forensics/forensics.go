@@ -0,0 +1,73 @@
+// Package forensics helps analyze Ed25519 signatures produced by broken or
+// compromised signers.
+//
+// Ed25519 derives the nonce r of a signature from the private key and the
+// message, so a correct signer never uses the same R for two different
+// messages. A signer that does, because of a faulty implementation, a
+// hardware fault or a deliberate backdoor, reveals its secret scalar a, as
+//
+//	s1 = r + k1*a,  s2 = r + k2*a  =>  a = (s1 - s2) / (k1 - k2).
+//
+// Incident responders can use RecoverScalarFromNonceReuse to confirm that a
+// key leaked this way, and must treat any key it succeeds on as compromised.
+package forensics
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+
+	"filippo.io/edwards25519"
+	"github.com/hdevalence/ed25519consensus"
+)
+
+var (
+	// ErrNoNonceReuse means the two signatures do not share R, or have the
+	// same challenge, so they reveal nothing about the key.
+	ErrNoNonceReuse = errors.New("forensics: signatures do not reuse a nonce")
+	// ErrInvalidSignature means one of the signatures is not valid under
+	// the public key.
+	ErrInvalidSignature = errors.New("forensics: invalid signature")
+	// ErrNotRecovered means the recovered scalar does not match the public
+	// key, which has a small-order component and was therefore not produced
+	// by standard Ed25519 key generation.
+	ErrNotRecovered = errors.New("forensics: recovered scalar does not match the public key")
+)
+
+// RecoverScalarFromNonceReuse recovers the secret scalar a of publicKey,
+// such that publicKey = [a]B, from two valid signatures of different
+// messages sharing the same R. The scalar is enough to sign any message
+// under publicKey, so it must be handled as a private key.
+func RecoverScalarFromNonceReuse(publicKey ed25519.PublicKey, msg1, sig1, msg2, sig2 []byte) (*edwards25519.Scalar, error) {
+	if !ed25519consensus.Verify(publicKey, msg1, sig1) || !ed25519consensus.Verify(publicKey, msg2, sig2) {
+		return nil, ErrInvalidSignature
+	}
+	if !bytes.Equal(sig1[:32], sig2[:32]) {
+		return nil, ErrNoNonceReuse
+	}
+
+	k1 := ed25519consensus.ComputeChallenge(sig1[:32], publicKey, msg1)
+	k2 := ed25519consensus.ComputeChallenge(sig2[:32], publicKey, msg2)
+	dk := new(edwards25519.Scalar).Subtract(k1, k2)
+	if dk.Equal(edwards25519.NewScalar()) == 1 {
+		return nil, ErrNoNonceReuse
+	}
+
+	s1, err := new(edwards25519.Scalar).SetCanonicalBytes(sig1[32:])
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	s2, err := new(edwards25519.Scalar).SetCanonicalBytes(sig2[32:])
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	ds := new(edwards25519.Scalar).Subtract(s1, s2)
+	a := new(edwards25519.Scalar).Multiply(ds, new(edwards25519.Scalar).Invert(dk))
+
+	// ZIP215: the key may be a non-canonical encoding, so compare points.
+	A, err := new(edwards25519.Point).SetBytes(publicKey)
+	if err != nil || new(edwards25519.Point).ScalarBaseMult(a).Equal(A) != 1 {
+		return nil, ErrNotRecovered
+	}
+	return a, nil
+}
@@ -0,0 +1,55 @@
+package forensics
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/hdevalence/ed25519consensus"
+)
+
+// signWithNonce signs message like a broken signer that always uses the
+// nonce r.
+func signWithNonce(priv ed25519.PrivateKey, r *edwards25519.Scalar, message []byte) []byte {
+	h := sha512.Sum512(priv.Seed())
+	a, _ := new(edwards25519.Scalar).SetBytesWithClamping(h[:32])
+	R := new(edwards25519.Point).ScalarBaseMult(r).Bytes()
+	k := ed25519consensus.ComputeChallenge(R, priv.Public().(ed25519.PublicKey), message)
+	s := new(edwards25519.Scalar).MultiplyAdd(k, a, r)
+	return append(R, s.Bytes()...)
+}
+
+func TestRecoverScalarFromNonceReuse(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	var nonce [64]byte
+	nonce[0] = 42
+	r, _ := new(edwards25519.Scalar).SetUniformBytes(nonce[:])
+
+	msg1, msg2 := []byte("first"), []byte("second")
+	sig1 := signWithNonce(priv, r, msg1)
+	sig2 := signWithNonce(priv, r, msg2)
+	if !ed25519consensus.Verify(pub, msg1, sig1) || !ed25519consensus.Verify(pub, msg2, sig2) {
+		t.Fatal("broken signer produced invalid signatures")
+	}
+
+	a, err := RecoverScalarFromNonceReuse(pub, msg1, sig1, msg2, sig2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := sha512.Sum512(priv.Seed())
+	want, _ := new(edwards25519.Scalar).SetBytesWithClamping(h[:32])
+	if a.Equal(want) != 1 {
+		t.Error("recovered the wrong scalar")
+	}
+
+	if _, err := RecoverScalarFromNonceReuse(pub, msg1, sig1, msg1, sig1); err != ErrNoNonceReuse {
+		t.Errorf("same signature twice: got %v", err)
+	}
+	if _, err := RecoverScalarFromNonceReuse(pub, msg1, sig1, msg2, ed25519.Sign(priv, msg2)); err != ErrNoNonceReuse {
+		t.Errorf("distinct nonces: got %v", err)
+	}
+	if _, err := RecoverScalarFromNonceReuse(pub, msg2, sig1, msg2, sig2); err != ErrInvalidSignature {
+		t.Errorf("invalid signature: got %v", err)
+	}
+}
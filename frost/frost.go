@@ -0,0 +1,65 @@
+// Package frost verifies signatures produced by the FROST(Ed25519, SHA-512)
+// threshold signing protocol of RFC 9591.
+//
+// FROST signatures are ordinary Ed25519 signatures, but RFC 9591 deserializes
+// group elements more strictly than ZIP215: the public key and R must be
+// canonical encodings of non-identity points in the prime-order subgroup.
+// Every signature accepted by this package is therefore also accepted by
+// ed25519consensus.Verify, but not conversely.
+package frost
+
+import (
+	"crypto/ed25519"
+
+	"github.com/hdevalence/ed25519consensus"
+)
+
+// elementPolicy is the RFC 9591 DeserializeElement check for edwards25519.
+const elementPolicy = ed25519consensus.RequireCanonicalKey |
+	ed25519consensus.RejectIdentityKey |
+	ed25519consensus.RequireTorsionFreeKey
+
+// validElements reports whether the public key and the R component of sig
+// pass RFC 9591 element deserialization.
+func validElements(publicKey ed25519.PublicKey, sig []byte) bool {
+	if len(sig) != ed25519.SignatureSize {
+		return false
+	}
+	return ed25519consensus.ValidatePublicKey(publicKey, elementPolicy) == nil &&
+		ed25519consensus.ValidatePublicKey(sig[:32], elementPolicy) == nil
+}
+
+// Verify reports whether sig is a valid FROST(Ed25519, SHA-512) signature of
+// message by the group public key publicKey.
+func Verify(publicKey ed25519.PublicKey, message, sig []byte) bool {
+	return validElements(publicKey, sig) && ed25519consensus.Verify(publicKey, message, sig)
+}
+
+// BatchVerifier batch-verifies FROST signatures with the same criteria as
+// Verify.
+type BatchVerifier struct {
+	bv ed25519consensus.BatchVerifier
+}
+
+// NewBatchVerifier creates an empty BatchVerifier.
+func NewBatchVerifier() *BatchVerifier {
+	return &BatchVerifier{bv: ed25519consensus.NewBatchVerifier()}
+}
+
+// Add adds a (group public key, message, sig) triple to the current batch.
+// Element deserialization is checked immediately; an entry failing it makes
+// the whole batch invalid.
+func (v *BatchVerifier) Add(publicKey ed25519.PublicKey, message, sig []byte) {
+	if !validElements(publicKey, sig) {
+		// An entry with a short key is always invalid.
+		v.bv.Add(nil, message, sig)
+		return
+	}
+	v.bv.Add(publicKey, message, sig)
+}
+
+// Verify checks all entries in the current batch, returning true if all
+// entries are valid. Calling Verify on an empty batch returns false.
+func (v *BatchVerifier) Verify() bool {
+	return v.bv.Verify()
+}
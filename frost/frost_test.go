@@ -0,0 +1,46 @@
+package frost
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/hdevalence/ed25519consensus/testvectors"
+)
+
+func TestVerify(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("threshold-signed message")
+	sig := ed25519.Sign(priv, msg)
+	if !Verify(pub, msg, sig) {
+		t.Error("signature failed to verify")
+	}
+	if Verify(pub, []byte("other message"), sig) {
+		t.Error("signature verified for the wrong message")
+	}
+
+	// Small-order and mixed-order elements, which ZIP215 accepts, are
+	// rejected by element deserialization.
+	for _, v := range append(testvectors.ZIP215(), testvectors.MixedOrder()...) {
+		if Verify(v.PublicKey, v.Message, v.Signature) {
+			t.Errorf("%s: accepted", v.Comment)
+		}
+	}
+}
+
+func TestBatchVerifier(t *testing.T) {
+	v := NewBatchVerifier()
+	for i := 0; i < 10; i++ {
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		msg := []byte{byte(i)}
+		v.Add(pub, msg, ed25519.Sign(priv, msg))
+	}
+	if !v.Verify() {
+		t.Error("failed batch verification")
+	}
+
+	mixed := testvectors.MixedOrder()[0]
+	v.Add(mixed.PublicKey, mixed.Message, mixed.Signature)
+	if v.Verify() {
+		t.Error("batch verification should fail due to a mixed-order key")
+	}
+}
@@ -0,0 +1,86 @@
+package ed25519consensus
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// jwk is the RFC 8037 JSON Web Key representation of an Ed25519 key.
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	D   string `json:"d,omitempty"`
+}
+
+var errInvalidJWK = errors.New("ed25519consensus: invalid Ed25519 JWK")
+
+// MarshalPublicKeyJWK encodes pub as an RFC 8037 JSON Web Key.
+func MarshalPublicKeyJWK(pub ed25519.PublicKey) ([]byte, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, ErrInvalidKeyLength
+	}
+	return json.Marshal(jwk{
+		Kty: "OKP",
+		Crv: "Ed25519",
+		X:   base64.RawURLEncoding.EncodeToString(pub),
+	})
+}
+
+// MarshalPrivateKeyJWK encodes priv, including its public key, as an RFC 8037
+// JSON Web Key.
+func MarshalPrivateKeyJWK(priv ed25519.PrivateKey) ([]byte, error) {
+	if len(priv) != ed25519.PrivateKeySize {
+		return nil, errors.New("ed25519consensus: invalid private key length")
+	}
+	return json.Marshal(jwk{
+		Kty: "OKP",
+		Crv: "Ed25519",
+		X:   base64.RawURLEncoding.EncodeToString(priv.Public().(ed25519.PublicKey)),
+		D:   base64.RawURLEncoding.EncodeToString(priv.Seed()),
+	})
+}
+
+// ParsePublicKeyJWK decodes the public key of an RFC 8037 Ed25519 JSON Web Key.
+// The key may also contain a private key, which is ignored.
+//
+// Like Verify, it does not require the key to be a canonical point encoding.
+func ParsePublicKeyJWK(data []byte) (ed25519.PublicKey, error) {
+	var k jwk
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, err
+	}
+	if k.Kty != "OKP" || k.Crv != "Ed25519" {
+		return nil, errInvalidJWK
+	}
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil || len(x) != ed25519.PublicKeySize {
+		return nil, errInvalidJWK
+	}
+	return ed25519.PublicKey(x), nil
+}
+
+// ParsePrivateKeyJWK decodes the private key of an RFC 8037 Ed25519 JSON Web
+// Key, checking that it matches the public key in the same JWK.
+func ParsePrivateKeyJWK(data []byte) (ed25519.PrivateKey, error) {
+	pub, err := ParsePublicKeyJWK(data)
+	if err != nil {
+		return nil, err
+	}
+	var k jwk
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, err
+	}
+	d, err := base64.RawURLEncoding.DecodeString(k.D)
+	if err != nil || len(d) != ed25519.SeedSize {
+		return nil, errInvalidJWK
+	}
+	priv := ed25519.NewKeyFromSeed(d)
+	if !bytes.Equal(priv.Public().(ed25519.PublicKey), pub) {
+		return nil, errors.New("ed25519consensus: JWK private key does not match its public key")
+	}
+	return priv, nil
+}
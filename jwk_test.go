@@ -0,0 +1,57 @@
+package ed25519consensus
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+)
+
+// rfc8037Key is the example key from RFC 8037, Appendix A.1.
+const rfc8037Key = `{"kty":"OKP","crv":"Ed25519",
+   "d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A",
+   "x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+
+func TestJWK(t *testing.T) {
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	want := ed25519.NewKeyFromSeed(seed)
+
+	priv, err := ParsePrivateKeyJWK([]byte(rfc8037Key))
+	if err != nil || !bytes.Equal(priv, want) {
+		t.Fatalf("ParsePrivateKeyJWK: got %x, %v", priv, err)
+	}
+	pub, err := ParsePublicKeyJWK([]byte(rfc8037Key))
+	if err != nil || !bytes.Equal(pub, want.Public().(ed25519.PublicKey)) {
+		t.Fatalf("ParsePublicKeyJWK: got %x, %v", pub, err)
+	}
+
+	data, err := MarshalPrivateKeyJWK(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ParsePrivateKeyJWK(data); err != nil || !bytes.Equal(got, priv) {
+		t.Errorf("private key round trip: got %x, %v", got, err)
+	}
+	data, err = MarshalPublicKeyJWK(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`; string(data) != want {
+		t.Errorf("MarshalPublicKeyJWK: got %s, want %s", data, want)
+	}
+	if _, err := ParsePrivateKeyJWK(data); err == nil {
+		t.Error("ParsePrivateKeyJWK accepted a public key")
+	}
+
+	for _, bad := range []string{
+		`{"kty":"EC","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`,
+		`{"kty":"OKP","crv":"X25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`,
+		`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcH"}`,
+		`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo",
+		  "d":"AAAAne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"}`,
+	} {
+		if _, err := ParsePrivateKeyJWK([]byte(bad)); err == nil {
+			t.Errorf("ParsePrivateKeyJWK accepted %s", bad)
+		}
+	}
+}
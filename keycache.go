@@ -0,0 +1,105 @@
+package ed25519consensus
+
+import (
+	"container/list"
+	"crypto/ed25519"
+	"sync"
+	"sync/atomic"
+
+	"filippo.io/edwards25519"
+)
+
+// keyCache maps public key encodings to their decompressed points, for
+// workloads where keys repeat but are not known in advance. It is disabled
+// unless SetKeyCacheSize is called.
+var keyCache struct {
+	enabled atomic.Bool
+
+	mu       sync.Mutex
+	capacity int
+	entries  map[[ed25519.PublicKeySize]byte]*list.Element
+	lru      list.List // of *keyCacheEntry, most recently used first
+}
+
+type keyCacheEntry struct {
+	encoding [ed25519.PublicKeySize]byte
+	A        edwards25519.Point
+}
+
+// SetKeyCacheSize makes Verify and the batch verifiers remember the
+// decompressed points of the n most recently used public keys, saving the
+// decompression, which costs about a tenth of a verification, when keys
+// repeat. Invalid encodings are not cached. The cache is shared by the whole
+// process. A size of zero, the default, disables and empties it.
+//
+// Sessions (see NewSession) are a better fit when the keys are known in
+// advance.
+func SetKeyCacheSize(n int) {
+	keyCache.mu.Lock()
+	defer keyCache.mu.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	keyCache.capacity = n
+	if n == 0 {
+		keyCache.entries = nil
+		keyCache.lru.Init()
+		keyCache.enabled.Store(false)
+		return
+	}
+	if keyCache.entries == nil {
+		keyCache.entries = make(map[[ed25519.PublicKeySize]byte]*list.Element)
+	}
+	for len(keyCache.entries) > n {
+		evictKey()
+	}
+	keyCache.enabled.Store(true)
+}
+
+// evictKey removes the least recently used key. keyCache.mu must be held.
+func evictKey() {
+	oldest := keyCache.lru.Back()
+	delete(keyCache.entries, oldest.Value.(*keyCacheEntry).encoding)
+	keyCache.lru.Remove(oldest)
+}
+
+// decodeKey sets A to the point encoded by publicKey, which must be 32 bytes
+// long, consulting the key cache if enabled. Like SetBytes, it accepts
+// non-canonical encodings, as required by ZIP215.
+func decodeKey(A *edwards25519.Point, publicKey []byte) error {
+	if !keyCache.enabled.Load() {
+		_, err := A.SetBytes(publicKey)
+		return err
+	}
+
+	var encoding [ed25519.PublicKeySize]byte
+	copy(encoding[:], publicKey)
+	keyCache.mu.Lock()
+	if el, ok := keyCache.entries[encoding]; ok {
+		keyCache.lru.MoveToFront(el)
+		A.Set(&el.Value.(*keyCacheEntry).A)
+		keyCache.mu.Unlock()
+		return nil
+	}
+	keyCache.mu.Unlock()
+
+	if _, err := A.SetBytes(publicKey); err != nil {
+		return err
+	}
+
+	e := &keyCacheEntry{encoding: encoding}
+	e.A.Set(A)
+	keyCache.mu.Lock()
+	defer keyCache.mu.Unlock()
+	if keyCache.capacity == 0 {
+		return nil
+	}
+	if _, ok := keyCache.entries[encoding]; ok {
+		return nil
+	}
+	if len(keyCache.entries) >= keyCache.capacity {
+		evictKey()
+	}
+	keyCache.entries[encoding] = keyCache.lru.PushFront(e)
+	return nil
+}
@@ -0,0 +1,63 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/hdevalence/ed25519consensus/testvectors"
+)
+
+func TestKeyCache(t *testing.T) {
+	SetKeyCacheSize(2)
+	t.Cleanup(func() { SetKeyCacheSize(0) })
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("cached key")
+	sig := ed25519.Sign(priv, msg)
+	for i := 0; i < 2; i++ {
+		if !Verify(pub, msg, sig) {
+			t.Fatal("valid signature rejected")
+		}
+	}
+	if allocs := testing.AllocsPerRun(100, func() { Verify(pub, msg, sig) }); allocs > 0 {
+		t.Errorf("Verify with a cached key allocated %v times", allocs)
+	}
+
+	for i := 0; i < 3; i++ {
+		other, _, _ := ed25519.GenerateKey(nil)
+		Verify(other, msg, sig)
+	}
+	if n := len(keyCache.entries); n != 2 {
+		t.Errorf("cache holds %d keys, want 2", n)
+	}
+	var encoding [32]byte
+	copy(encoding[:], pub)
+	if _, ok := keyCache.entries[encoding]; ok {
+		t.Error("least recently used key was not evicted")
+	}
+
+	SetKeyCacheSize(1)
+	if n := len(keyCache.entries); n != 1 {
+		t.Errorf("cache holds %d keys after shrinking, want 1", n)
+	}
+	SetKeyCacheSize(0)
+	if n := len(keyCache.entries); n != 0 {
+		t.Errorf("cache holds %d keys after disabling, want 0", n)
+	}
+}
+
+func TestZIP215KeyCache(t *testing.T) {
+	SetKeyCacheSize(16)
+	t.Cleanup(func() { SetKeyCacheSize(0) })
+
+	// Run twice, so that the keys are found in the cache the second time.
+	for i := 0; i < 2; i++ {
+		testvectors.Run(t, Verify)
+		testvectors.Run(t, func(publicKey ed25519.PublicKey, message, signature []byte) bool {
+			v := NewBatchVerifier()
+			v.Add(publicKey, message, signature)
+			v.Add(publicKey, message, signature)
+			return v.Verify()
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// PublicKey is an Ed25519 public key of the correct length. It is a point
+// encoding, which may be invalid or non-canonical; see ValidatePublicKey.
+type PublicKey [ed25519.PublicKeySize]byte
+
+// Signature is an Ed25519 signature of the correct length, whose top three
+// bits are clear, as checked by NewSignature.
+type Signature [ed25519.SignatureSize]byte
+
+// NewPublicKey returns b as a PublicKey. It returns ErrInvalidKeyLength if b
+// is not 32 bytes long.
+func NewPublicKey(b []byte) (PublicKey, error) {
+	var pk PublicKey
+	if len(b) != len(pk) {
+		return pk, ErrInvalidKeyLength
+	}
+	copy(pk[:], b)
+	return pk, nil
+}
+
+// NewSignature returns b as a Signature. It returns ErrMalformedSignature if b
+// is not 64 bytes long, or if any of the top three bits of its last byte are
+// set, which Verify would reject regardless of the public key and message.
+func NewSignature(b []byte) (Signature, error) {
+	var sig Signature
+	if len(b) != len(sig) || b[63]&224 != 0 {
+		return sig, ErrMalformedSignature
+	}
+	copy(sig[:], b)
+	return sig, nil
+}
+
+// Verify reports whether sig is a valid signature of message by pk, with the
+// same semantics as the package-level Verify. Like Verify, it does not
+// allocate.
+func (pk *PublicKey) Verify(message []byte, sig *Signature) bool {
+	// A Signature built without NewSignature may still have its top bits set.
+	if sig[63]&224 != 0 {
+		return false
+	}
+
+	h := sha512.New()
+	h.Write(sig[:32])
+	h.Write(pk[:])
+	h.Write(message)
+	var digest [64]byte
+	h.Sum(digest[:0])
+
+	return verifyDigest(pk[:], sig[:], &digest, false)
+}
+
+// String returns the hexadecimal encoding of pk.
+func (pk PublicKey) String() string {
+	return hex.EncodeToString(pk[:])
+}
+
+// MarshalText implements encoding.TextMarshaler, encoding pk in hexadecimal.
+func (pk PublicKey) MarshalText() ([]byte, error) {
+	return hexText(pk[:]), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts hexadecimal,
+// as produced by MarshalText, and standard padded Base64, as found in
+// CometBFT genesis documents.
+func (pk *PublicKey) UnmarshalText(text []byte) error {
+	b, err := decodeText(text, len(pk), ErrInvalidKeyLength)
+	if err != nil {
+		return err
+	}
+	k, err := NewPublicKey(b)
+	if err != nil {
+		return err
+	}
+	*pk = k
+	return nil
+}
+
+// Bech32 returns the Bech32 encoding of pk with the human-readable part hrp,
+// which must be lowercase.
+func (pk PublicKey) Bech32(hrp string) (string, error) {
+	return encodeBech32(hrp, pk[:])
+}
+
+// ParsePublicKeyBech32 decodes a public key encoded by PublicKey.Bech32 with
+// the human-readable part hrp.
+func ParsePublicKeyBech32(s, hrp string) (PublicKey, error) {
+	b, err := decodeBech32(s, hrp)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	return NewPublicKey(b)
+}
+
+// String returns the hexadecimal encoding of sig.
+func (sig Signature) String() string {
+	return hex.EncodeToString(sig[:])
+}
+
+// MarshalText implements encoding.TextMarshaler, encoding sig in
+// hexadecimal.
+func (sig Signature) MarshalText() ([]byte, error) {
+	return hexText(sig[:]), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts hexadecimal,
+// as produced by MarshalText, and standard padded Base64, and applies the
+// checks of NewSignature.
+func (sig *Signature) UnmarshalText(text []byte) error {
+	b, err := decodeText(text, len(sig), ErrMalformedSignature)
+	if err != nil {
+		return err
+	}
+	s, err := NewSignature(b)
+	if err != nil {
+		return err
+	}
+	*sig = s
+	return nil
+}
+
+// Bech32 returns the Bech32 encoding of sig with the human-readable part
+// hrp, which must be lowercase. The result is longer than the 90 characters
+// allowed by BIP 173, which some decoders enforce.
+func (sig Signature) Bech32(hrp string) (string, error) {
+	return encodeBech32(hrp, sig[:])
+}
+
+// ParseSignatureBech32 decodes a signature encoded by Signature.Bech32 with
+// the human-readable part hrp, and applies the checks of NewSignature.
+func ParseSignatureBech32(s, hrp string) (Signature, error) {
+	b, err := decodeBech32(s, hrp)
+	if err != nil {
+		return Signature{}, err
+	}
+	return NewSignature(b)
+}
+
+func hexText(b []byte) []byte {
+	out := make([]byte, hex.EncodedLen(len(b)))
+	hex.Encode(out, b)
+	return out
+}
+
+// decodeText decodes the hexadecimal or Base64 encoding of n bytes, telling
+// them apart by length, and returns lengthErr for text of any other length.
+func decodeText(text []byte, n int, lengthErr error) ([]byte, error) {
+	switch len(text) {
+	case hex.EncodedLen(n):
+		b := make([]byte, n)
+		if _, err := hex.Decode(b, text); err != nil {
+			return nil, err
+		}
+		return b, nil
+	case base64.StdEncoding.EncodedLen(n):
+		b := make([]byte, n)
+		if _, err := base64.StdEncoding.Decode(b, text); err != nil {
+			return nil, err
+		}
+		return b, nil
+	default:
+		return nil, lengthErr
+	}
+}
@@ -0,0 +1,99 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewPublicKey(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(nil)
+	pk, err := NewPublicKey(pub)
+	if err != nil || string(pk[:]) != string(pub) {
+		t.Errorf("NewPublicKey(%x) = %x, %v", pub, pk, err)
+	}
+	if _, err := NewPublicKey(pub[:31]); err != ErrInvalidKeyLength {
+		t.Errorf("short key: got %v, want ErrInvalidKeyLength", err)
+	}
+}
+
+func TestNewSignature(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	sig := ed25519.Sign(priv, []byte("typed"))
+	if _, err := NewSignature(sig); err != nil {
+		t.Errorf("valid signature: %v", err)
+	}
+	if _, err := NewSignature(sig[:63]); err != ErrMalformedSignature {
+		t.Errorf("short signature: got %v, want ErrMalformedSignature", err)
+	}
+	sig[63] |= 0x20
+	if _, err := NewSignature(sig); err != ErrMalformedSignature {
+		t.Errorf("high bits set: got %v, want ErrMalformedSignature", err)
+	}
+}
+
+func TestPublicKeyVerify(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("typed")
+	pk, _ := NewPublicKey(pub)
+	sig, _ := NewSignature(ed25519.Sign(priv, msg))
+	if !pk.Verify(msg, &sig) {
+		t.Fatal("valid signature rejected")
+	}
+	if pk.Verify([]byte("other"), &sig) {
+		t.Error("signature accepted for the wrong message")
+	}
+
+	// Direct construction bypasses NewSignature.
+	bad := sig
+	bad[63] |= 0x80
+	if pk.Verify(msg, &bad) {
+		t.Error("signature with high bits set accepted")
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() {
+		if !pk.Verify(msg, &sig) {
+			t.Fatal("signature failed to verify")
+		}
+	}); allocs > 0 {
+		t.Errorf("expected zero allocations, got %0.1f", allocs)
+	}
+}
+
+func TestKeyText(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	pk, _ := NewPublicKey(pub)
+	sig, _ := NewSignature(ed25519.Sign(priv, []byte("text")))
+
+	text, _ := json.Marshal(struct {
+		Key PublicKey
+		Sig Signature
+	}{pk, sig})
+	var got struct {
+		Key PublicKey
+		Sig Signature
+	}
+	if err := json.Unmarshal(text, &got); err != nil || got.Key != pk || got.Sig != sig {
+		t.Errorf("JSON round trip of %s: got %v, %v, %v", text, got.Key, got.Sig, err)
+	}
+
+	var fromBase64 PublicKey
+	if err := fromBase64.UnmarshalText([]byte(base64.StdEncoding.EncodeToString(pub))); err != nil || fromBase64 != pk {
+		t.Errorf("Base64 key: got %v, %v", fromBase64, err)
+	}
+
+	for _, text := range []string{"", "00", string(pub), strings.Repeat("zz", 32)} {
+		var pk PublicKey
+		if err := pk.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("UnmarshalText(%q) succeeded", text)
+		}
+	}
+	var bad Signature
+	highBits := sig
+	highBits[63] |= 0x80
+	if err := bad.UnmarshalText([]byte(highBits.String())); err != ErrMalformedSignature {
+		t.Errorf("signature with high bits set: got %v, want ErrMalformedSignature", err)
+	}
+}
@@ -0,0 +1,146 @@
+package ed25519consensus
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// ErrManagerClosed is returned by BatchManager.Verify after Close.
+var ErrManagerClosed = errors.New("ed25519consensus: batch manager closed")
+
+// BatchManager verifies independent batches, such as one per block or per
+// shard, on a fixed pool of worker goroutines, so that nodes processing
+// several chains in parallel do not oversubscribe their CPUs.
+//
+// Scheduling is fair across concurrent calls to Verify: workers take batches
+// from each pending call in turn, so a call with many batches does not delay
+// a call with few until all of its own batches are done.
+//
+// Each batch is verified on a single worker. Batches should not also use
+// deferred hashing (see BatchVerifier.SetDeferredHashing), which would start
+// goroutines of their own.
+type BatchManager struct {
+	mu      sync.Mutex
+	cond    sync.Cond
+	pending []*batchGroup // calls with batches left to start, in turn order
+	next    int
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// batchGroup is the batches of one call to Verify.
+type batchGroup struct {
+	batches   []*BatchVerifier
+	started   int
+	results   []bool
+	remaining int
+	done      chan struct{}
+}
+
+// NewBatchManager starts a BatchManager with the given number of workers. A
+// non-positive workers selects runtime.GOMAXPROCS(0).
+//
+// The manager must be stopped with Close to release its goroutines.
+func NewBatchManager(workers int) *BatchManager {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	m := &BatchManager{}
+	m.cond.L = &m.mu
+	m.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go m.work()
+	}
+	return m
+}
+
+// Verify verifies each batch as by BatchVerifier.Verify on the workers of m,
+// and returns the results in the order of batches once all are done. The
+// batches must not be used by the caller until Verify returns.
+func (m *BatchManager) Verify(batches ...*BatchVerifier) ([]bool, error) {
+	g, err := m.submit(batches)
+	if err != nil {
+		return nil, err
+	}
+	<-g.done
+	return g.results, nil
+}
+
+// submit queues batches for the workers, and returns the group whose done
+// channel is closed once they are all verified.
+func (m *BatchManager) submit(batches []*BatchVerifier) (*batchGroup, error) {
+	g := &batchGroup{
+		batches:   batches,
+		results:   make([]bool, len(batches)),
+		remaining: len(batches),
+		done:      make(chan struct{}),
+	}
+	if len(batches) == 0 {
+		close(g.done)
+		return g, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil, ErrManagerClosed
+	}
+	m.pending = append(m.pending, g)
+	m.cond.Broadcast()
+	return g, nil
+}
+
+// Close waits for the batches already submitted to be verified, and stops
+// the workers. Later calls to Verify return ErrManagerClosed.
+func (m *BatchManager) Close() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	m.cond.Broadcast()
+	m.mu.Unlock()
+	m.wg.Wait()
+}
+
+// work verifies batches, taking them from the pending calls in turn, until
+// the manager is closed and no batches are left.
+func (m *BatchManager) work() {
+	defer m.wg.Done()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for {
+		for len(m.pending) == 0 && !m.closed {
+			m.cond.Wait()
+		}
+		if len(m.pending) == 0 {
+			return
+		}
+
+		// Take the next batch of the next call in turn.
+		if m.next >= len(m.pending) {
+			m.next = 0
+		}
+		g := m.pending[m.next]
+		i := g.started
+		g.started++
+		if g.started == len(g.batches) {
+			m.pending = append(m.pending[:m.next], m.pending[m.next+1:]...)
+		} else {
+			m.next++
+		}
+
+		m.mu.Unlock()
+		ok := g.batches[i].Verify()
+		m.mu.Lock()
+
+		g.results[i] = ok
+		g.remaining--
+		if g.remaining == 0 {
+			close(g.done)
+		}
+	}
+}
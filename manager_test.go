@@ -0,0 +1,102 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestBatchManager(t *testing.T) {
+	m := NewBatchManager(3)
+
+	var wg sync.WaitGroup
+	for c := 0; c < 4; c++ {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+			batches := make([]*BatchVerifier, 5)
+			for i := range batches {
+				v := NewBatchVerifier()
+				populateBatchVerifier(t, &v)
+				if i == c {
+					pub, _, _ := ed25519.GenerateKey(nil)
+					v.Add(pub, nil, make([]byte, ed25519.SignatureSize))
+				}
+				batches[i] = &v
+			}
+			results, err := m.Verify(batches...)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			for i, ok := range results {
+				if ok != (i != c) {
+					t.Errorf("call %d: batch %d verified: %v", c, i, ok)
+				}
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	if results, err := m.Verify(); err != nil || len(results) != 0 {
+		t.Errorf("Verify() = %v, %v", results, err)
+	}
+
+	m.Close()
+	m.Close()
+	v := NewBatchVerifier()
+	populateBatchVerifier(t, &v)
+	if _, err := m.Verify(&v); err != ErrManagerClosed {
+		t.Errorf("Verify after Close: got %v, want ErrManagerClosed", err)
+	}
+}
+
+func TestBatchManagerFairness(t *testing.T) {
+	// With a single worker, batches of concurrent calls are interleaved.
+	m := NewBatchManager(1)
+	defer m.Close()
+
+	// The first batch holds the worker until the other calls are queued.
+	started := make(chan struct{})
+	block := make(chan struct{})
+	first := NewBatchVerifier()
+	first.SetFailureCallback(func(int, ed25519.PublicKey, []byte, []byte, error) {
+		close(started)
+		<-block
+	})
+	first.Add(nil, nil, nil)
+
+	// The batches are only verified by the single worker, one at a time.
+	var order []int
+	record := func(call int) *BatchVerifier {
+		v := NewBatchVerifier()
+		v.SetFailureCallback(func(int, ed25519.PublicKey, []byte, []byte, error) {
+			order = append(order, call)
+		})
+		v.Add(nil, nil, nil)
+		return &v
+	}
+
+	g0, err := m.submit([]*BatchVerifier{&first})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	g1, err := m.submit([]*BatchVerifier{record(1), record(1), record(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g2, err := m.submit([]*BatchVerifier{record(2), record(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	close(block)
+	<-g0.done
+	<-g1.done
+	<-g2.done
+
+	if want := []int{1, 2, 1, 2, 1}; !reflect.DeepEqual(order, want) {
+		t.Errorf("batches verified in order %v, want %v", order, want)
+	}
+}
@@ -0,0 +1,58 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"sync/atomic"
+	"time"
+)
+
+// MetricsCollector receives measurements from Verify and BatchVerifier.Verify,
+// for export to a monitoring system such as Prometheus. Its methods may be
+// called concurrently, must return quickly, and must not verify signatures
+// themselves.
+type MetricsCollector interface {
+	// ObserveVerify is called after each call to Verify, with the
+	// decision and how long it took.
+	ObserveVerify(valid bool, d time.Duration)
+	// ObserveBatch is called after each call to BatchVerifier.Verify or
+	// AdaptiveVerifier.Verify, with the number of entries, whether they
+	// are all valid and how long it took.
+	ObserveBatch(size int, valid bool, d time.Duration)
+	// ObserveFailure is called for each signature rejected by Verify or
+	// by a failed batch, with the reason, one of the errors reported by
+	// VerifyWithOptions or a failure callback, such as ErrInvalidSignature.
+	ObserveFailure(reason error)
+}
+
+type metricsHolder struct {
+	c MetricsCollector
+}
+
+// metrics holds the collector set by SetMetricsCollector, or nil.
+var metrics atomic.Pointer[metricsHolder]
+
+// SetMetricsCollector makes Verify and BatchVerifier.Verify report to c. The
+// collector is shared by the whole process. A nil c, the default, disables
+// metrics, which then cost nothing.
+//
+// To report the reason for each failure, Verify checks a rejected signature
+// a second time, and a failed batch verifies each entry individually, as
+// when a failure callback is set.
+func SetMetricsCollector(c MetricsCollector) {
+	if c == nil {
+		metrics.Store(nil)
+		return
+	}
+	metrics.Store(&metricsHolder{c})
+}
+
+// observeVerify runs verify, reporting to m.
+func (m *metricsHolder) observeVerify(publicKey ed25519.PublicKey, message, sig []byte) bool {
+	start := time.Now()
+	valid := verify(publicKey, message, sig)
+	m.c.ObserveVerify(valid, time.Since(start))
+	if !valid {
+		m.c.ObserveFailure(VerifyWithOptions(publicKey, message, sig, &Options{}))
+	}
+	return valid
+}
@@ -0,0 +1,91 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testCollector struct {
+	mu       sync.Mutex
+	verifies []bool
+	batches  []int
+	failures []error
+}
+
+func (c *testCollector) ObserveVerify(valid bool, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.verifies = append(c.verifies, valid)
+}
+
+func (c *testCollector) ObserveBatch(size int, valid bool, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !valid {
+		size = -size
+	}
+	c.batches = append(c.batches, size)
+}
+
+func (c *testCollector) ObserveFailure(reason error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures = append(c.failures, reason)
+}
+
+func TestMetricsCollector(t *testing.T) {
+	c := &testCollector{}
+	SetMetricsCollector(c)
+	t.Cleanup(func() { SetMetricsCollector(nil) })
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("measured")
+	sig := ed25519.Sign(priv, msg)
+	bad := append([]byte(nil), sig...)
+	bad[40] ^= 1
+
+	Verify(pub, msg, sig)
+	Verify(pub, msg, bad)
+	Verify(pub[:31], msg, sig)
+
+	v := NewBatchVerifier()
+	v.Add(pub, msg, sig)
+	v.Add(pub, msg, sig)
+	v.Verify()
+	v.Add(pub, msg, bad)
+	v.Add(pub, msg, sig[:10])
+	v.Verify()
+
+	SetMetricsCollector(nil)
+	Verify(pub, msg, bad)
+
+	if want := []bool{true, false, false}; !equalBools(c.verifies, want) {
+		t.Errorf("verifications %v, want %v", c.verifies, want)
+	}
+	if len(c.batches) != 2 || c.batches[0] != 2 || c.batches[1] != -4 {
+		t.Errorf("batches %v, want [2 -4]", c.batches)
+	}
+	want := []error{ErrInvalidSignature, ErrInvalidKeyLength, ErrInvalidSignature, ErrMalformedEntry}
+	if len(c.failures) != len(want) {
+		t.Fatalf("failures %v, want %v", c.failures, want)
+	}
+	for i := range want {
+		if c.failures[i] != want[i] {
+			t.Errorf("failure %d: %v, want %v", i, c.failures[i], want[i])
+		}
+	}
+}
+
+func equalBools(a, b []bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+package ed25519consensus
+
+import (
+	"encoding/binary"
+
+	"filippo.io/edwards25519"
+)
+
+const (
+	// AutoWindow lets Verify choose the multiscalar multiplication strategy
+	// from the size of the batch. It is the default.
+	AutoWindow = 0
+
+	// StrausWindow makes Verify always use the interleaved sliding-window
+	// (Straus) multiscalar multiplication provided by edwards25519.
+	StrausWindow = -1
+
+	// maxWindow bounds the Pippenger window so that signed digits fit in an
+	// int16 and the buckets stay within a few megabytes.
+	maxWindow = 16
+
+	// pippengerThreshold is the number of terms from which AutoWindow
+	// switches from Straus to Pippenger.
+	pippengerThreshold = 256
+)
+
+// multiScalarMult sets p = sum(scalars[i] * points[i]) using the strategy
+// selected by window, as described in BatchVerifier.SetWindowSize.
+func multiScalarMult(p *edwards25519.Point, scalars []*edwards25519.Scalar, points []*edwards25519.Point, window int) *edwards25519.Point {
+	if window == AutoWindow {
+		if len(scalars) < pippengerThreshold {
+			window = StrausWindow
+		} else {
+			window = pippengerWindow(len(scalars))
+		}
+	}
+	if window == StrausWindow {
+		return p.VarTimeMultiScalarMult(scalars, points)
+	}
+	return pippengerMultiScalarMult(p, scalars, points, uint(window))
+}
+
+// pippengerWindow returns the window size minimizing the approximate number
+// of point additions performed by Pippenger's method over n terms.
+func pippengerWindow(n int) int {
+	best, bestCost := 2, -1
+	for w := 2; w <= maxWindow; w++ {
+		windows := (253+w-1)/w + 1
+		cost := windows * (n + 1<<w)
+		if bestCost < 0 || cost < bestCost {
+			best, bestCost = w, cost
+		}
+	}
+	return best
+}
+
+// pippengerMultiScalarMult sets p = sum(scalars[i] * points[i]) using
+// Pippenger's bucket method with signed digits of w bits.
+func pippengerMultiScalarMult(p *edwards25519.Point, scalars []*edwards25519.Scalar, points []*edwards25519.Point, w uint) *edwards25519.Point {
+	if len(scalars) != len(points) {
+		panic("ed25519consensus: called multiScalarMult with different size inputs")
+	}
+
+	// Scalars are reduced, so they fit in 253 bits. One extra digit absorbs
+	// the carry out of the top window.
+	numDigits := (253+int(w)-1)/int(w) + 1
+	digits := make([]int16, len(scalars)*numDigits)
+	for i, s := range scalars {
+		signedDigits(s, w, digits[i*numDigits:(i+1)*numDigits])
+	}
+
+	buckets := make([]edwards25519.Point, 1<<(w-1))
+	acc := edwards25519.NewIdentityPoint()
+	running, sum := new(edwards25519.Point), new(edwards25519.Point)
+	for d := numDigits - 1; d >= 0; d-- {
+		for i := uint(0); i < w; i++ {
+			acc.Add(acc, acc)
+		}
+
+		for i := range buckets {
+			buckets[i].Set(edwards25519.NewIdentityPoint())
+		}
+		used := 0
+		for i, point := range points {
+			switch digit := int(digits[i*numDigits+d]); {
+			case digit > 0:
+				buckets[digit-1].Add(&buckets[digit-1], point)
+				if digit > used {
+					used = digit
+				}
+			case digit < 0:
+				buckets[-digit-1].Subtract(&buckets[-digit-1], point)
+				if -digit > used {
+					used = -digit
+				}
+			}
+		}
+
+		// sum = sum((i+1) * buckets[i]), computed with running sums.
+		running.Set(edwards25519.NewIdentityPoint())
+		sum.Set(edwards25519.NewIdentityPoint())
+		for i := used - 1; i >= 0; i-- {
+			running.Add(running, &buckets[i])
+			sum.Add(sum, running)
+		}
+		acc.Add(acc, sum)
+	}
+	return p.Set(acc)
+}
+
+// signedDigits writes the radix-2^w signed digit expansion of s into digits,
+// least significant first. Each digit is in [-2^(w-1), 2^(w-1)).
+func signedDigits(s *edwards25519.Scalar, w uint, digits []int16) {
+	var buf [40]byte
+	copy(buf[:], s.Bytes())
+
+	radix, half := 1<<w, 1<<(w-1)
+	carry := 0
+	for i := range digits {
+		offset := uint(i) * w
+		word := binary.LittleEndian.Uint32(buf[offset/8:])
+		digit := int(word>>(offset%8))&(radix-1) + carry
+		carry = 0
+		if digit >= half {
+			digit -= radix
+			carry = 1
+		}
+		digits[i] = int16(digit)
+	}
+}
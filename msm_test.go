@@ -0,0 +1,101 @@
+package ed25519consensus
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"filippo.io/edwards25519"
+)
+
+func TestPippengerMatchesStraus(t *testing.T) {
+	scalars, points := randomTerms(t, 50)
+	want := new(edwards25519.Point).VarTimeMultiScalarMult(scalars, points)
+	for w := 2; w <= maxWindow; w++ {
+		got := pippengerMultiScalarMult(new(edwards25519.Point), scalars, points, uint(w))
+		if got.Equal(want) != 1 {
+			t.Errorf("Pippenger with window %d disagrees with Straus", w)
+		}
+	}
+}
+
+func TestSignedDigits(t *testing.T) {
+	scalars, _ := randomTerms(t, 10)
+	for w := uint(2); w <= maxWindow; w++ {
+		for _, s := range scalars {
+			digits := make([]int16, (253+int(w)-1)/int(w)+1)
+			signedDigits(s, w, digits)
+
+			// Recompute the scalar from its digits with Horner's method.
+			var radix [32]byte
+			radix[w/8] = 1 << (w % 8)
+			r, _ := new(edwards25519.Scalar).SetCanonicalBytes(radix[:])
+			got := new(edwards25519.Scalar)
+			for i := len(digits) - 1; i >= 0; i-- {
+				d := scalarFromInt(int(digits[i]))
+				got.MultiplyAdd(got, r, d)
+			}
+			if got.Equal(s) != 1 {
+				t.Errorf("signed digits with window %d do not recompose the scalar", w)
+			}
+		}
+	}
+}
+
+func TestBatchWindowSizes(t *testing.T) {
+	for _, w := range []int{StrausWindow, AutoWindow, 2, 5, maxWindow} {
+		v := NewBatchVerifier()
+		populateBatchVerifier(t, &v)
+		v.SetWindowSize(w)
+		if !v.Verify() {
+			t.Errorf("failed batch verification with window size %d", w)
+		}
+
+		v.entries[4].signature[1] ^= 1
+		if v.Verify() {
+			t.Errorf("batch verification with window size %d should fail due to corrupt signature", w)
+		}
+	}
+}
+
+func BenchmarkMultiScalarMult(b *testing.B) {
+	for _, n := range []int{64, 256, 1024, 4096, 16384} {
+		scalars, points := randomTerms(b, n)
+		for _, w := range []int{StrausWindow, AutoWindow} {
+			b.Run(fmt.Sprintf("%d/window=%d", n, w), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					multiScalarMult(new(edwards25519.Point), scalars, points, w)
+				}
+			})
+		}
+	}
+}
+
+func randomTerms(tb testing.TB, n int) ([]*edwards25519.Scalar, []*edwards25519.Point) {
+	scalars := make([]*edwards25519.Scalar, n)
+	points := make([]*edwards25519.Point, n)
+	var buf [64]byte
+	for i := range scalars {
+		if _, err := rand.Read(buf[:]); err != nil {
+			tb.Fatal(err)
+		}
+		scalars[i], _ = new(edwards25519.Scalar).SetUniformBytes(buf[:])
+		k, _ := new(edwards25519.Scalar).SetUniformBytes(buf[:])
+		points[i] = new(edwards25519.Point).ScalarBaseMult(k.Multiply(k, k))
+	}
+	return scalars, points
+}
+
+func scalarFromInt(x int) *edwards25519.Scalar {
+	var buf [32]byte
+	neg := x < 0
+	if neg {
+		x = -x
+	}
+	buf[0], buf[1], buf[2] = byte(x), byte(x>>8), byte(x>>16)
+	s, _ := new(edwards25519.Scalar).SetCanonicalBytes(buf[:])
+	if neg {
+		s.Negate(s)
+	}
+	return s
+}
@@ -0,0 +1,111 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"math/bits"
+)
+
+// MultiEd25519 keys and signatures use the format of Move-based chains such as
+// Aptos:
+//
+//   - a public key is N concatenated Ed25519 keys followed by a one-byte
+//     threshold K, with 1 <= K <= N <= MaxMultiKeys;
+//   - a signature is M concatenated Ed25519 signatures followed by a
+//     four-byte bitmap whose M set bits give, in increasing order, the index
+//     of the key that produced each signature. Bit i is the bit
+//     0x80 >> (i % 8) of byte i / 8.
+//
+// A multi-signature is valid if M >= K and every signature is valid under its
+// key with the validation criteria of Verify.
+
+// MaxMultiKeys is the maximum number of keys in a MultiEd25519 public key.
+const MaxMultiKeys = 32
+
+const multiBitmapSize = 4
+
+// multiEntry is a single signature of a parsed multi-signature.
+type multiEntry struct {
+	pub ed25519.PublicKey
+	sig []byte
+}
+
+// parseMulti splits a MultiEd25519 public key and signature into (key,
+// signature) pairs, checking the format and the threshold.
+func parseMulti(publicKey, sig []byte) ([]multiEntry, bool) {
+	if len(publicKey) < ed25519.PublicKeySize+1 || (len(publicKey)-1)%ed25519.PublicKeySize != 0 {
+		return nil, false
+	}
+	n := (len(publicKey) - 1) / ed25519.PublicKeySize
+	threshold := int(publicKey[len(publicKey)-1])
+	if n > MaxMultiKeys || threshold < 1 || threshold > n {
+		return nil, false
+	}
+
+	if len(sig) < multiBitmapSize || (len(sig)-multiBitmapSize)%ed25519.SignatureSize != 0 {
+		return nil, false
+	}
+	m := (len(sig) - multiBitmapSize) / ed25519.SignatureSize
+	bitmap := sig[len(sig)-multiBitmapSize:]
+	set := uint32(bitmap[0])<<24 | uint32(bitmap[1])<<16 | uint32(bitmap[2])<<8 | uint32(bitmap[3])
+	if bits.OnesCount32(set) != m || m < threshold {
+		return nil, false
+	}
+	// Bits beyond the last key must be clear.
+	if n < MaxMultiKeys && set<<n != 0 {
+		return nil, false
+	}
+
+	entries := make([]multiEntry, 0, m)
+	for i := 0; i < n; i++ {
+		if set&(1<<(31-i)) == 0 {
+			continue
+		}
+		j := len(entries)
+		entries = append(entries, multiEntry{
+			pub: publicKey[i*ed25519.PublicKeySize : (i+1)*ed25519.PublicKeySize],
+			sig: sig[j*ed25519.SignatureSize : (j+1)*ed25519.SignatureSize],
+		})
+	}
+	return entries, true
+}
+
+// VerifyMulti reports whether sig is a valid MultiEd25519 signature of
+// message by publicKey.
+func VerifyMulti(publicKey, message, sig []byte) bool {
+	entries, ok := parseMulti(publicKey, sig)
+	if !ok {
+		return false
+	}
+	for _, e := range entries {
+		if !Verify(e.pub, message, e.sig) {
+			return false
+		}
+	}
+	return true
+}
+
+// AddMulti adds every signature of a MultiEd25519 signature to the current
+// batch. If the key or signature is malformed, it adds an invalid entry, so
+// that the batch fails to verify. If the signatures do not all fit within
+// the limits set with SetLimits, it adds none of them, and Verify fails.
+func (v *BatchVerifier) AddMulti(publicKey, message, sig []byte) {
+	entries, ok := parseMulti(publicKey, sig)
+	if !ok {
+		v.dropIfFull(v.add(nil, message, sig, nil, false))
+		return
+	}
+	// Check the limits upfront, so that either all signatures are added or
+	// none are.
+	retained := 0
+	if v.retains() {
+		retained = len(message)
+	}
+	if (v.maxEntries > 0 && len(v.entries)+len(entries) > v.maxEntries) ||
+		(v.maxMemory > 0 && v.memory+len(entries)*(entryMemory+retained) > v.maxMemory) {
+		v.overflowed = true
+		return
+	}
+	for _, e := range entries {
+		v.Add(e.pub, message, e.sig)
+	}
+}
@@ -0,0 +1,118 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"testing"
+)
+
+// multiKey returns a MultiEd25519 key with n keys and the given threshold,
+// and a function signing with the keys at the given indices.
+func multiKey(t *testing.T, n, threshold int) ([]byte, func(msg []byte, signers ...int) []byte) {
+	var pub []byte
+	var privs []ed25519.PrivateKey
+	for i := 0; i < n; i++ {
+		p, priv, _ := ed25519.GenerateKey(nil)
+		pub = append(pub, p...)
+		privs = append(privs, priv)
+	}
+	pub = append(pub, byte(threshold))
+	sign := func(msg []byte, signers ...int) []byte {
+		var sig []byte
+		var bitmap [4]byte
+		for _, i := range signers {
+			sig = append(sig, ed25519.Sign(privs[i], msg)...)
+			bitmap[i/8] |= 0x80 >> (i % 8)
+		}
+		return append(sig, bitmap[:]...)
+	}
+	return pub, sign
+}
+
+func TestVerifyMulti(t *testing.T) {
+	msg := []byte("multisig transaction")
+	pub, sign := multiKey(t, 5, 3)
+
+	for _, c := range []struct {
+		signers []int
+		valid   bool
+	}{
+		{[]int{0, 1, 2}, true},
+		{[]int{1, 3, 4}, true},
+		{[]int{0, 1, 2, 3, 4}, true},
+		{[]int{0, 4}, false},
+		{nil, false},
+	} {
+		if got := VerifyMulti(pub, msg, sign(msg, c.signers...)); got != c.valid {
+			t.Errorf("signers %v: got %v, want %v", c.signers, got, c.valid)
+		}
+	}
+
+	sig := sign(msg, 0, 1, 2)
+	if VerifyMulti(pub, []byte("other"), sig) {
+		t.Error("multi-signature verified for the wrong message")
+	}
+
+	// Signatures in the wrong order do not match the bitmap.
+	swapped := append(append(append([]byte(nil), sig[64:128]...), sig[:64]...), sig[128:]...)
+	if VerifyMulti(pub, msg, swapped) {
+		t.Error("multi-signature with swapped signatures verified")
+	}
+
+	// A bit beyond the last key.
+	extra := append([]byte(nil), sig...)
+	extra[len(extra)-4] |= 0x04
+	if VerifyMulti(pub, msg, extra) {
+		t.Error("multi-signature with a bit set beyond the keys verified")
+	}
+
+	for _, threshold := range []byte{0, 6} {
+		bad := append([]byte(nil), pub...)
+		bad[len(bad)-1] = threshold
+		if VerifyMulti(bad, msg, sig) {
+			t.Errorf("multi-signature verified with threshold %d", threshold)
+		}
+	}
+	if VerifyMulti(pub[1:], msg, sig) || VerifyMulti(pub, msg, sig[1:]) {
+		t.Error("multi-signature with truncated encoding verified")
+	}
+}
+
+func TestBatchAddMulti(t *testing.T) {
+	msg := []byte("multisig transaction")
+	pub, sign := multiKey(t, MaxMultiKeys, 2)
+
+	v := NewBatchVerifier()
+	populateBatchVerifier(t, &v)
+	v.AddMulti(pub, msg, sign(msg, 0, 17, 31))
+	if !v.Verify() {
+		t.Error("failed batch verification")
+	}
+
+	v.AddMulti(pub, msg, sign(msg, 0))
+	if v.Verify() {
+		t.Error("batch verification should fail due to a multi-signature below threshold")
+	}
+}
+
+func TestBatchAddMultiLimits(t *testing.T) {
+	msg := []byte("multisig transaction")
+	pub, sign := multiKey(t, 4, 2)
+
+	v := NewBatchVerifier()
+	v.SetLimits(2, 0)
+	v.AddMulti(pub, msg, sign(msg, 0, 1, 2))
+	if len(v.entries) != 0 {
+		t.Errorf("a partial multi-signature of %d entries was added", len(v.entries))
+	}
+	v.AddMulti(pub, msg, sign(msg, 0, 1))
+	if v.Verify() {
+		t.Error("batch verified after dropping a multi-signature")
+	}
+
+	v = NewBatchVerifier()
+	v.SetLimits(2, 0)
+	v.AddMulti(pub, msg, sign(msg, 0, 1))
+	if !v.Verify() {
+		t.Error("multi-signature within the limits failed to verify")
+	}
+}
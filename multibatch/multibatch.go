@@ -0,0 +1,117 @@
+//go:build go1.24
+
+// Package multibatch batch-verifies signatures of several EdDSA schemes
+// together, for consensus systems accepting both Ed25519 and Ed448 keys.
+//
+// It is kept apart from package ed25519consensus so that users of Ed25519
+// alone do not depend on the ed448 package.
+package multibatch
+
+import (
+	"errors"
+
+	"github.com/hdevalence/ed25519consensus"
+	"github.com/hdevalence/ed25519consensus/ed448"
+)
+
+// ErrUnknownScheme is returned when adding a signature of an unknown scheme.
+var ErrUnknownScheme = errors.New("multibatch: unknown signature scheme")
+
+// Scheme identifies a signature scheme accepted by MultiBatchVerifier.
+type Scheme int
+
+const (
+	// SchemeEd25519 selects Ed25519, verified as by ed25519consensus.Verify.
+	SchemeEd25519 Scheme = iota
+	// SchemeEd448 selects Ed448, verified as by ed448.Verify.
+	SchemeEd448
+)
+
+// String returns the conventional name of the scheme.
+func (s Scheme) String() string {
+	switch s {
+	case SchemeEd25519:
+		return "ed25519"
+	case SchemeEd448:
+		return "ed448"
+	default:
+		return "unknown"
+	}
+}
+
+// MultiBatchVerifier accumulates signatures of several schemes with Add,
+// dispatching each to a batch verifier for its scheme, before checking them
+// all with Verify. This lets block processing loops handle transactions
+// signed with different schemes through a single batch.
+type MultiBatchVerifier struct {
+	ed25519 ed25519consensus.BatchVerifier
+	ed448   ed448.BatchVerifier
+
+	n   [2]int
+	bad bool
+}
+
+// NewMultiBatchVerifier creates an empty MultiBatchVerifier.
+func NewMultiBatchVerifier() MultiBatchVerifier {
+	return MultiBatchVerifier{
+		ed25519: ed25519consensus.NewBatchVerifier(),
+		ed448:   ed448.NewBatchVerifier(),
+	}
+}
+
+// Add adds a signature of the given scheme with an empty context string to
+// the batch, as by AddWithContext.
+func (v *MultiBatchVerifier) Add(scheme Scheme, publicKey, message, sig []byte) error {
+	return v.AddWithContext(scheme, publicKey, message, sig, "")
+}
+
+// AddWithContext adds a signature of the given scheme under a context string
+// to the batch. The context is the Ed25519ctx context for Ed25519, as in
+// BatchVerifier.AddWithContext, and the RFC 8032 context for Ed448.
+//
+// An unknown scheme returns ErrUnknownScheme, and an Ed25519 signature that
+// exceeds the limits of SetLimits returns ed25519consensus.ErrBatchFull. In
+// both cases the signature is not added and the batch fails to verify.
+func (v *MultiBatchVerifier) AddWithContext(scheme Scheme, publicKey, message, sig []byte, context string) error {
+	switch scheme {
+	case SchemeEd25519:
+		if err := v.ed25519.TryAddWithContext(publicKey, message, sig, context); err != nil {
+			v.bad = true
+			return err
+		}
+	case SchemeEd448:
+		v.ed448.AddWithContext(publicKey, message, sig, context)
+	default:
+		v.bad = true
+		return ErrUnknownScheme
+	}
+	v.n[scheme]++
+	return nil
+}
+
+// SetLimits bounds the Ed25519 part of the batch, as by
+// BatchVerifier.SetLimits.
+func (v *MultiBatchVerifier) SetLimits(maxEntries, maxMemory int) {
+	v.ed25519.SetLimits(maxEntries, maxMemory)
+}
+
+// Len returns the number of entries added to the batch.
+func (v *MultiBatchVerifier) Len() int {
+	return v.n[SchemeEd25519] + v.n[SchemeEd448]
+}
+
+// Verify checks all entries in the batch, returning true if all are valid
+// and false otherwise, without identifying the invalid ones. Calling Verify
+// on an empty batch returns false.
+func (v *MultiBatchVerifier) Verify() bool {
+	if v.bad || v.Len() == 0 {
+		return false
+	}
+	if v.n[SchemeEd25519] > 0 && !v.ed25519.Verify() {
+		return false
+	}
+	if v.n[SchemeEd448] > 0 && !v.ed448.Verify() {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+//go:build go1.24
+
+package multibatch
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/hdevalence/ed25519consensus"
+)
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+var (
+	// RFC 8032, Section 7.4, the blank message test.
+	multiEd448Pub = mustDecodeHex("5fd7449b59b461fd2ce787ec616ad46a1da1342485a70e1f8a0ea75d80e96778edf124769b46c7061bd6783df1e50f6cd1fa1abeafe8256180")
+	multiEd448Sig = mustDecodeHex("533a37f6bbe457251f023c0d88f976ae2dfb504a843e34d2074fd823d41a591f2b233f034f628281f2fd7a22ddd47d7828c59bd0a21bfd3980ff0d2028d4b18a9df63e006c5d1c2d345b925d8dc00b4104852db99ac5c7cdda8530a113a0f4dbb61149f05a7363268c71d95808ff2e652600")
+)
+
+func TestMultiBatchVerifier(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("multi")
+
+	v := NewMultiBatchVerifier()
+	if v.Verify() {
+		t.Error("empty batch accepted")
+	}
+	v.Add(SchemeEd25519, pub, msg, ed25519.Sign(priv, msg))
+	v.Add(SchemeEd448, multiEd448Pub, nil, multiEd448Sig)
+	if v.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", v.Len())
+	}
+	if !v.Verify() {
+		t.Fatal("valid batch rejected")
+	}
+
+	for _, scheme := range []Scheme{SchemeEd25519, SchemeEd448} {
+		v := NewMultiBatchVerifier()
+		v.Add(SchemeEd25519, pub, msg, ed25519.Sign(priv, msg))
+		switch scheme {
+		case SchemeEd25519:
+			v.Add(scheme, pub, []byte("other"), ed25519.Sign(priv, msg))
+		case SchemeEd448:
+			v.Add(scheme, multiEd448Pub, msg, multiEd448Sig)
+		}
+		if v.Verify() {
+			t.Errorf("batch with an invalid %v signature accepted", scheme)
+		}
+	}
+
+	v = NewMultiBatchVerifier()
+	v.Add(SchemeEd25519, pub, msg, ed25519.Sign(priv, msg))
+	if err := v.Add(Scheme(42), pub, msg, ed25519.Sign(priv, msg)); err != ErrUnknownScheme {
+		t.Errorf("Add with an unknown scheme returned %v", err)
+	}
+	if v.Verify() {
+		t.Error("batch with an unknown scheme accepted")
+	}
+}
+
+func TestMultiBatchVerifierLimits(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("multi")
+
+	v := NewMultiBatchVerifier()
+	v.SetLimits(1, 0)
+	if err := v.Add(SchemeEd25519, pub, msg, ed25519.Sign(priv, msg)); err != nil {
+		t.Fatal(err)
+	}
+	err := v.Add(SchemeEd25519, pub, msg, ed25519.Sign(priv, msg))
+	if !errors.Is(err, ed25519consensus.ErrBatchFull) {
+		t.Errorf("Add past the limit returned %v, want ErrBatchFull", err)
+	}
+	if v.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", v.Len())
+	}
+	if v.Verify() {
+		t.Error("batch with a dropped signature accepted")
+	}
+}
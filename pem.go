@@ -0,0 +1,58 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+)
+
+var (
+	errNoPEMBlock   = errors.New("ed25519consensus: no PEM data found")
+	errNotEd25519   = errors.New("ed25519consensus: key is not an Ed25519 key")
+	errPEMBlockType = errors.New("ed25519consensus: unexpected PEM block type")
+)
+
+// ParsePublicKeyPEM parses the first PEM block in data, which must be a
+// "PUBLIC KEY" block holding a PKIX-encoded Ed25519 public key.
+//
+// Like Verify, it does not require the key to be a canonical point encoding.
+func ParsePublicKeyPEM(data []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errNoPEMBlock
+	}
+	if block.Type != "PUBLIC KEY" {
+		return nil, errPEMBlockType
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, errNotEd25519
+	}
+	return pub, nil
+}
+
+// ParsePrivateKeyPEM parses the first PEM block in data, which must be a
+// "PRIVATE KEY" block holding a PKCS #8-encoded Ed25519 private key.
+func ParsePrivateKeyPEM(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errNoPEMBlock
+	}
+	if block.Type != "PRIVATE KEY" {
+		return nil, errPEMBlockType
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errNotEd25519
+	}
+	return priv, nil
+}
@@ -0,0 +1,51 @@
+package ed25519consensus
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+)
+
+func TestParsePEM(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+	privPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})
+
+	gotPub, err := ParsePublicKeyPEM(pubPEM)
+	if err != nil || !bytes.Equal(gotPub, pub) {
+		t.Errorf("ParsePublicKeyPEM: got %x, %v", gotPub, err)
+	}
+	gotPriv, err := ParsePrivateKeyPEM(privPEM)
+	if err != nil || !bytes.Equal(gotPriv, priv) {
+		t.Errorf("ParsePrivateKeyPEM: got %x, %v", gotPriv, err)
+	}
+
+	if _, err := ParsePublicKeyPEM(privPEM); err == nil {
+		t.Error("ParsePublicKeyPEM accepted a private key")
+	}
+	if _, err := ParsePrivateKeyPEM(pubPEM); err == nil {
+		t.Error("ParsePrivateKeyPEM accepted a public key")
+	}
+	if _, err := ParsePublicKeyPEM([]byte("not PEM")); err == nil {
+		t.Error("ParsePublicKeyPEM accepted non-PEM data")
+	}
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecDER, _ := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	if _, err := ParsePublicKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: ecDER})); err != errNotEd25519 {
+		t.Errorf("ParsePublicKeyPEM of an ECDSA key: got %v, want %v", err, errNotEd25519)
+	}
+}
@@ -0,0 +1,177 @@
+package ed25519consensus
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"errors"
+
+	"filippo.io/edwards25519"
+)
+
+// Variant is a set of Ed25519 variants, as defined by RFC 8032.
+type Variant uint
+
+const (
+	// VariantEd25519 is plain Ed25519, with no dom2 prefix.
+	VariantEd25519 Variant = 1 << iota
+	// VariantEd25519ctx is Ed25519ctx, with a non-empty context string.
+	VariantEd25519ctx
+	// VariantEd25519ph is Ed25519ph, which signs the SHA-512 hash of the
+	// message.
+	VariantEd25519ph
+)
+
+// Policy expresses the signature validity rules of a chain that differ from
+// ZIP215, so that they are stated once and enforced uniformly by
+// VerifyWithOptions, Policy.Verify and BatchVerifier.SetPolicy. The zero
+// Policy is ZIP215, as implemented by Verify.
+type Policy struct {
+	// Keys is the set of requirements on public keys, checked as by
+	// ValidatePublicKey.
+	Keys KeyPolicy
+
+	// RequireCanonicalR rejects signatures whose R is not a canonical point
+	// encoding.
+	RequireCanonicalR bool
+
+	// Cofactorless checks the equation [s]B = R + [k]A, as most other
+	// implementations do, instead of the cofactored equation of ZIP215.
+	// Cofactorless batch verification is not sound, so BatchVerifier then
+	// verifies each entry individually.
+	Cofactorless bool
+
+	// Variants is the set of allowed variants. Zero allows all of them.
+	Variants Variant
+}
+
+var (
+	// ErrVariantNotAllowed means a signature uses an Ed25519 variant that is
+	// not allowed by the Policy.
+	ErrVariantNotAllowed = errors.New("ed25519consensus: Ed25519 variant not allowed by policy")
+	// ErrInvalidOptions means the Options of VerifyWithOptions are not
+	// valid: the hash is neither zero nor SHA-512, the context is longer
+	// than 255 bytes, or an Ed25519ph message is not a SHA-512 digest.
+	ErrInvalidOptions = errors.New("ed25519consensus: invalid verification options")
+)
+
+// Options selects the variant verified by VerifyWithOptions, and the policy
+// applied to it.
+type Options struct {
+	// Hash is zero for Ed25519 and Ed25519ctx, or crypto.SHA512 for
+	// Ed25519ph, in which case the message is the SHA-512 hash of the
+	// signed message.
+	Hash crypto.Hash
+	// Context is the context string for Ed25519ctx and Ed25519ph. An empty
+	// context with a zero Hash selects plain Ed25519.
+	Context string
+
+	Policy Policy
+}
+
+// Verify reports whether sig is a valid plain Ed25519 signature of message
+// by publicKey under p.
+func (p Policy) Verify(publicKey ed25519.PublicKey, message, sig []byte) bool {
+	return VerifyWithOptions(publicKey, message, sig, &Options{Policy: p}) == nil
+}
+
+// allows reports whether the policy allows the variant selected by dom, a
+// dom2 prefix or nil for plain Ed25519.
+func (p *Policy) allows(dom []byte) bool {
+	return p.Variants == 0 || p.Variants&variantOf(dom) != 0
+}
+
+// variantOf returns the variant selected by dom, a dom2 prefix or nil for
+// plain Ed25519.
+func variantOf(dom []byte) Variant {
+	switch {
+	case dom == nil:
+		return VariantEd25519
+	case dom[len(domPrefix)] == 1:
+		return VariantEd25519ph
+	default:
+		return VariantEd25519ctx
+	}
+}
+
+// check returns the first violation of p by a public key and signature of
+// the right lengths, signing with the variant selected by dom, or nil.
+func (p *Policy) check(publicKey ed25519.PublicKey, sig, dom []byte) error {
+	if !p.allows(dom) {
+		return ErrVariantNotAllowed
+	}
+	if p.Keys != 0 {
+		if err := ValidatePublicKey(publicKey, p.Keys); err != nil {
+			return err
+		}
+	}
+	if p.RequireCanonicalR {
+		var R [32]byte
+		copy(R[:], sig[:32])
+		if !IsCanonicalPointEncoding(R) {
+			return ErrNonCanonicalR
+		}
+	}
+	return nil
+}
+
+// VerifyWithOptions checks that sig is a valid signature of message by
+// publicKey, in the variant and under the policy selected by opts, and
+// returns nil if it is. Otherwise it returns why: ErrInvalidOptions,
+// ErrInvalidKeyLength, ErrMalformedSignature, ErrInvalidKeyEncoding,
+// ErrVariantNotAllowed, an error of ValidatePublicKey, ErrNonCanonicalR, or
+// ErrInvalidSignature.
+//
+// With a zero Policy, it accepts exactly the signatures accepted by Verify,
+// VerifyWithContext or VerifyPH.
+func VerifyWithOptions(publicKey ed25519.PublicKey, message, sig []byte, opts *Options) error {
+	var dom []byte
+	switch {
+	case opts.Hash == crypto.SHA512:
+		if len(message) != sha512.Size {
+			return ErrInvalidOptions
+		}
+		dom = dom2(1, opts.Context)
+	case opts.Hash != 0:
+		return ErrInvalidOptions
+	case opts.Context != "":
+		dom = dom2(0, opts.Context)
+	}
+	if dom == nil && (opts.Hash != 0 || opts.Context != "") {
+		return ErrInvalidOptions
+	}
+
+	if len(publicKey) != ed25519.PublicKeySize {
+		return ErrInvalidKeyLength
+	}
+	if len(sig) != ed25519.SignatureSize || sig[63]&224 != 0 {
+		return ErrMalformedSignature
+	}
+
+	e := entry{dom: dom, cofactorless: opts.Policy.Cofactorless}
+	copy(e.pubkey[:], publicKey)
+	copy(e.signature[:], sig)
+	e.computeDigest(message)
+	err := opts.Policy.check(publicKey, sig, dom)
+	if err == nil {
+		err = e.check()
+	}
+	traceDigest(publicKey, sig, dom, &e.digest, &opts.Policy, err == nil)
+	return err
+}
+
+// SetPolicy makes the batch enforce p: the Add methods reject entries that
+// violate its requirements on keys, R or variants, as with SetKeyPolicy and
+// SetRequireCanonicalR, and Verify checks the cofactorless equation if it
+// is selected. Entries added with AddWithChallenge or as a Hashed Entry
+// count as plain Ed25519.
+func (v *BatchVerifier) SetPolicy(p Policy) {
+	v.policy = p
+}
+
+// verifyCofactorless checks [s]B - [k]A == R.
+func verifyCofactorless(A, R *edwards25519.Point, s, k *edwards25519.Scalar) bool {
+	minusA := new(edwards25519.Point).Negate(A)
+	P := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(k, minusA, s)
+	return P.Equal(R) == 1
+}
@@ -0,0 +1,80 @@
+package ed25519consensus
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/hdevalence/ed25519consensus/testvectors"
+)
+
+func TestPolicyZIP215(t *testing.T) {
+	testvectors.Run(t, Policy{}.Verify)
+	testvectors.Run(t, func(publicKey ed25519.PublicKey, message, signature []byte) bool {
+		v := NewBatchVerifier()
+		v.SetPolicy(Policy{})
+		v.Add(publicKey, message, signature)
+		v.Add(publicKey, message, signature)
+		return v.Verify()
+	})
+}
+
+func TestPolicyCofactorless(t *testing.T) {
+	p := Policy{Cofactorless: true}
+	for _, vec := range testvectors.All() {
+		want := Classify(vec.PublicKey, vec.Message, vec.Signature).Cofactorless
+		if got := p.Verify(vec.PublicKey, vec.Message, vec.Signature); got != want {
+			t.Errorf("%s: cofactorless Verify = %v, want %v", vec.Comment, got, want)
+		}
+
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		msg := []byte("valid")
+		v := NewBatchVerifier()
+		v.SetPolicy(p)
+		v.Add(pub, msg, ed25519.Sign(priv, msg))
+		v.Add(vec.PublicKey, vec.Message, vec.Signature)
+		if got := v.Verify(); got != want {
+			t.Errorf("%s: cofactorless batch = %v, want %v", vec.Comment, got, want)
+		}
+	}
+}
+
+func TestPolicyVariants(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("variants")
+	sig := ed25519.Sign(priv, msg)
+	pure := Policy{Variants: VariantEd25519}
+
+	if err := VerifyWithOptions(pub, msg, sig, &Options{Policy: pure}); err != nil {
+		t.Errorf("plain Ed25519 rejected: %v", err)
+	}
+	opts := &Options{Context: "ctx", Policy: pure}
+	if err := VerifyWithOptions(pub, msg, sig, opts); err != ErrVariantNotAllowed {
+		t.Errorf("Ed25519ctx under a plain policy: got %v", err)
+	}
+	opts.Policy = Policy{}
+	if err := VerifyWithOptions(pub, msg, sig, opts); err != ErrInvalidSignature {
+		t.Errorf("plain signature as Ed25519ctx: got %v", err)
+	}
+	if err := VerifyWithOptions(pub, msg, sig, &Options{Hash: crypto.SHA256}); err != ErrInvalidOptions {
+		t.Errorf("SHA-256 hash: got %v", err)
+	}
+	if err := VerifyWithOptions(pub, msg, sig, &Options{Hash: crypto.SHA512}); err != ErrInvalidOptions {
+		t.Errorf("Ed25519ph with a short digest: got %v", err)
+	}
+
+	v := NewBatchVerifier()
+	v.SetPolicy(pure)
+	var reasons []error
+	v.SetFailureCallback(func(_ int, _ ed25519.PublicKey, _, _ []byte, reason error) {
+		reasons = append(reasons, reason)
+	})
+	v.Add(pub, msg, sig)
+	v.AddWithContext(pub, msg, sig, "ctx")
+	if v.Verify() {
+		t.Error("batch with a disallowed variant accepted")
+	}
+	if len(reasons) != 1 || reasons[0] != ErrVariantNotAllowed {
+		t.Errorf("reported %v, want ErrVariantNotAllowed", reasons)
+	}
+}
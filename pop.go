@@ -0,0 +1,39 @@
+package ed25519consensus
+
+import "crypto/ed25519"
+
+// popDomain prefixes the message signed by a proof of possession, so that it
+// cannot be confused with, or replayed as, any protocol message.
+const popDomain = "ed25519consensus proof of possession v1"
+
+// popMessage returns the message signed by the proof of possession of
+// publicKey.
+func popMessage(publicKey []byte) []byte {
+	return append([]byte(popDomain), publicKey...)
+}
+
+// GeneratePoP returns a proof of possession of priv: a signature of a fixed,
+// domain-separated message binding the public key. Registration flows can
+// require it to check that a validator holds the private key of the public
+// key it registers. It panics if priv has the wrong length, like
+// ed25519.Sign.
+func GeneratePoP(priv ed25519.PrivateKey) []byte {
+	return ed25519.Sign(priv, popMessage(priv.Public().(ed25519.PublicKey)))
+}
+
+// VerifyPoP checks pop, as generated by GeneratePoP, with the rules of
+// Verify. It returns an error from ValidatePublicKey, ErrInvalidSignature,
+// or nil if pop proves possession of the private key of publicKey.
+//
+// Keys of small order are rejected with ErrSmallOrderKey, since signatures
+// under them, including proofs of possession, can be forged without any
+// private key.
+func VerifyPoP(publicKey ed25519.PublicKey, pop []byte) error {
+	if err := ValidatePublicKey(publicKey, RejectSmallOrderKey); err != nil {
+		return err
+	}
+	if !Verify(publicKey, popMessage(publicKey), pop) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestPoP(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	pop := GeneratePoP(priv)
+	if err := VerifyPoP(pub, pop); err != nil {
+		t.Errorf("valid proof: %v", err)
+	}
+
+	other, _, _ := ed25519.GenerateKey(nil)
+	if err := VerifyPoP(other, pop); err != ErrInvalidSignature {
+		t.Errorf("proof for another key: got %v", err)
+	}
+	if err := VerifyPoP(pub, ed25519.Sign(priv, []byte(popDomain))); err != ErrInvalidSignature {
+		t.Errorf("signature without the key: got %v", err)
+	}
+	if err := VerifyPoP(pub[:31], pop); err != ErrInvalidKeyLength {
+		t.Errorf("short key: got %v", err)
+	}
+
+	// With the identity as key and R = [s]B, anyone can forge a signature,
+	// which Verify accepts.
+	identity := make([]byte, 32)
+	identity[0] = 1
+	forged := make([]byte, 64)
+	forged[0] = 0x58
+	for i := 1; i < 32; i++ {
+		forged[i] = 0x66
+	}
+	forged[32] = 1
+	if !Verify(identity, popMessage(identity), forged) {
+		t.Fatal("forged signature under the identity rejected by Verify")
+	}
+	if err := VerifyPoP(identity, forged); err != ErrSmallOrderKey {
+		t.Errorf("small-order key: got %v", err)
+	}
+}
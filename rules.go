@@ -0,0 +1,83 @@
+package ed25519consensus
+
+// Rules describes the acceptance criteria of a verifier in a form that can
+// be serialized with encoding/json, so that protocol specifications and
+// implementations in other languages can assert that they agree with this
+// package by comparing descriptors rather than reading code.
+//
+// Every verifier requires signatures of 64 bytes, public keys of 32 bytes
+// that decode to a point, an R that decodes to a point, and the challenge
+// k = SHA-512(dom2 || R || A || M) computed over the encodings as given.
+// Rules records the criteria on which implementations differ.
+type Rules struct {
+	// Version is the frozen semantics the rules belong to, such as "v1",
+	// or empty for rules that are not a published version.
+	Version string `json:"version,omitempty"`
+
+	// Equation is "cofactored" for [8][s]B = [8]R + [8][k]A, or
+	// "cofactorless" for [s]B = R + [k]A.
+	Equation string `json:"equation"`
+	// CanonicalS is whether s must be reduced modulo the group order l.
+	// It implies that the top three bits of the signature are zero.
+	CanonicalS bool `json:"canonicalS"`
+
+	// NonCanonicalA and NonCanonicalR are whether A and R may be
+	// non-canonical point encodings.
+	NonCanonicalA bool `json:"nonCanonicalA"`
+	NonCanonicalR bool `json:"nonCanonicalR"`
+	// IdentityA is whether A may be the identity, SmallOrderA whether it
+	// may be another point of order dividing 8, and MixedOrderA whether it
+	// may be a point of larger order with a small-order component.
+	IdentityA   bool `json:"identityA"`
+	SmallOrderA bool `json:"smallOrderA"`
+	MixedOrderA bool `json:"mixedOrderA"`
+	// SmallOrderR is whether R may be a point of order dividing 8.
+	SmallOrderR bool `json:"smallOrderR"`
+
+	// Variants lists the accepted variants of RFC 8032, among "Ed25519",
+	// "Ed25519ctx" and "Ed25519ph".
+	Variants []string `json:"variants"`
+}
+
+// Rules returns the rules of version, and whether version is known.
+func (v SemanticsVersion) Rules() (Rules, bool) {
+	if _, ok := semantics[v]; !ok {
+		return Rules{}, false
+	}
+	// Every published version is ZIP215, the zero Policy.
+	r := Policy{}.Rules()
+	r.Version = v.String()
+	return r, true
+}
+
+// Rules returns the rules enforced under p by VerifyWithOptions,
+// Policy.Verify and a BatchVerifier with p set. Its Version is empty, even
+// for the zero Policy, whose rules are those of LatestSemantics.
+func (p Policy) Rules() Rules {
+	r := Rules{
+		Equation:      "cofactored",
+		CanonicalS:    true,
+		NonCanonicalA: p.Keys&RequireCanonicalKey == 0,
+		NonCanonicalR: !p.RequireCanonicalR,
+		IdentityA:     p.Keys&(RejectIdentityKey|RejectSmallOrderKey) == 0,
+		SmallOrderA:   p.Keys&(RejectSmallOrderKey|RequireTorsionFreeKey) == 0,
+		MixedOrderA:   p.Keys&RequireTorsionFreeKey == 0,
+		SmallOrderR:   true,
+	}
+	if p.Cofactorless {
+		r.Equation = "cofactorless"
+	}
+	for _, v := range []struct {
+		variant Variant
+		name    string
+	}{
+		{VariantEd25519, "Ed25519"},
+		{VariantEd25519ctx, "Ed25519ctx"},
+		{VariantEd25519ph, "Ed25519ph"},
+	} {
+		if p.Variants == 0 || p.Variants&v.variant != 0 {
+			r.Variants = append(r.Variants, v.name)
+		}
+	}
+	return r
+}
@@ -0,0 +1,73 @@
+package ed25519consensus
+
+import (
+	"encoding/json"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/hdevalence/ed25519consensus/testvectors"
+)
+
+func TestSemanticsRules(t *testing.T) {
+	r, ok := SemanticsV1.Rules()
+	if !ok {
+		t.Fatal("SemanticsV1 unknown")
+	}
+	got, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"version":"v1","equation":"cofactored","canonicalS":true,` +
+		`"nonCanonicalA":true,"nonCanonicalR":true,"identityA":true,"smallOrderA":true,"mixedOrderA":true,"smallOrderR":true,` +
+		`"variants":["Ed25519","Ed25519ctx","Ed25519ph"]}`
+	if string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if _, ok := SemanticsVersion(0).Rules(); ok {
+		t.Error("unknown version has rules")
+	}
+}
+
+// TestPolicyRules checks that the rules of each policy predict its
+// decisions on the ZIP215 and mixed-order vectors, which are all valid under
+// the zero Policy.
+func TestPolicyRules(t *testing.T) {
+	vectors := append(testvectors.ZIP215(), testvectors.MixedOrder()...)
+	for _, p := range []Policy{
+		{},
+		{Keys: RequireCanonicalKey},
+		{Keys: RejectIdentityKey},
+		{Keys: RejectSmallOrderKey},
+		{Keys: RequireTorsionFreeKey},
+		{RequireCanonicalR: true},
+		{Cofactorless: true},
+		{Keys: RequireCanonicalKey | RequireTorsionFreeKey, RequireCanonicalR: true, Cofactorless: true},
+	} {
+		r := p.Rules()
+		for _, v := range vectors {
+			c := Classify(v.PublicKey, v.Message, v.Signature)
+			A, _ := new(edwards25519.Point).SetBytes(v.PublicKey)
+			isIdentity := A.Equal(edwards25519.NewIdentityPoint()) == 1
+			want := (r.NonCanonicalA || !c.NonCanonicalA) &&
+				(r.NonCanonicalR || !c.NonCanonicalR) &&
+				(r.IdentityA || !isIdentity) &&
+				(r.SmallOrderA || !c.SmallOrderA || isIdentity) &&
+				(r.MixedOrderA || c.SmallOrderA || !HasSmallOrderComponent(v.PublicKey)) &&
+				(r.SmallOrderR || !c.SmallOrderR) &&
+				(r.Equation == "cofactored" || c.Cofactorless)
+			if got := p.Verify(v.PublicKey, v.Message, v.Signature); got != want {
+				t.Errorf("%+v: %s: got %v, rules %+v predict %v", p, v.Comment, got, r, want)
+			}
+		}
+	}
+}
+
+func TestPolicyRulesVariants(t *testing.T) {
+	r := Policy{Variants: VariantEd25519 | VariantEd25519ph}.Rules()
+	if len(r.Variants) != 2 || r.Variants[0] != "Ed25519" || r.Variants[1] != "Ed25519ph" {
+		t.Errorf("got variants %v", r.Variants)
+	}
+	if r.Version != "" {
+		t.Errorf("policy rules have version %q", r.Version)
+	}
+}
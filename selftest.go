@@ -0,0 +1,61 @@
+package ed25519consensus
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+)
+
+// Known-answer test vectors for SelfTest. The first is RFC 8032, Section 7.1,
+// Test 1. The second is valid only under the cofactored ZIP215 equation: its
+// public key has a torsion component of order 8.
+const (
+	kat1Seed      = "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"
+	kat1PublicKey = "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
+	kat1Signature = "e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b"
+
+	kat2PublicKey = "02cc69f258660f0559d8028d689fd01d888c9049d211d751fc3013c7411e0e7e"
+	kat2Message   = "ed25519consensus mixed order"
+	kat2Signature = "26508923c885c1f2b015e826992eb6ead6f7621e28e75d32eff3b3d1aaaf270e6680be7bfc4e328f20add32021b7ebd4585509d753ae856d646d5f3b6660cf0f"
+)
+
+// SelfTest runs known-answer tests of signing, single verification and
+// batch verification against embedded vectors, and returns an error if any
+// of them fails. It is meant to be called at startup by deployments that
+// require a power-on self-test, and takes about a millisecond.
+func SelfTest() error {
+	seed, _ := hex.DecodeString(kat1Seed)
+	pub1, _ := hex.DecodeString(kat1PublicKey)
+	sig1, _ := hex.DecodeString(kat1Signature)
+	pub2, _ := hex.DecodeString(kat2PublicKey)
+	sig2, _ := hex.DecodeString(kat2Signature)
+	msg2 := []byte(kat2Message)
+
+	k := NewExpandedPrivateKey(ed25519.NewKeyFromSeed(seed))
+	if !bytes.Equal(k.Public(), pub1) {
+		return errors.New("ed25519consensus: self-test failed: public key derivation")
+	}
+	if sig := k.Sign(nil); !bytes.Equal(sig, sig1) {
+		return errors.New("ed25519consensus: self-test failed: signing")
+	}
+
+	// Corrupt the last byte of s, keeping it reduced.
+	bad := append([]byte(nil), sig1...)
+	bad[62] ^= 1
+	if !Verify(pub1, nil, sig1) || !Verify(pub2, msg2, sig2) || Verify(pub1, nil, bad) {
+		return errors.New("ed25519consensus: self-test failed: verification")
+	}
+
+	v := NewBatchVerifier()
+	v.Add(pub1, nil, sig1)
+	v.Add(pub2, msg2, sig2)
+	if !v.Verify() {
+		return errors.New("ed25519consensus: self-test failed: batch verification")
+	}
+	v.Add(pub1, nil, bad)
+	if v.Verify() {
+		return errors.New("ed25519consensus: self-test failed: batch verification")
+	}
+	return nil
+}
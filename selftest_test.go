@@ -0,0 +1,20 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The embedded vectors agree with crypto/ed25519.
+	seed, _ := hex.DecodeString(kat1Seed)
+	priv := ed25519.NewKeyFromSeed(seed)
+	if got := hex.EncodeToString(ed25519.Sign(priv, nil)); got != kat1Signature {
+		t.Errorf("crypto/ed25519 signature %s, want %s", got, kat1Signature)
+	}
+}
@@ -0,0 +1,69 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"fmt"
+)
+
+// SemanticsVersion identifies a frozen set of signature validity rules.
+//
+// Once a version is published, the signatures it accepts never change, even
+// if the rules of Verify evolve or the edwards25519 dependency is updated:
+// TestSemanticsFrozen pins the decisions of every version over a corpus of
+// edge cases. Chains that must never fork over signature validity should
+// pin a version with VerifyVersion or the function of that version, such as
+// VerifyV1, rather than call Verify.
+type SemanticsVersion int
+
+const (
+	// SemanticsV1 is ZIP215, as implemented by Verify since v1.0.0: any
+	// decodable encoding of A and R, canonical s, and the cofactored
+	// equation [8][s]B = [8]R + [8][k]A.
+	SemanticsV1 SemanticsVersion = 1
+)
+
+// String returns the name of the version, such as "v1".
+func (v SemanticsVersion) String() string {
+	return fmt.Sprintf("v%d", int(v))
+}
+
+// semantics is the registry of frozen versions.
+var semantics = map[SemanticsVersion]func(ed25519.PublicKey, []byte, []byte) bool{
+	SemanticsV1: VerifyV1,
+}
+
+// LatestSemantics is the version currently implemented by Verify.
+const LatestSemantics = SemanticsV1
+
+// VerifyVersion reports whether sig is a valid signature of message by
+// publicKey under the rules of version, and whether version is known. An
+// unknown version accepts nothing.
+func VerifyVersion(version SemanticsVersion, publicKey ed25519.PublicKey, message, sig []byte) (valid, known bool) {
+	verify, ok := semantics[version]
+	if !ok {
+		return false, false
+	}
+	return verify(publicKey, message, sig), true
+}
+
+// VerifyV1 reports whether sig is a valid signature of message by publicKey
+// under SemanticsV1. Unlike Verify, whose rules follow LatestSemantics, its
+// decisions are frozen.
+func VerifyV1(publicKey ed25519.PublicKey, message, sig []byte) bool {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return false
+	}
+	if len(sig) != ed25519.SignatureSize || sig[63]&224 != 0 {
+		return false
+	}
+
+	h := sha512.New()
+	h.Write(sig[:32])
+	h.Write(publicKey)
+	h.Write(message)
+	var digest [64]byte
+	h.Sum(digest[:0])
+
+	return verifyDigest(publicKey, sig, &digest, false)
+}
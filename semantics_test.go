@@ -0,0 +1,89 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/hdevalence/ed25519consensus/testvectors"
+)
+
+// semanticsCorpus returns edge-case (public key, message, signature) triples
+// whose decisions pin the frozen semantics: every small-order encoding as A
+// and R, combined with boundary values of s, plus the ZIP215 vectors.
+func semanticsCorpus() [][3][]byte {
+	var encodings [][32]byte
+	for _, enc := range SmallOrderEncodings() {
+		encodings = append(encodings, enc)
+	}
+	for _, enc := range NonCanonicalSmallOrderEncodings() {
+		encodings = append(encodings, enc)
+	}
+	priv := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	var pub [32]byte
+	copy(pub[:], priv.Public().(ed25519.PublicKey))
+	encodings = append(encodings, pub)
+
+	lMinusOne := scalarFromInt(-1).Bytes()
+	l := append([]byte(nil), lMinusOne...)
+	l[0]++
+	highBit := make([]byte, 32)
+	highBit[31] = 0x80
+	scalars := [][]byte{make([]byte, 32), scalarFromInt(1).Bytes(), lMinusOne, l, highBit}
+
+	msg := []byte("frozen semantics")
+	var corpus [][3][]byte
+	for _, A := range encodings {
+		for _, R := range encodings {
+			for _, s := range scalars {
+				sig := append(append([]byte(nil), R[:]...), s...)
+				corpus = append(corpus, [3][]byte{append([]byte(nil), A[:]...), msg, sig})
+			}
+		}
+	}
+	corpus = append(corpus, [3][]byte{pub[:], msg, ed25519.Sign(priv, msg)})
+	for _, vec := range testvectors.All() {
+		corpus = append(corpus, [3][]byte{vec.PublicKey, vec.Message, vec.Signature})
+	}
+	return corpus
+}
+
+// frozenDecisions is the SHA-256 hash of the decisions of each version over
+// semanticsCorpus. It must never change.
+var frozenDecisions = map[SemanticsVersion]string{
+	SemanticsV1: "6216b43d244d999a44da4d01a123bb1b9e82a51059d37135b57cd5bbc2c298cc",
+}
+
+func TestSemanticsFrozen(t *testing.T) {
+	corpus := semanticsCorpus()
+	for version, want := range frozenDecisions {
+		h := sha256.New()
+		for _, c := range corpus {
+			valid, known := VerifyVersion(version, c[0], c[1], c[2])
+			if !known {
+				t.Fatalf("%v: unknown version", version)
+			}
+			if valid {
+				h.Write([]byte{1})
+			} else {
+				h.Write([]byte{0})
+			}
+		}
+		if got := hex.EncodeToString(h.Sum(nil)); got != want {
+			t.Errorf("%v: decisions hash to %s, want %s", version, got, want)
+		}
+	}
+}
+
+func TestLatestSemantics(t *testing.T) {
+	for _, c := range semanticsCorpus() {
+		want, _ := VerifyVersion(LatestSemantics, c[0], c[1], c[2])
+		if got := Verify(c[0], c[1], c[2]); got != want {
+			t.Errorf("Verify(%x, %x) = %v, but %v says %v", c[0], c[2], got, LatestSemantics, want)
+		}
+	}
+	if valid, known := VerifyVersion(0, nil, nil, nil); valid || known {
+		t.Error("unknown version accepted")
+	}
+}
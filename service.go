@@ -0,0 +1,165 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// ErrServiceClosed is reported for signatures submitted to a
+// VerificationService after Close.
+var ErrServiceClosed = errors.New("ed25519consensus: verification service closed")
+
+// Result is the outcome of a signature submitted to a VerificationService.
+type Result struct {
+	// Valid reports whether the signature is valid, as by Verify.
+	Valid bool
+	// Err is ErrServiceClosed if the signature was not verified because the
+	// service was closed, and nil otherwise.
+	Err error
+}
+
+// VerificationService verifies signatures submitted concurrently by many
+// goroutines, transparently grouping them into batches. A batch is verified
+// once it reaches the maximum size or once its oldest signature has waited
+// for the maximum delay, whichever comes first. If a batch fails, its
+// entries are verified individually, so every submission gets its own
+// result.
+type VerificationService struct {
+	maxBatch int
+	maxDelay time.Duration
+
+	mu       sync.RWMutex
+	closed   bool
+	requests chan serviceRequest
+	batches  chan []serviceRequest
+	wg       sync.WaitGroup
+}
+
+type serviceRequest struct {
+	publicKey    ed25519.PublicKey
+	message, sig []byte
+	result       chan<- Result
+}
+
+// NewVerificationService starts a VerificationService with the given number
+// of workers, each verifying one batch at a time, which groups at most
+// maxBatch signatures per batch and delays none by more than maxDelay. A
+// non-positive workers selects runtime.GOMAXPROCS(0), and a non-positive
+// maxBatch selects 64.
+//
+// The service must be stopped with Close to release its goroutines.
+func NewVerificationService(workers, maxBatch int, maxDelay time.Duration) *VerificationService {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if maxBatch < 1 {
+		maxBatch = 64
+	}
+	s := &VerificationService{
+		maxBatch: maxBatch,
+		maxDelay: maxDelay,
+		requests: make(chan serviceRequest, maxBatch),
+		batches:  make(chan []serviceRequest, workers),
+	}
+	s.wg.Add(1 + workers)
+	go s.collect()
+	for i := 0; i < workers; i++ {
+		go s.work()
+	}
+	return s
+}
+
+// Submit queues a signature for verification and returns a channel that
+// receives its result. The caller must not modify the arguments until the
+// result has been received.
+func (s *VerificationService) Submit(publicKey ed25519.PublicKey, message, sig []byte) <-chan Result {
+	result := make(chan Result, 1)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		result <- Result{Err: ErrServiceClosed}
+		return result
+	}
+	s.requests <- serviceRequest{publicKey, message, sig, result}
+	return result
+}
+
+// Close verifies the signatures already submitted, delivers their results,
+// and stops the service. Signatures submitted after Close fail with
+// ErrServiceClosed.
+func (s *VerificationService) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.requests)
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// collect groups requests into batches for the workers.
+func (s *VerificationService) collect() {
+	defer s.wg.Done()
+	defer close(s.batches)
+
+	var pending []serviceRequest
+	var timer *time.Timer
+	var timeout <-chan time.Time
+	flush := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, timeout = nil, nil
+		}
+		if len(pending) > 0 {
+			s.batches <- pending
+			pending = nil
+		}
+	}
+
+	for {
+		select {
+		case r, ok := <-s.requests:
+			if !ok {
+				flush()
+				return
+			}
+			pending = append(pending, r)
+			if len(pending) >= s.maxBatch {
+				flush()
+			} else if timer == nil {
+				timer = time.NewTimer(s.maxDelay)
+				timeout = timer.C
+			}
+		case <-timeout:
+			flush()
+		}
+	}
+}
+
+// work verifies batches until the service is closed.
+func (s *VerificationService) work() {
+	defer s.wg.Done()
+
+	v := NewPreallocatedBatchVerifier(s.maxBatch)
+	for batch := range s.batches {
+		v.Reset()
+		for _, r := range batch {
+			v.Add(r.publicKey, r.message, r.sig)
+		}
+		if v.Verify() {
+			for _, r := range batch {
+				r.result <- Result{Valid: true}
+			}
+			continue
+		}
+		for _, r := range batch {
+			r.result <- Result{Valid: Verify(r.publicKey, r.message, r.sig)}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestVerificationService(t *testing.T) {
+	s := NewVerificationService(2, 8, time.Millisecond)
+	defer s.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pub, priv, _ := ed25519.GenerateKey(nil)
+			msg := []byte(fmt.Sprintf("message %d", i))
+			sig := ed25519.Sign(priv, msg)
+			valid := i%7 != 0
+			if !valid {
+				msg = []byte("tampered")
+			}
+			if r := <-s.Submit(pub, msg, sig); r.Valid != valid || r.Err != nil {
+				t.Errorf("submission %d: got %+v, want valid %v", i, r, valid)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestVerificationServiceDeadline(t *testing.T) {
+	// A single submission never fills the batch, so it must be flushed by
+	// the deadline.
+	s := NewVerificationService(1, 1000, 10*time.Millisecond)
+	defer s.Close()
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("lonely")
+	select {
+	case r := <-s.Submit(pub, msg, ed25519.Sign(priv, msg)):
+		if !r.Valid {
+			t.Error("valid signature rejected")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("submission was not flushed by the deadline")
+	}
+}
+
+func TestVerificationServiceClose(t *testing.T) {
+	s := NewVerificationService(1, 1000, time.Hour)
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("pending at close")
+	pending := s.Submit(pub, msg, ed25519.Sign(priv, msg))
+	s.Close()
+	if r := <-pending; !r.Valid || r.Err != nil {
+		t.Errorf("pending submission: got %+v", r)
+	}
+
+	if r := <-s.Submit(pub, msg, ed25519.Sign(priv, msg)); r.Err != ErrServiceClosed {
+		t.Errorf("submission after Close: got %+v", r)
+	}
+	s.Close()
+}
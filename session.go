@@ -0,0 +1,116 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+
+	"filippo.io/edwards25519"
+)
+
+// KeyHandle refers to a public key registered with a Session.
+type KeyHandle uint32
+
+// Session holds a validator set whose public keys are registered once, so
+// that the signatures of thousands of blocks can be verified against the
+// same keys without validating and decompressing them each time.
+//
+// A Session is safe for concurrent use by Verify and by batches once all keys
+// are registered, but RegisterKey must not be called concurrently with any
+// other method.
+type Session struct {
+	policy KeyPolicy
+	keys   []sessionKey
+}
+
+// sessionKey is a registered public key and its decompressed point.
+type sessionKey struct {
+	encoding [ed25519.PublicKeySize]byte
+	A        edwards25519.Point
+}
+
+// NewSession creates an empty Session, whose keys must satisfy policy.
+func NewSession(policy KeyPolicy) *Session {
+	return &Session{policy: policy}
+}
+
+// RegisterKey validates publicKey as by ValidatePublicKey with the policy
+// of the Session, and returns a handle to refer to it.
+func (s *Session) RegisterKey(publicKey ed25519.PublicKey) (KeyHandle, error) {
+	if err := ValidatePublicKey(publicKey, s.policy); err != nil {
+		return 0, err
+	}
+	var k sessionKey
+	copy(k.encoding[:], publicKey)
+	// ZIP215: this works because SetBytes does not check that encodings are canonical.
+	if _, err := k.A.SetBytes(publicKey); err != nil {
+		return 0, ErrInvalidKeyEncoding
+	}
+	s.keys = append(s.keys, k)
+	return KeyHandle(len(s.keys) - 1), nil
+}
+
+// PublicKey returns the public key registered as h, or nil if h is unknown.
+func (s *Session) PublicKey(h KeyHandle) ed25519.PublicKey {
+	k := s.key(h)
+	if k == nil {
+		return nil
+	}
+	return append(ed25519.PublicKey(nil), k.encoding[:]...)
+}
+
+func (s *Session) key(h KeyHandle) *sessionKey {
+	if int(h) >= len(s.keys) {
+		return nil
+	}
+	return &s.keys[h]
+}
+
+// Verify is like the package-level Verify, for the public key registered as
+// h. It returns false if h is unknown.
+func (s *Session) Verify(h KeyHandle, message, sig []byte) bool {
+	k := s.key(h)
+	if k == nil {
+		return false
+	}
+	if len(sig) != ed25519.SignatureSize || sig[63]&224 != 0 {
+		return false
+	}
+	var e entry
+	e.pubkey = k.encoding
+	copy(e.signature[:], sig)
+	e.computeDigest(message)
+
+	kReduced, err := new(edwards25519.Scalar).SetUniformBytes(e.digest[:])
+	if err != nil {
+		return false
+	}
+	// ZIP215: this works because SetBytes does not check that encodings are canonical.
+	R, err := new(edwards25519.Point).SetBytes(sig[:32])
+	if err != nil {
+		return false
+	}
+	sc, err := new(edwards25519.Scalar).SetCanonicalBytes(sig[32:])
+	if err != nil {
+		return false
+	}
+	return verifyEquation(&k.A, R, sc, kReduced, false)
+}
+
+// AddSession adds a (message, sig) pair by the public key registered as h in
+// s to the batch, as by Add. Verify then uses the decompressed key of the
+// Session. An unknown h adds an invalid entry, which makes Verify fail. The
+// Session must outlive the batch.
+func (v *BatchVerifier) AddSession(s *Session, h KeyHandle, message, sig []byte) {
+	k := s.key(h)
+	if k == nil {
+		v.dropIfFull(v.add(nil, message, sig, nil, false))
+		return
+	}
+	n := len(v.entries)
+	if err := v.add(k.encoding[:], message, sig, nil, true); err != nil {
+		v.dropIfFull(err)
+		return
+	}
+	if len(v.entries) > n { // not skipped by the cache
+		v.entries[n].key = &k.A
+	}
+}
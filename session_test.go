@@ -0,0 +1,65 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"testing"
+)
+
+func TestSession(t *testing.T) {
+	s := NewSession(RejectSmallOrderKey)
+
+	var privs []ed25519.PrivateKey
+	var handles []KeyHandle
+	for i := 0; i < 10; i++ {
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		h, err := s.RegisterKey(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.PublicKey(h); string(got) != string(pub) {
+			t.Errorf("PublicKey(%d) = %x, want %x", h, got, pub)
+		}
+		privs = append(privs, priv)
+		handles = append(handles, h)
+	}
+	if _, err := s.RegisterKey(make([]byte, 31)); err != ErrInvalidKeyLength {
+		t.Errorf("RegisterKey of a short key: got %v", err)
+	}
+	identity := make([]byte, 32)
+	identity[0] = 1
+	if _, err := s.RegisterKey(identity); err != ErrSmallOrderKey {
+		t.Errorf("RegisterKey of the identity: got %v", err)
+	}
+
+	for round := 0; round < 3; round++ {
+		msg := []byte(fmt.Sprintf("block %d", round))
+		v := NewBatchVerifier()
+		for i, h := range handles {
+			sig := ed25519.Sign(privs[i], msg)
+			if !s.Verify(h, msg, sig) {
+				t.Errorf("round %d: signature %d rejected", round, i)
+			}
+			if s.Verify(h, []byte("other"), sig) {
+				t.Errorf("round %d: signature %d accepted for another message", round, i)
+			}
+			v.AddSession(s, h, msg, sig)
+		}
+		if !v.Verify() {
+			t.Errorf("round %d: valid batch rejected", round)
+		}
+		v.AddSession(s, handles[0], []byte("other"), ed25519.Sign(privs[0], msg))
+		if v.Verify() {
+			t.Errorf("round %d: batch with an invalid signature accepted", round)
+		}
+	}
+
+	if s.Verify(KeyHandle(len(handles)), nil, make([]byte, 64)) {
+		t.Error("unknown handle accepted")
+	}
+	v := NewBatchVerifier()
+	v.AddSession(s, KeyHandle(len(handles)), nil, make([]byte, 64))
+	if v.Verify() {
+		t.Error("batch with an unknown handle accepted")
+	}
+}
@@ -0,0 +1,96 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"io"
+)
+
+// ErrSignatureFault is returned by SignWithOptions when the signature it
+// produced fails to verify, which only happens if the computation was
+// faulty, for example because of a glitch or memory corruption. The
+// signature must be discarded, as faulty signatures can leak the key.
+var ErrSignatureFault = errors.New("ed25519consensus: produced signature failed to verify")
+
+// hedgeSize is the number of random bytes mixed into a hedged nonce.
+const hedgeSize = 32
+
+// SignOptions configures SignWithOptions. The zero value, like a nil
+// *SignOptions, selects deterministic RFC 8032 signing, as by ed25519.Sign.
+type SignOptions struct {
+	// Rand, if not nil, is read for 32 bytes of noise, such as from
+	// crypto/rand.Reader. The nonce is then the SHA-512 hash of the noise,
+	// padded with zeros to a full block, the secret nonce prefix and the
+	// message, as in draft-irtf-cfrg-det-sigs-with-noise. Such hedged
+	// signatures are still standard Ed25519 signatures, verified by any
+	// implementation, but signing the same message twice yields different
+	// signatures. This defeats fault attacks that glitch one of two
+	// signatures of the same message to recover the key. Since the prefix
+	// stays secret and the padded noise cannot be mistaken for a message,
+	// a broken Rand is no worse than deterministic signing: a constant
+	// noise never reproduces the nonce of a deterministic signature.
+	Rand io.Reader
+
+	// VerifyAfterSign makes SignWithOptions verify the signature with the
+	// rules of Verify before returning it, and return ErrSignatureFault
+	// instead if it is invalid. This is a cheap countermeasure against
+	// faults compared to the cost of leaking a key, but it roughly triples
+	// the cost of signing.
+	VerifyAfterSign bool
+}
+
+// SignWithOptions returns the signature of message by priv, configured by
+// opts, which may be nil. It returns an error if reading opts.Rand fails, or
+// ErrSignatureFault as requested by opts.VerifyAfterSign. It panics if priv
+// does not have the length of an ed25519.PrivateKey.
+func SignWithOptions(priv ed25519.PrivateKey, message []byte, opts *SignOptions) ([]byte, error) {
+	if len(priv) != ed25519.PrivateKeySize {
+		panic("ed25519consensus: bad private key length")
+	}
+	var noise [hedgeSize]byte
+	n, err := opts.noise(&noise)
+	if err != nil {
+		return nil, err
+	}
+	sig := appendSignPrivate(make([]byte, 0, ed25519.SignatureSize), priv, noise[:n], message)
+	Zeroize(noise[:])
+	return opts.check(priv[32:], message, sig)
+}
+
+// SignWithOptions is like the package-level SignWithOptions, for k.
+func (k *ExpandedPrivateKey) SignWithOptions(message []byte, opts *SignOptions) ([]byte, error) {
+	var noise [hedgeSize]byte
+	n, err := opts.noise(&noise)
+	if err != nil {
+		return nil, err
+	}
+	sig := appendSignExpanded(make([]byte, 0, ed25519.SignatureSize), &k.s, k.prefix[:], noise[:n], k.public[:], message)
+	Zeroize(noise[:])
+	return opts.check(k.public[:], message, sig)
+}
+
+// noise fills buf from opts.Rand for hedged signing, and returns the number
+// of bytes to hash into the nonce, which is zero for deterministic signing.
+func (opts *SignOptions) noise(buf *[hedgeSize]byte) (int, error) {
+	if opts == nil || opts.Rand == nil {
+		return 0, nil
+	}
+	if _, err := io.ReadFull(opts.Rand, buf[:]); err != nil {
+		return 0, err
+	}
+	return len(buf), nil
+}
+
+// check returns sig, or ErrSignatureFault if opts.VerifyAfterSign is set
+// and sig is not a valid signature of message by publicKey. The check does
+// not report to the tracer or the metrics collector, since it is not a
+// verification requested by the caller.
+func (opts *SignOptions) check(publicKey, message, sig []byte) ([]byte, error) {
+	if opts == nil || !opts.VerifyAfterSign {
+		return sig, nil
+	}
+	if !(*PublicKey)(publicKey).Verify(message, (*Signature)(sig)) {
+		return nil, ErrSignatureFault
+	}
+	return sig, nil
+}
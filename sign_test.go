@@ -0,0 +1,89 @@
+package ed25519consensus
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"testing/iotest"
+)
+
+func TestSignWithOptions(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	k := NewExpandedPrivateKey(priv)
+	msg := []byte("hedged")
+	want := ed25519.Sign(priv, msg)
+
+	for _, opts := range []*SignOptions{nil, {}} {
+		if sig, err := SignWithOptions(priv, msg, opts); err != nil || !bytes.Equal(sig, want) {
+			t.Errorf("deterministic: got %x, %v, want %x", sig, err, want)
+		}
+		if sig, err := k.SignWithOptions(msg, opts); err != nil || !bytes.Equal(sig, want) {
+			t.Errorf("deterministic expanded: got %x, %v, want %x", sig, err, want)
+		}
+	}
+
+	hedged := &SignOptions{Rand: rand.Reader}
+	sig1, err := SignWithOptions(priv, msg, hedged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, err := k.SignWithOptions(msg, hedged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(sig1, sig2) || bytes.Equal(sig1, want) {
+		t.Error("hedged signatures repeat")
+	}
+	for _, sig := range [][]byte{sig1, sig2} {
+		if !Verify(pub, msg, sig) || !ed25519.Verify(pub, msg, sig) {
+			t.Errorf("hedged signature %x failed to verify", sig)
+		}
+	}
+
+	// The same randomness gives the same signature.
+	fixed := bytes.Repeat([]byte{7}, 64)
+	a, _ := SignWithOptions(priv, msg, &SignOptions{Rand: bytes.NewReader(fixed)})
+	b, _ := k.SignWithOptions(msg, &SignOptions{Rand: bytes.NewReader(fixed)})
+	if !bytes.Equal(a, b) {
+		t.Error("SignWithOptions and ExpandedPrivateKey.SignWithOptions differ")
+	}
+
+	// A constant Rand does not reproduce the nonce of the deterministic
+	// signature of the noise followed by the message.
+	prefixed := ed25519.Sign(priv, append(fixed[:hedgeSize:hedgeSize], msg...))
+	if !Verify(pub, msg, a) || bytes.Equal(a[:32], prefixed[:32]) || bytes.Equal(a[:32], want[:32]) {
+		t.Errorf("hedged signature %x with fixed noise reuses a deterministic nonce", a)
+	}
+
+	if _, err := SignWithOptions(priv, msg, &SignOptions{Rand: iotest.ErrReader(iotest.ErrTimeout)}); err != iotest.ErrTimeout {
+		t.Errorf("failing Rand: got %v", err)
+	}
+}
+
+func TestVerifyAfterSign(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("checked")
+	opts := &SignOptions{VerifyAfterSign: true}
+	if sig, err := SignWithOptions(priv, msg, opts); err != nil || !bytes.Equal(sig, ed25519.Sign(priv, msg)) {
+		t.Errorf("got %x, %v", sig, err)
+	}
+	opts.Rand = rand.Reader
+	if _, err := NewExpandedPrivateKey(priv).SignWithOptions(msg, opts); err != nil {
+		t.Errorf("hedged: got %v", err)
+	}
+
+	// A corrupted copy of the public key makes the signature invalid.
+	priv[40] ^= 1
+	if sig, err := SignWithOptions(priv, msg, opts); err != ErrSignatureFault || sig != nil {
+		t.Errorf("faulty key: got %x, %v", sig, err)
+	}
+	k := NewExpandedPrivateKey(priv)
+	k.s = *scalarFromInt(1)
+	if _, err := k.SignWithOptions(msg, opts); err != ErrSignatureFault {
+		t.Errorf("faulty expanded key: got %v", err)
+	}
+	if _, err := k.SignWithOptions(msg, nil); err != nil {
+		t.Errorf("without the check: got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+
+	"filippo.io/edwards25519"
+)
+
+// SignerBatchVerifier batch-verifies many signatures by a single public key,
+// such as a light client replaying the history of one validator.
+//
+// The public key is decoded once by NewSignerBatchVerifier, and since every
+// signature shares it, the batch equation folds all the [z_i * k_i]A terms
+// into a single term, so Verify performs a multiscalar multiplication over
+// n+2 points instead of 2n+1.
+type SignerBatchVerifier struct {
+	publicKey [32]byte
+	A         edwards25519.Point
+	bad       bool // the public key or a signature was malformed
+	entries   []signerEntry
+}
+
+// signerEntry is a signature with its challenge.
+type signerEntry struct {
+	R edwards25519.Point
+	s edwards25519.Scalar
+	k edwards25519.Scalar
+}
+
+// NewSignerBatchVerifier creates an empty SignerBatchVerifier for signatures
+// by publicKey. If publicKey is malformed, the batch never verifies.
+func NewSignerBatchVerifier(publicKey ed25519.PublicKey) *SignerBatchVerifier {
+	v := new(SignerBatchVerifier)
+	if len(publicKey) != ed25519.PublicKeySize {
+		v.bad = true
+		return v
+	}
+	copy(v.publicKey[:], publicKey)
+	// ZIP215: this works because SetBytes does not check that encodings are canonical.
+	if _, err := v.A.SetBytes(publicKey); err != nil {
+		v.bad = true
+	}
+	return v
+}
+
+// Add adds a (message, sig) pair to the batch. The signature is decoded and
+// the challenge computed immediately, so no reference to either is retained.
+// A malformed signature makes the batch fail.
+func (v *SignerBatchVerifier) Add(message, sig []byte) {
+	if v.bad {
+		return
+	}
+	if len(sig) != ed25519.SignatureSize || sig[63]&224 != 0 {
+		v.bad = true
+		return
+	}
+	var e signerEntry
+	// ZIP215: this works because SetBytes does not check that encodings are canonical.
+	if _, err := e.R.SetBytes(sig[:32]); err != nil {
+		v.bad = true
+		return
+	}
+	if _, err := e.s.SetCanonicalBytes(sig[32:]); err != nil {
+		v.bad = true
+		return
+	}
+	e.k.Set(ComputeChallenge(sig[:32], v.publicKey[:], message))
+	v.entries = append(v.entries, e)
+}
+
+// Len returns the number of signatures added to the batch.
+func (v *SignerBatchVerifier) Len() int {
+	return len(v.entries)
+}
+
+// Verify checks all signatures in the batch, returning true if all are valid
+// and false otherwise, with the validation criteria of Verify. Calling Verify
+// on an empty batch returns false.
+func (v *SignerBatchVerifier) Verify() bool {
+	n := len(v.entries)
+	if v.bad || n == 0 {
+		return false
+	}
+	if !ShouldBatch(n) {
+		for i := range v.entries {
+			e := &v.entries[i]
+			if !verifyEquation(&v.A, &e.R, &e.s, &e.k, false) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// The batch verification equation is
+	//
+	// [-sum(z_i * s_i)]B + sum([z_i]R_i) + [sum(z_i * k_i)]A = 0.
+	svals := make([]edwards25519.Scalar, n+2)
+	scalars := make([]*edwards25519.Scalar, n+2)
+	points := make([]*edwards25519.Point, n+2)
+	for i := range scalars {
+		scalars[i] = &svals[i]
+	}
+	Bcoeff, Acoeff := scalars[0], scalars[1]
+	points[0] = edwards25519.NewGeneratorPoint()
+	points[1] = &v.A
+
+	randomness := make([]byte, n*16)
+	if _, err := rand.Read(randomness); err != nil {
+		return false
+	}
+	buf := make([]byte, 32)
+	for i := range v.entries {
+		e := &v.entries[i]
+		z := scalars[2+i]
+		if err := setRandomizer(z, randomness[i*16:(i+1)*16], buf); err != nil {
+			return false
+		}
+		points[2+i] = &e.R
+		Bcoeff.MultiplyAdd(z, &e.s, Bcoeff)
+		Acoeff.MultiplyAdd(z, &e.k, Acoeff)
+	}
+	Bcoeff.Negate(Bcoeff) // this term is subtracted in the summation
+
+	check := multiScalarMult(new(edwards25519.Point), scalars, points, AutoWindow)
+	check.MultByCofactor(check)
+	return check.Equal(edwards25519.NewIdentityPoint()) == 1
+}
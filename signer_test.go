@@ -0,0 +1,54 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"testing"
+
+	"github.com/hdevalence/ed25519consensus/testvectors"
+)
+
+func TestSignerBatchVerifier(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+
+	v := NewSignerBatchVerifier(pub)
+	if v.Verify() {
+		t.Error("empty batch accepted")
+	}
+	for i := 0; i < 38; i++ {
+		msg := []byte(fmt.Sprintf("block %d", i))
+		v.Add(msg, ed25519.Sign(priv, msg))
+	}
+	if v.Len() != 38 {
+		t.Errorf("Len() = %d, want 38", v.Len())
+	}
+	if !v.Verify() {
+		t.Fatal("valid batch rejected")
+	}
+
+	v.Add([]byte("other"), ed25519.Sign(priv, []byte("block 0")))
+	if v.Verify() {
+		t.Error("batch with an invalid signature accepted")
+	}
+
+	v = NewSignerBatchVerifier(pub)
+	v.Add([]byte("block 0"), ed25519.Sign(priv, []byte("block 0")))
+	v.Add(nil, make([]byte, ed25519.SignatureSize-1))
+	if v.Verify() {
+		t.Error("batch with a short signature accepted")
+	}
+
+	if NewSignerBatchVerifier(pub[:31]).Verify() {
+		t.Error("batch with a short public key accepted")
+	}
+}
+
+func TestZIP215SignerBatch(t *testing.T) {
+	// Add each vector twice, so that the batch equation is used.
+	testvectors.Run(t, func(publicKey ed25519.PublicKey, message, signature []byte) bool {
+		v := NewSignerBatchVerifier(publicKey)
+		v.Add(message, signature)
+		v.Add(message, signature)
+		return v.Verify()
+	})
+}
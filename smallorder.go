@@ -0,0 +1,65 @@
+package ed25519consensus
+
+import "encoding/hex"
+
+// smallOrderHex are the canonical encodings of the points [i]T8, where T8 is
+// TorsionGenerator.
+var smallOrderHex = [8]string{
+	"0100000000000000000000000000000000000000000000000000000000000000", // identity
+	"c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a", // order 8
+	"0000000000000000000000000000000000000000000000000000000000000080", // order 4
+	"26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc05", // order 8
+	"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", // order 2
+	"26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc85", // order 8
+	"0000000000000000000000000000000000000000000000000000000000000000", // order 4
+	"c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac03fa", // order 8
+}
+
+// nonCanonicalSmallOrderHex are the non-canonical encodings of small-order
+// points accepted by edwards25519.
+var nonCanonicalSmallOrderHex = [6]string{
+	"0100000000000000000000000000000000000000000000000000000000000080", // identity, sign bit set
+	"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", // order 2, sign bit set
+	"eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", // identity, y = p + 1
+	"eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", // identity, y = p + 1, sign bit set
+	"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", // order 4, y = p
+	"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", // order 4, y = p, sign bit set
+}
+
+// SmallOrderEncodings returns the canonical encodings of the eight points of
+// order dividing 8, with the encoding of [i]T8 at index i, where T8 is the
+// point returned by TorsionGenerator. Index 0 is the identity, and index 4
+// the point of order 2.
+//
+// Verify accepts all of them as A and R, as ZIP215 requires; they are
+// provided so that tests and audits need not copy them from elsewhere.
+func SmallOrderEncodings() [8][32]byte {
+	var encs [8][32]byte
+	for i, s := range smallOrderHex {
+		encs[i] = mustDecodeEncoding(s)
+	}
+	return encs
+}
+
+// NonCanonicalSmallOrderEncodings returns the six non-canonical encodings of
+// small-order points that edwards25519 decodes: the identity and the point
+// of order 2 with the sign bit set, the identity with y = p + 1, and a point
+// of order 4 with y = p, the latter two with and without the sign bit.
+// AnalyzePoint gives the torsion component of each.
+//
+// Verify accepts all of them as A and R, as ZIP215 requires.
+func NonCanonicalSmallOrderEncodings() [6][32]byte {
+	var encs [6][32]byte
+	for i, s := range nonCanonicalSmallOrderHex {
+		encs[i] = mustDecodeEncoding(s)
+	}
+	return encs
+}
+
+func mustDecodeEncoding(s string) [32]byte {
+	var b [32]byte
+	if _, err := hex.Decode(b[:], []byte(s)); err != nil {
+		panic(err)
+	}
+	return b
+}
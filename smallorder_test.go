@@ -0,0 +1,34 @@
+package ed25519consensus
+
+import (
+	"bytes"
+	"testing"
+
+	"filippo.io/edwards25519"
+)
+
+func TestSmallOrderEncodings(t *testing.T) {
+	p := edwards25519.NewIdentityPoint()
+	for i, enc := range SmallOrderEncodings() {
+		if !bytes.Equal(enc[:], p.Bytes()) {
+			t.Errorf("encoding %d is %x, want [%d]T8 = %x", i, enc, i, p.Bytes())
+		}
+		a := AnalyzePoint(enc)
+		if !a.Valid || !a.Canonical || !a.SmallOrder || a.Torsion != i {
+			t.Errorf("encoding %d: %+v", i, a)
+		}
+		p.Add(p, TorsionGenerator())
+	}
+
+	seen := make(map[[32]byte]bool)
+	for i, enc := range NonCanonicalSmallOrderEncodings() {
+		a := AnalyzePoint(enc)
+		if !a.Valid || a.Canonical || !a.SmallOrder {
+			t.Errorf("non-canonical encoding %d: %+v", i, a)
+		}
+		if seen[enc] {
+			t.Errorf("non-canonical encoding %d is repeated", i)
+		}
+		seen[enc] = true
+	}
+}
@@ -0,0 +1,74 @@
+package ed25519consensus
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// SpanStarter creates tracing spans, so that batch verification shows up in
+// the traces of block processing without this package depending on a
+// tracing library. An OpenTelemetry adapter is a few lines:
+//
+//	type otelStarter struct{ tracer trace.Tracer }
+//
+//	func (s otelStarter) StartSpan(ctx context.Context, name string) (context.Context, ed25519consensus.Span) {
+//		ctx, span := s.tracer.Start(ctx, name)
+//		return ctx, otelSpan{span}
+//	}
+//
+// with otelSpan implementing Span with span.SetAttributes and span.End.
+type SpanStarter interface {
+	// StartSpan starts a span named name as a child of any span in ctx.
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a tracing span created by a SpanStarter.
+type Span interface {
+	SetInt(key string, value int64)
+	SetBool(key string, value bool)
+	End()
+}
+
+type spanStarterHolder struct {
+	s SpanStarter
+}
+
+// spans holds the starter set by SetSpanStarter, or nil.
+var spans atomic.Pointer[spanStarterHolder]
+
+// SetSpanStarter makes BatchVerifier.VerifyContext create a span with s for
+// each verification. The starter is shared by the whole process. A nil s,
+// the default, disables spans.
+func SetSpanStarter(s SpanStarter) {
+	if s == nil {
+		spans.Store(nil)
+		return
+	}
+	spans.Store(&spanStarterHolder{s})
+}
+
+// VerifyContext is like Verify, but if a SpanStarter is set, it records the
+// verification in a span named "ed25519consensus.BatchVerifier.Verify",
+// a child of any span in ctx, with the attributes
+//
+//	ed25519consensus.batch.size         number of entries
+//	ed25519consensus.batch.valid        result of Verify
+//	ed25519consensus.batch.duration_us  duration of Verify, in microseconds
+//
+// ctx is only used to find the parent span: verification cannot be
+// canceled.
+func (v *BatchVerifier) VerifyContext(ctx context.Context) bool {
+	h := spans.Load()
+	if h == nil {
+		return v.Verify()
+	}
+	_, span := h.s.StartSpan(ctx, "ed25519consensus.BatchVerifier.Verify")
+	start := time.Now()
+	valid := v.Verify()
+	span.SetInt("ed25519consensus.batch.size", int64(len(v.entries)))
+	span.SetBool("ed25519consensus.batch.valid", valid)
+	span.SetInt("ed25519consensus.batch.duration_us", time.Since(start).Microseconds())
+	span.End()
+	return valid
+}
@@ -0,0 +1,77 @@
+package ed25519consensus
+
+import (
+	"context"
+	"crypto/ed25519"
+	"testing"
+)
+
+type testSpanKey struct{}
+
+type testSpan struct {
+	parent string
+	ints   map[string]int64
+	bools  map[string]bool
+	ended  bool
+}
+
+func (s *testSpan) SetInt(key string, value int64) { s.ints[key] = value }
+func (s *testSpan) SetBool(key string, value bool) { s.bools[key] = value }
+func (s *testSpan) End()                           { s.ended = true }
+
+type testSpanStarter struct {
+	spans []*testSpan
+}
+
+func (st *testSpanStarter) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(testSpanKey{}).(string)
+	s := &testSpan{parent: parent, ints: map[string]int64{}, bools: map[string]bool{}}
+	st.spans = append(st.spans, s)
+	return context.WithValue(ctx, testSpanKey{}, name), s
+}
+
+func TestVerifyContextSpans(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("spanned")
+	sig := ed25519.Sign(priv, msg)
+	v := NewBatchVerifier()
+	for i := 0; i < 3; i++ {
+		v.Add(pub, msg, sig)
+	}
+
+	if !v.VerifyContext(context.Background()) {
+		t.Fatal("valid batch rejected without a span starter")
+	}
+
+	st := &testSpanStarter{}
+	SetSpanStarter(st)
+	t.Cleanup(func() { SetSpanStarter(nil) })
+
+	ctx := context.WithValue(context.Background(), testSpanKey{}, "block")
+	if !v.VerifyContext(ctx) {
+		t.Fatal("valid batch rejected")
+	}
+	v.Add(pub, msg, sig[:10])
+	if v.VerifyContext(ctx) {
+		t.Fatal("invalid batch accepted")
+	}
+
+	if len(st.spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(st.spans))
+	}
+	for i, want := range []struct {
+		size  int64
+		valid bool
+	}{{3, true}, {4, false}} {
+		s := st.spans[i]
+		if !s.ended || s.parent != "block" {
+			t.Errorf("span %d: ended %v, parent %q", i, s.ended, s.parent)
+		}
+		if s.ints["ed25519consensus.batch.size"] != want.size || s.bools["ed25519consensus.batch.valid"] != want.valid {
+			t.Errorf("span %d: attributes %v %v", i, s.ints, s.bools)
+		}
+		if _, ok := s.ints["ed25519consensus.batch.duration_us"]; !ok {
+			t.Errorf("span %d: no duration", i)
+		}
+	}
+}
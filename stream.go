@@ -0,0 +1,136 @@
+package ed25519consensus
+
+import (
+	"context"
+	"crypto/ed25519"
+	"time"
+)
+
+// StreamOptions configures a stream batcher started by NewStreamBatcher.
+type StreamOptions struct {
+	// MaxBatch is the number of entries at which a batch is verified. A
+	// non-positive value selects 64.
+	MaxBatch int
+
+	// MaxDelay is how long the oldest entry of a batch may wait for the
+	// batch to fill before it is verified anyway. Zero verifies whatever
+	// entries are buffered as soon as the input channel is empty.
+	MaxDelay time.Duration
+
+	// Buffer is the capacity of the input and output channels.
+	Buffer int
+}
+
+// StreamResult is the outcome of one batch of a stream batcher.
+type StreamResult struct {
+	// Entries are the entries of the batch, in the order they were received.
+	Entries []Entry
+	// Valid reports for each entry whether it is valid.
+	Valid []bool
+	// OK reports whether all entries are valid.
+	OK bool
+}
+
+// NewStreamBatcher starts a goroutine that reads entries from the returned
+// input channel, groups them into batches, and sends the result of each batch
+// on the returned output channel. A batch is verified once it reaches
+// opts.MaxBatch entries or once its oldest entry has waited for
+// opts.MaxDelay, whichever comes first. If a batch fails, its entries are
+// verified individually to fill in StreamResult.Valid.
+//
+// Closing the input channel flushes the buffered entries and then closes the
+// output channel. Cancelling ctx stops the batcher and closes the output
+// channel, discarding any buffered entries. The output channel must be
+// drained until it is closed.
+//
+// Hashed entries are trusted as by BatchVerifier.AddEntry.
+func NewStreamBatcher(ctx context.Context, opts StreamOptions) (chan<- Entry, <-chan StreamResult) {
+	if opts.MaxBatch < 1 {
+		opts.MaxBatch = 64
+	}
+	in := make(chan Entry, opts.Buffer)
+	out := make(chan StreamResult, opts.Buffer)
+	go streamBatch(ctx, opts, in, out)
+	return in, out
+}
+
+// streamBatch is the goroutine started by NewStreamBatcher.
+func streamBatch(ctx context.Context, opts StreamOptions, in <-chan Entry, out chan<- StreamResult) {
+	defer close(out)
+
+	var pending []Entry
+	var timer *time.Timer
+	var timeout <-chan time.Time
+	flush := func() bool {
+		if timer != nil {
+			timer.Stop()
+			timer, timeout = nil, nil
+		}
+		if len(pending) == 0 {
+			return true
+		}
+		r := verifyStream(pending)
+		pending = nil
+		select {
+		case out <- r:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for {
+		select {
+		case e, ok := <-in:
+			if !ok {
+				flush()
+				return
+			}
+			pending = append(pending, e)
+			switch {
+			case len(pending) >= opts.MaxBatch:
+				if !flush() {
+					return
+				}
+			case opts.MaxDelay <= 0:
+				if len(in) == 0 && !flush() {
+					return
+				}
+			case timer == nil:
+				timer = time.NewTimer(opts.MaxDelay)
+				timeout = timer.C
+			}
+		case <-timeout:
+			timer, timeout = nil, nil
+			if !flush() {
+				return
+			}
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		}
+	}
+}
+
+// verifyStream verifies entries as one batch.
+func verifyStream(entries []Entry) StreamResult {
+	r := StreamResult{
+		Entries: entries,
+		Valid:   make([]bool, len(entries)),
+	}
+	for i := range r.Valid {
+		r.Valid[i] = true
+	}
+
+	v := NewPreallocatedBatchVerifier(len(entries))
+	v.SetFailureCallback(func(i int, _ ed25519.PublicKey, _, _ []byte, _ error) {
+		r.Valid[i] = false
+	})
+	for _, e := range entries {
+		v.AddEntry(e)
+	}
+	r.OK = v.Verify()
+	return r
+}
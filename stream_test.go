@@ -0,0 +1,81 @@
+package ed25519consensus
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newTestEntry(i int, valid bool) Entry {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte(fmt.Sprintf("message %d", i))
+	e := Entry{Message: msg}
+	copy(e.PublicKey[:], pub)
+	copy(e.Signature[:], ed25519.Sign(priv, msg))
+	if !valid {
+		e.Message = []byte("tampered")
+	}
+	return e
+}
+
+func TestStreamBatcher(t *testing.T) {
+	in, out := NewStreamBatcher(context.Background(), StreamOptions{MaxBatch: 4, MaxDelay: time.Hour})
+
+	const n = 10
+	go func() {
+		for i := 0; i < n; i++ {
+			in <- newTestEntry(i, i != 5)
+		}
+		close(in)
+	}()
+
+	var sizes []int
+	var got int
+	for r := range out {
+		sizes = append(sizes, len(r.Entries))
+		for j, valid := range r.Valid {
+			if want := got+j != 5; valid != want {
+				t.Errorf("entry %d: valid %v, want %v", got+j, valid, want)
+			}
+		}
+		if r.OK != (got > 5 || got+len(r.Entries) <= 5) {
+			t.Errorf("batch at %d: OK %v", got, r.OK)
+		}
+		got += len(r.Entries)
+	}
+	if got != n {
+		t.Errorf("got %d results, want %d", got, n)
+	}
+	// The last batch is flushed by closing the input.
+	if len(sizes) != 3 || sizes[0] != 4 || sizes[1] != 4 || sizes[2] != 2 {
+		t.Errorf("batch sizes %v, want [4 4 2]", sizes)
+	}
+}
+
+func TestStreamBatcherDeadline(t *testing.T) {
+	in, out := NewStreamBatcher(context.Background(), StreamOptions{MaxBatch: 1000, MaxDelay: 10 * time.Millisecond})
+	defer close(in)
+
+	in <- newTestEntry(0, true)
+	select {
+	case r := <-out:
+		if !r.OK || len(r.Entries) != 1 {
+			t.Errorf("got %+v", r)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("entry was not flushed by the deadline")
+	}
+}
+
+func TestStreamBatcherCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in, out := NewStreamBatcher(ctx, StreamOptions{MaxBatch: 1000, MaxDelay: time.Hour})
+
+	in <- newTestEntry(0, true)
+	cancel()
+	for r := range out {
+		t.Errorf("got a result after cancellation: %+v", r)
+	}
+}
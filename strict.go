@@ -0,0 +1,34 @@
+package ed25519consensus
+
+import "crypto/ed25519"
+
+// SetKeyPolicy makes the Add methods check each public key against policy,
+// as by ValidatePublicKey, for chains whose rules on keys are stricter than
+// ZIP215. An entry whose key violates the policy is added as invalid, so
+// that Verify fails, and is reported to a failure callback with the error
+// returned by ValidatePublicKey. The zero KeyPolicy, the default, checks
+// nothing beyond Verify.
+//
+// Keys are checked once per entry when it is added, which costs a point
+// decompression, and more for RequireTorsionFreeKey.
+func (v *BatchVerifier) SetKeyPolicy(policy KeyPolicy) {
+	v.policy.Keys = policy
+}
+
+// SetRequireCanonicalR makes the Add methods reject signatures whose R is
+// not a canonical point encoding, which ZIP215 accepts. Such entries are
+// added as invalid, and reported to a failure callback with
+// ErrNonCanonicalR.
+func (v *BatchVerifier) SetRequireCanonicalR(on bool) {
+	v.policy.RequireCanonicalR = on
+}
+
+// checkPolicy returns the first violation of the policy of the batch by a
+// public key and signature of the right lengths, signing with the variant
+// selected by dom, or nil.
+func (v *BatchVerifier) checkPolicy(publicKey ed25519.PublicKey, sig, dom []byte) error {
+	if v.policy == (Policy{}) {
+		return nil
+	}
+	return v.policy.check(publicKey, sig, dom)
+}
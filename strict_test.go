@@ -0,0 +1,85 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"filippo.io/edwards25519"
+)
+
+func TestBatchKeyPolicy(t *testing.T) {
+	// A signature under the identity key, valid under ZIP215 for any
+	// message: R = [s]B.
+	identity := SmallOrderEncodings()[0]
+	var one [32]byte
+	one[0] = 1
+	s, _ := new(edwards25519.Scalar).SetCanonicalBytes(one[:])
+	sig := append(new(edwards25519.Point).ScalarBaseMult(s).Bytes(), s.Bytes()...)
+
+	v := NewBatchVerifier()
+	populateBatchVerifier(t, &v)
+	v.Add(identity[:], []byte("anything"), sig)
+	if !v.Verify() {
+		t.Fatal("ZIP215 batch rejected the identity key")
+	}
+
+	for _, tt := range []struct {
+		policy KeyPolicy
+		err    error
+	}{
+		{RejectIdentityKey, ErrIdentityKey},
+		{RejectSmallOrderKey, ErrSmallOrderKey},
+	} {
+		v := NewBatchVerifier()
+		v.SetKeyPolicy(tt.policy)
+		var reasons []error
+		v.SetFailureCallback(func(_ int, _ ed25519.PublicKey, _, _ []byte, reason error) {
+			reasons = append(reasons, reason)
+		})
+		populateBatchVerifier(t, &v)
+		v.Add(identity[:], []byte("anything"), sig)
+		if v.Verify() {
+			t.Errorf("policy %v: batch with the identity key accepted", tt.policy)
+		}
+		if len(reasons) != 1 || reasons[0] != tt.err {
+			t.Errorf("policy %v: reported %v, want %v", tt.policy, reasons, tt.err)
+		}
+	}
+}
+
+func TestBatchRequireCanonicalR(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("canonical")
+	sig := ed25519.Sign(priv, msg)
+
+	v := NewBatchVerifier()
+	v.SetRequireCanonicalR(true)
+	populateBatchVerifier(t, &v)
+	v.Add(pub, msg, sig)
+	if !v.Verify() {
+		t.Fatal("batch of canonical signatures rejected")
+	}
+
+	// A non-canonical encoding of the identity as R, under the identity
+	// key, with s = 0: valid under ZIP215.
+	identity := SmallOrderEncodings()[0]
+	nonCanonical := NonCanonicalSmallOrderEncodings()[0]
+	sig = append(nonCanonical[:], make([]byte, 32)...)
+	if !Verify(identity[:], msg, sig) {
+		t.Fatal("ZIP215 rejected a non-canonical R")
+	}
+	v = NewBatchVerifier()
+	v.SetRequireCanonicalR(true)
+	var reasons []error
+	v.SetFailureCallback(func(_ int, _ ed25519.PublicKey, _, _ []byte, reason error) {
+		reasons = append(reasons, reason)
+	})
+	populateBatchVerifier(t, &v)
+	v.Add(identity[:], msg, sig)
+	if v.Verify() {
+		t.Error("batch with a non-canonical R accepted")
+	}
+	if len(reasons) != 1 || reasons[0] != ErrNonCanonicalR {
+		t.Errorf("reported %v, want ErrNonCanonicalR", reasons)
+	}
+}
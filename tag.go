@@ -0,0 +1,58 @@
+package ed25519consensus
+
+import "crypto/ed25519"
+
+// AddTagged is like Add, but associates tag with the entry, such as the hash
+// of the transaction or the index of the validator it belongs to.
+// VerifyTagged reports the tags of invalid entries, which saves callers from
+// keeping track of the entry indices themselves.
+func (v *BatchVerifier) AddTagged(tag any, publicKey ed25519.PublicKey, message, sig []byte) {
+	n := len(v.entries)
+	if err := v.add(publicKey, message, sig, nil, true); err != nil {
+		v.dropIfFull(err)
+		return
+	}
+	if len(v.entries) == n {
+		// Skipped by the cache, so it cannot fail.
+		return
+	}
+	for len(v.tags) < len(v.entries)-1 {
+		v.tags = append(v.tags, nil)
+	}
+	v.tags = append(v.tags, tag)
+}
+
+// Tag returns the tag of the entry at index i, as passed to a
+// FailureCallback, or nil if the entry was not added with AddTagged.
+func (v *BatchVerifier) Tag(i int) any {
+	if i < len(v.tags) {
+		return v.tags[i]
+	}
+	return nil
+}
+
+// VerifyTagged is like Verify, but if the batch fails, it verifies every
+// entry individually and also returns the tags of the invalid entries, in
+// order. Invalid entries added without AddTagged are reported with a nil tag.
+// If a failure callback is set, it is called as by Verify. If an entry was
+// dropped because the batch was full (see SetLimits), it returns false and
+// no tags, as the dropped entry cannot be identified.
+func (v *BatchVerifier) VerifyTagged() (ok bool, failed []any) {
+	if len(v.entries) == 0 || v.overflowed {
+		return !v.overflowed && v.cacheHits > 0, nil
+	}
+	v.aliases.check()
+	if v.verifyBatch() {
+		if v.cache != nil {
+			v.cacheStoreValid()
+		}
+		return true, nil
+	}
+	v.forEachFailure(func(i int, e *entry, err error) {
+		if v.onFailure != nil {
+			v.reportFailure(i, e, err)
+		}
+		failed = append(failed, v.Tag(i))
+	})
+	return false, failed
+}
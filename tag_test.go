@@ -0,0 +1,43 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"reflect"
+	"testing"
+)
+
+func TestVerifyTagged(t *testing.T) {
+	v := NewBatchVerifier()
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("tagged")
+	sig := ed25519.Sign(priv, msg)
+
+	v.Add(pub, msg, sig)
+	v.AddTagged("tx1", pub, msg, sig)
+	if ok, failed := v.VerifyTagged(); !ok || failed != nil {
+		t.Fatalf("valid batch: VerifyTagged() = %v, %v", ok, failed)
+	}
+
+	v.Add(pub, []byte("untagged"), sig)
+	v.AddTagged("tx3", pub, []byte("other"), sig)
+	v.AddTagged(4, pub, msg, sig)
+	v.AddTagged("tx5", pub, msg, sig[:10])
+
+	var indices []int
+	v.SetFailureCallback(func(index int, _ ed25519.PublicKey, _, _ []byte, _ error) {
+		indices = append(indices, index)
+	})
+	ok, failed := v.VerifyTagged()
+	if ok {
+		t.Fatal("invalid batch verified")
+	}
+	if want := []any{nil, "tx3", "tx5"}; !reflect.DeepEqual(failed, want) {
+		t.Errorf("failed tags %v, want %v", failed, want)
+	}
+	if want := []int{2, 3, 5}; !reflect.DeepEqual(indices, want) {
+		t.Errorf("failure callback indices %v, want %v", indices, want)
+	}
+	if v.Tag(4) != 4 || v.Tag(0) != nil || v.Tag(100) != nil {
+		t.Errorf("unexpected tags %v, %v, %v", v.Tag(4), v.Tag(0), v.Tag(100))
+	}
+}
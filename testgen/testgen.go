@@ -0,0 +1,234 @@
+// Package testgen generates Ed25519 signatures that exercise the boundary
+// conditions of the ZIP215 validation criteria, for fuzzing and testing code
+// that handles signatures accepted by github.com/hdevalence/ed25519consensus.
+//
+// Unlike the fixed vectors in the testvectors package, every call to Generate
+// draws fresh keys, messages and torsion components.
+package testgen
+
+import (
+	cryptorand "crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"io"
+
+	"filippo.io/edwards25519"
+)
+
+// Case is a generated signature, with whether ZIP215 accepts it.
+type Case struct {
+	// Name identifies the boundary condition exercised.
+	Name string
+
+	PublicKey []byte
+	Message   []byte
+	Signature []byte
+
+	ValidZIP215 bool
+}
+
+// smallOrderEncodings are every encoding, canonical or not, of the eight
+// points of order dividing 8. The first eight are canonical.
+var smallOrderEncodings = [14]string{
+	"0100000000000000000000000000000000000000000000000000000000000000", // identity
+	"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", // order 2
+	"0000000000000000000000000000000000000000000000000000000000000000", // order 4
+	"0000000000000000000000000000000000000000000000000000000000000080", // order 4
+	"26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc05", // order 8
+	"26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc85", // order 8
+	"c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a", // order 8
+	"c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac03fa", // order 8
+	"0100000000000000000000000000000000000000000000000000000000000080", // identity, sign bit set
+	"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", // order 2, sign bit set
+	"eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", // identity, y = p + 1
+	"eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", // identity, y = p + 1, sign bit set
+	"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", // order 4, y = p
+	"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", // order 4, y = p, sign bit set
+}
+
+// generator draws random values for a single call to Generate.
+type generator struct {
+	rand io.Reader
+	err  error
+}
+
+// Generate returns one case for each boundary condition, drawing randomness
+// from rand, or from crypto/rand if rand is nil.
+func Generate(rand io.Reader) ([]Case, error) {
+	g := &generator{rand: rand}
+	if g.rand == nil {
+		g.rand = cryptorand.Reader
+	}
+	cases := []Case{
+		g.maxScalar(),
+		g.nonCanonicalScalar(),
+		g.highBitsScalar(),
+		g.nonCanonicalR(),
+		g.nonCanonicalA(),
+		g.smallOrderA(),
+		g.mixedOrderA(),
+	}
+	if g.err != nil {
+		return nil, g.err
+	}
+	return cases, nil
+}
+
+// maxScalar signs with s = l - 1 under a small-order key, so that R only
+// needs to match [s]B up to torsion.
+func (g *generator) maxScalar() Case {
+	s := new(edwards25519.Scalar).Subtract(edwards25519.NewScalar(), scalarOne())
+	R := new(edwards25519.Point).ScalarBaseMult(s)
+	R.Add(R, g.torsionPoint())
+	return Case{
+		Name:        "s = l - 1",
+		PublicKey:   g.smallOrderEncoding(true),
+		Message:     g.bytes(32),
+		Signature:   append(R.Bytes(), s.Bytes()...),
+		ValidZIP215: true,
+	}
+}
+
+// nonCanonicalScalar adds l to s in a valid signature. The result almost
+// always has the top three bits clear, but is rejected because s is not
+// reduced.
+func (g *generator) nonCanonicalScalar() Case {
+	c := g.validSignature("s + l")
+	addOrder(c.Signature[32:])
+	c.ValidZIP215 = false
+	return c
+}
+
+// highBitsScalar sets some of the top three bits of s in a valid signature.
+func (g *generator) highBitsScalar() Case {
+	c := g.validSignature("high bits of s set")
+	c.Signature[63] |= 0x20 << (g.byte() % 3)
+	c.ValidZIP215 = false
+	return c
+}
+
+// nonCanonicalR uses a non-canonical small-order R with s = 0 under a
+// small-order key.
+func (g *generator) nonCanonicalR() Case {
+	sig := make([]byte, 64)
+	copy(sig, g.smallOrderEncoding(false))
+	return Case{
+		Name:        "non-canonical R",
+		PublicKey:   g.smallOrderEncoding(true),
+		Message:     g.bytes(32),
+		Signature:   sig,
+		ValidZIP215: true,
+	}
+}
+
+// nonCanonicalA uses a non-canonical small-order key, with R = [s]B plus a
+// random torsion component.
+func (g *generator) nonCanonicalA() Case {
+	s := g.scalar()
+	R := new(edwards25519.Point).ScalarBaseMult(s)
+	R.Add(R, g.torsionPoint())
+	return Case{
+		Name:        "non-canonical A",
+		PublicKey:   g.smallOrderEncoding(false),
+		Message:     g.bytes(32),
+		Signature:   append(R.Bytes(), s.Bytes()...),
+		ValidZIP215: true,
+	}
+}
+
+// smallOrderA forges a signature under a canonical small-order key.
+func (g *generator) smallOrderA() Case {
+	c := g.nonCanonicalA()
+	c.Name = "small-order A"
+	c.PublicKey = g.smallOrderEncoding(true)
+	return c
+}
+
+// mixedOrderA signs with a key that has a random torsion component.
+func (g *generator) mixedOrderA() Case {
+	return g.signature("mixed-order A", g.torsionPoint())
+}
+
+// validSignature returns an honest signature by a fresh key.
+func (g *generator) validSignature(name string) Case {
+	return g.signature(name, edwards25519.NewIdentityPoint())
+}
+
+// signature signs a random message with a fresh secret scalar a, under the
+// public key [a]B + T.
+func (g *generator) signature(name string, T *edwards25519.Point) Case {
+	a, r := g.scalar(), g.scalar()
+	A := new(edwards25519.Point).ScalarBaseMult(a)
+	A.Add(A, T)
+	R := new(edwards25519.Point).ScalarBaseMult(r)
+	msg := g.bytes(32)
+
+	h := sha512.New()
+	h.Write(R.Bytes())
+	h.Write(A.Bytes())
+	h.Write(msg)
+	k, _ := new(edwards25519.Scalar).SetUniformBytes(h.Sum(nil))
+	s := new(edwards25519.Scalar).MultiplyAdd(k, a, r)
+
+	return Case{
+		Name:        name,
+		PublicKey:   A.Bytes(),
+		Message:     msg,
+		Signature:   append(R.Bytes(), s.Bytes()...),
+		ValidZIP215: true,
+	}
+}
+
+func (g *generator) bytes(n int) []byte {
+	b := make([]byte, n)
+	if g.err == nil {
+		_, g.err = io.ReadFull(g.rand, b)
+	}
+	return b
+}
+
+func (g *generator) byte() byte {
+	return g.bytes(1)[0]
+}
+
+func (g *generator) scalar() *edwards25519.Scalar {
+	s, _ := new(edwards25519.Scalar).SetUniformBytes(g.bytes(64))
+	return s
+}
+
+// smallOrderEncoding returns a random canonical or non-canonical encoding of
+// a small-order point.
+func (g *generator) smallOrderEncoding(canonical bool) []byte {
+	var enc string
+	if canonical {
+		enc = smallOrderEncodings[g.byte()%8]
+	} else {
+		enc = smallOrderEncodings[8+g.byte()%6]
+	}
+	b, _ := hex.DecodeString(enc)
+	return b
+}
+
+// torsionPoint returns a random point of order dividing 8.
+func (g *generator) torsionPoint() *edwards25519.Point {
+	p, _ := new(edwards25519.Point).SetBytes(g.smallOrderEncoding(true))
+	return p
+}
+
+func scalarOne() *edwards25519.Scalar {
+	var one [32]byte
+	one[0] = 1
+	s, _ := new(edwards25519.Scalar).SetCanonicalBytes(one[:])
+	return s
+}
+
+// addOrder adds l to the little-endian integer s in place. The result fits
+// in 32 bytes since s < l < 2^253.
+func addOrder(s []byte) {
+	l, _ := hex.DecodeString("edd3f55c1a631258d69cf7a2def9de1400000000000000000000000000000010")
+	carry := 0
+	for i := range s {
+		sum := int(s[i]) + int(l[i]) + carry
+		s[i], carry = byte(sum), sum>>8
+	}
+}
@@ -0,0 +1,22 @@
+package testgen_test
+
+import (
+	"testing"
+
+	"github.com/hdevalence/ed25519consensus"
+	"github.com/hdevalence/ed25519consensus/testgen"
+)
+
+func TestGenerate(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		cases, err := testgen.Generate(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range cases {
+			if got := ed25519consensus.Verify(c.PublicKey, c.Message, c.Signature); got != c.ValidZIP215 {
+				t.Errorf("%s: Verify returned %v, want %v", c.Name, got, c.ValidZIP215)
+			}
+		}
+	}
+}
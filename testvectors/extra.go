@@ -0,0 +1,77 @@
+package testvectors
+
+// mixedOrderCases are signatures by keys [a]B + [i]T8 for i from 1 to 7,
+// where T8 is a point of order 8, with deterministic secret scalars a.
+var mixedOrderCases = [7]hexCase{
+	{
+		"02cc69f258660f0559d8028d689fd01d888c9049d211d751fc3013c7411e0e7e",
+		"26508923c885c1f2b015e826992eb6ead6f7621e28e75d32eff3b3d1aaaf270e6680be7bfc4e328f20add32021b7ebd4585509d753ae856d646d5f3b6660cf0f",
+	},
+	{
+		"a5555c0446ea8ff5286910d38a96b0b13df9f8f250660122bcd58c2271340535",
+		"08e2c02111329ba1a1ade6c9d1e40636d5bc9033e7f8ce52f57758157cb4e5f6214eb806d12c49002ea783fe200a7e3edcf48fb5d0680332d5bc099473de9304",
+	},
+	{
+		"9d3dbbb6f37adef6c346cd9ab50c8cb00a690709fafeccbfa254f5b6db51a9c9",
+		"ea8da870b91e13b6e564ef5996f3968d7faab8ba4cd788f60f978277b4bf49e711f12b1b61cb4503e73e098339ed53e3aa540252a0cf852f8f95de53c5272606",
+	},
+	{
+		"ceb829a9c7cabf9a9cb0da0a7092fe106b9e5e9e7a6ed90d26cda8b35aa3f3bf",
+		"983a20a6e073e5a13528ab6a1f9d7971c420aa6300d7cf629fdc83f756076fbe0404b7ca47234ba42d1489fd30a60119c6467b6a0b77494df07514619c72a30d",
+	},
+	{
+		"32d148d7c32ca9951fef9ab877f7e1b116a18dd03078e01d163c2c68da77b994",
+		"3d3601b7c4ba29ae2e6923e779ed9ca343701c0da4ccfd39f52ddb438a4176bd586eaf0ee9a0f55ba8c7f618d04c054a6b0467f325ee4631a430121d756e110c",
+	},
+	{
+		"95d4461271be1b560a95dee4eade1f1c390140570b9828be87ad0fe1d08e3a4b",
+		"ab418902356ae812bcfcd0db3860c854c4789df679e630b95b4144cb9f1b749dd7dc19a85fa0cd3350eeb9b33090e59712d1065e19f697eae9509065fbfcca04",
+	},
+	{
+		"df78c93c19ac7bdbbaff83dc860b840a9ccf76891c46119c3e7c289bb2b48071",
+		"0b580dd09949a6ae1c55e0b1d8ed4b4f72105b30e74379aaa09496711a302e109a28c4a4ac122f791e2b45a9f73187754473f3f354c67e7951d162baada79009",
+	},
+}
+
+// invalidCases are corruptions of the signature of the empty message from
+// RFC 8032, Section 7.1, Test 1.
+var invalidCases = [5]struct {
+	comment string
+	hexCase
+}{
+	{
+		"signature of another message by another key (RFC 8032, Test 2)",
+		hexCase{
+			"d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+			"92a009a9f0d4cab8720e820b5f642540a2b27b5416503f8fb3762223ebdb69da085ac1e43e15996e458f3613d0f11d8c387b2eaeb4302aeeb00d291612bb0c00",
+		},
+	},
+	{
+		"s replaced by s + l",
+		hexCase{
+			"d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+			"e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901554c8c7872aa064e049dbb3013fbf29380d25bf5f0595bbe24655141438e7a101b",
+		},
+	},
+	{
+		"high bit of s set",
+		hexCase{
+			"d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+			"e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a108b",
+		},
+	},
+	{
+		"R is not on the curve",
+		hexCase{
+			"d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+			"02000000000000000000000000000000000000000000000000000000000000005fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b",
+		},
+	},
+	{
+		"A is not on the curve",
+		hexCase{
+			"0200000000000000000000000000000000000000000000000000000000000000",
+			"e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b",
+		},
+	},
+}
@@ -0,0 +1,106 @@
+// Package testvectors provides conformance vectors for Ed25519 signature
+// verification under the ZIP215 validation criteria implemented by
+// github.com/hdevalence/ed25519consensus.
+//
+// Downstream projects can use Run to check that code wrapping a verifier
+// still makes exactly the decisions consensus requires: accepting
+// non-canonical and small-order encodings of A and R, accepting keys with a
+// small-order component, and rejecting malformed signatures.
+package testvectors
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+// Vector is a single verification test case.
+type Vector struct {
+	// Comment describes what the vector exercises.
+	Comment string
+
+	PublicKey []byte
+	Message   []byte
+	Signature []byte
+
+	// Valid is whether a ZIP215 verifier must accept the signature.
+	Valid bool
+}
+
+// hexCase is a hex-encoded public key and signature.
+type hexCase struct {
+	vkHex  string
+	sigHex string
+}
+
+func (c hexCase) vector(comment string, message []byte, valid bool) Vector {
+	vk, err := hex.DecodeString(c.vkHex)
+	if err != nil {
+		panic(err)
+	}
+	sig, err := hex.DecodeString(c.sigHex)
+	if err != nil {
+		panic(err)
+	}
+	return Vector{
+		Comment:   comment,
+		PublicKey: vk,
+		Message:   append([]byte(nil), message...),
+		Signature: sig,
+		Valid:     valid,
+	}
+}
+
+// ZIP215 returns the 196 vectors from ZIP215, which combine every canonical
+// and non-canonical encoding of a small-order point as A and as R, with s = 0,
+// over the message "Zcash". They are all valid.
+func ZIP215() []Vector {
+	vectors := make([]Vector, len(zip215Cases))
+	for i, c := range zip215Cases {
+		comment := fmt.Sprintf("ZIP215 vector %d: small-order A and R", i)
+		vectors[i] = c.vector(comment, []byte("Zcash"), true)
+	}
+	return vectors
+}
+
+// MixedOrder returns valid signatures by keys of the form [a]B + T, where T
+// is each of the non-identity points of order dividing 8. A verifier using
+// the cofactorless equation rejects some of them.
+func MixedOrder() []Vector {
+	vectors := make([]Vector, len(mixedOrderCases))
+	for i, c := range mixedOrderCases {
+		comment := fmt.Sprintf("key with a torsion component of order %d", [...]int{8, 4, 8, 2, 8, 4, 8}[i])
+		vectors[i] = c.vector(comment, []byte("ed25519consensus mixed order"), true)
+	}
+	return vectors
+}
+
+// Invalid returns signatures that every verifier must reject, derived from
+// the first test vector of RFC 8032.
+func Invalid() []Vector {
+	vectors := make([]Vector, len(invalidCases))
+	for i, c := range invalidCases {
+		vectors[i] = c.hexCase.vector(c.comment, nil, false)
+	}
+	return vectors
+}
+
+// All returns the vectors from ZIP215, MixedOrder and Invalid.
+func All() []Vector {
+	vectors := ZIP215()
+	vectors = append(vectors, MixedOrder()...)
+	return append(vectors, Invalid()...)
+}
+
+// Run checks verify against every vector returned by All, reporting each
+// wrong decision as an error on t. The signature of verify matches Verify in
+// both ed25519consensus and crypto/ed25519.
+func Run(t testing.TB, verify func(publicKey ed25519.PublicKey, message, sig []byte) bool) {
+	t.Helper()
+	for i, v := range All() {
+		if got := verify(v.PublicKey, v.Message, v.Signature); got != v.Valid {
+			t.Errorf("vector %d (%s): got %v, want %v", i, v.Comment, got, v.Valid)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package testvectors
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+)
+
+func TestInvalidVectorsDeriveFromValidSignature(t *testing.T) {
+	pub, _ := hex.DecodeString("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+	sig, _ := hex.DecodeString("e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b")
+	if !ed25519.Verify(pub, nil, sig) {
+		t.Fatal("RFC 8032 test vector failed to verify")
+	}
+	for _, v := range Invalid() {
+		if ed25519.Verify(v.PublicKey, v.Message, v.Signature) {
+			t.Errorf("invalid vector %q accepted by crypto/ed25519", v.Comment)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package ed25519consensus
+
+import (
+	"encoding/hex"
+
+	"filippo.io/edwards25519"
+)
+
+// PointAnalysis describes a point encoding, as returned by AnalyzePoint.
+type PointAnalysis struct {
+	// Valid is whether the encoding decodes to a point, under the rules of
+	// Verify. The other fields are false or zero if it does not.
+	Valid bool
+	// Canonical is whether the encoding is canonical. See
+	// IsCanonicalPointEncoding.
+	Canonical bool
+
+	// Torsion is the component of the point in the torsion subgroup of
+	// order 8, as the index i in 0..7 such that it equals [i]T8, where T8 is
+	// the small-order point returned by TorsionGenerator. Every point P is
+	// uniquely P = Q + [i]T8 with Q in the prime-order subgroup.
+	Torsion int
+	// TorsionFree is whether the point is in the prime-order subgroup, that
+	// is, whether Torsion is zero.
+	TorsionFree bool
+	// SmallOrder is whether the point has order at most 8, that is, whether
+	// it is [Torsion]T8 itself. This includes the identity.
+	SmallOrder bool
+}
+
+// torsionGenerator is a point of order 8, which generates the torsion
+// subgroup.
+var torsionGenerator = mustDecodePoint(smallOrderHex[1])
+
+func mustDecodePoint(s string) *edwards25519.Point {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	p, err := new(edwards25519.Point).SetBytes(b)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// TorsionGenerator returns the point of order 8 with respect to which
+// PointAnalysis.Torsion is expressed.
+func TorsionGenerator() *edwards25519.Point {
+	return new(edwards25519.Point).Set(torsionGenerator)
+}
+
+// AnalyzePoint decodes encoding and reports its canonicity and torsion
+// component, for auditing historical chain data for the encodings whose
+// treatment differs between ZIP215 and other validation rules.
+func AnalyzePoint(encoding [32]byte) PointAnalysis {
+	var a PointAnalysis
+	p, err := new(edwards25519.Point).SetBytes(encoding[:])
+	if err != nil {
+		return a
+	}
+	a.Valid = true
+	a.Canonical = IsCanonicalPointEncoding(encoding)
+
+	// With P = Q + T, [8]P = [8]Q, so Q = [1/8 mod l][8]P and T = P - Q.
+	var eight [32]byte
+	eight[0] = 8
+	inv, _ := new(edwards25519.Scalar).SetCanonicalBytes(eight[:])
+	inv.Invert(inv)
+	q := new(edwards25519.Point).MultByCofactor(p)
+	q.ScalarMult(inv, q)
+	t := new(edwards25519.Point).Subtract(p, q)
+
+	multiple := edwards25519.NewIdentityPoint()
+	for i := 0; i < 8; i++ {
+		if t.Equal(multiple) == 1 {
+			a.Torsion = i
+			break
+		}
+		multiple.Add(multiple, torsionGenerator)
+	}
+	a.TorsionFree = a.Torsion == 0
+	a.SmallOrder = q.Equal(edwards25519.NewIdentityPoint()) == 1
+	return a
+}
@@ -0,0 +1,65 @@
+package ed25519consensus
+
+import (
+	"testing"
+
+	"filippo.io/edwards25519"
+)
+
+func TestTorsionGenerator(t *testing.T) {
+	T8 := TorsionGenerator()
+	p := new(edwards25519.Point).Set(T8)
+	for i := 1; i < 8; i++ {
+		if p.Equal(edwards25519.NewIdentityPoint()) == 1 {
+			t.Fatalf("[%d]T8 is the identity", i)
+		}
+		p.Add(p, T8)
+	}
+	if p.Equal(edwards25519.NewIdentityPoint()) != 1 {
+		t.Error("[8]T8 is not the identity")
+	}
+}
+
+func TestAnalyzePoint(t *testing.T) {
+	var scalar [64]byte
+	scalar[0] = 7
+	k, _ := new(edwards25519.Scalar).SetUniformBytes(scalar[:])
+	Q := new(edwards25519.Point).ScalarBaseMult(k)
+
+	T := edwards25519.NewIdentityPoint()
+	for i := 0; i < 8; i++ {
+		for _, small := range []bool{false, true} {
+			p := new(edwards25519.Point).Set(T)
+			if !small {
+				p.Add(p, Q)
+			}
+			var enc [32]byte
+			copy(enc[:], p.Bytes())
+			want := PointAnalysis{
+				Valid:       true,
+				Canonical:   true,
+				Torsion:     i,
+				TorsionFree: i == 0,
+				SmallOrder:  small,
+			}
+			if got := AnalyzePoint(enc); got != want {
+				t.Errorf("[%d]T8, small %v: got %+v, want %+v", i, small, got, want)
+			}
+		}
+		T.Add(T, TorsionGenerator())
+	}
+
+	// y = p is a non-canonical encoding of y = 0, a point of order 4.
+	nonCanonical := [32]byte{0xed}
+	for i := 1; i < 31; i++ {
+		nonCanonical[i] = 0xff
+	}
+	nonCanonical[31] = 0x7f
+	if got := AnalyzePoint(nonCanonical); !got.Valid || got.Canonical || !got.SmallOrder || got.Torsion%4 != 2 {
+		t.Errorf("non-canonical y = 0: got %+v", got)
+	}
+
+	if got := AnalyzePoint([32]byte{2}); got != (PointAnalysis{}) {
+		t.Errorf("invalid encoding: got %+v", got)
+	}
+}
@@ -0,0 +1,122 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"sync/atomic"
+)
+
+// TraceRecord is a verification decision recorded by the tracer set with
+// SetTracer. It holds the challenge digest instead of the message, so a
+// trace stays small and reveals nothing about messages beyond their hash,
+// yet Replay can re-execute the decision exactly.
+type TraceRecord struct {
+	PublicKey [ed25519.PublicKeySize]byte
+	Signature [ed25519.SignatureSize]byte
+	// Digest is the challenge digest SHA-512(dom2 || R || A || M).
+	Digest [64]byte
+	// Variant is the Ed25519 variant the signature was verified as.
+	Variant Variant
+	// Policy is the policy the signature was verified under. It is the
+	// zero Policy for Verify and its variants.
+	Policy Policy
+	// Valid is the decision.
+	Valid bool
+}
+
+// tracer is the function set by SetTracer, or nil.
+var tracer atomic.Pointer[func(*TraceRecord)]
+
+// SetTracer makes Verify, VerifyWithContext, VerifyPH, VerifyConstantTime,
+// Verifier.Verify, VerifyWithOptions, Policy.Verify and BatchVerifier.Verify
+// call f with a record of each decision, for postmortem analysis of
+// consensus faults with Replay. The record is only valid during the call.
+// Inputs of the wrong length or with a malformed s are rejected before the
+// challenge is computed and are not recorded, nor are batch entries
+// rejected when added or added with a caller-supplied challenge.
+//
+// To record the decision of every entry of a failed batch, BatchVerifier
+// then verifies each entry individually, as when a failure callback is set.
+//
+// f may be called concurrently, and must not verify signatures itself. A
+// nil f, the default, disables tracing.
+func SetTracer(f func(*TraceRecord)) {
+	if f == nil {
+		tracer.Store(nil)
+		return
+	}
+	tracer.Store(&f)
+}
+
+// traceDigest records a decision if tracing is enabled. It does not retain
+// its arguments, so that callers such as Verify do not allocate when
+// tracing is disabled.
+func traceDigest(publicKey, sig, dom []byte, digest *[64]byte, p *Policy, valid bool) {
+	f := tracer.Load()
+	if f == nil {
+		return
+	}
+	r := &TraceRecord{Digest: *digest, Variant: variantOf(dom), Valid: valid}
+	copy(r.PublicKey[:], publicKey)
+	copy(r.Signature[:], sig)
+	if p != nil {
+		r.Policy = *p
+	}
+	(*f)(r)
+}
+
+// traceEntries records the decision of every bound entry of the batch,
+// given whether the batch verified.
+func (v *BatchVerifier) traceEntries(valid bool) {
+	if tracer.Load() == nil {
+		return
+	}
+	for i := range v.entries {
+		e := &v.entries[i]
+		if !e.bound {
+			continue
+		}
+		ok := valid
+		if !valid {
+			ok = e.check() == nil
+		} else if e.message != nil {
+			e.computeDigest(e.message)
+			e.message = nil
+		}
+		traceDigest(e.pubkey[:], e.signature[:], e.dom, &e.digest, &v.policy, ok)
+	}
+}
+
+// Replay re-executes the decisions of trace, and returns the indices of the
+// records whose decision differs now, which is empty unless the rules of
+// the package, or the code executing them, changed since the trace was
+// recorded.
+func Replay(trace []TraceRecord) []int {
+	var mismatches []int
+	for i := range trace {
+		if replay(&trace[i]) != trace[i].Valid {
+			mismatches = append(mismatches, i)
+		}
+	}
+	return mismatches
+}
+
+func replay(r *TraceRecord) bool {
+	if r.Signature[63]&224 != 0 {
+		return false
+	}
+	if r.Policy.Variants != 0 && r.Policy.Variants&r.Variant == 0 {
+		return false
+	}
+	p := r.Policy
+	p.Variants = 0
+	if p.check(r.PublicKey[:], r.Signature[:], nil) != nil {
+		return false
+	}
+	e := entry{
+		pubkey:       r.PublicKey,
+		signature:    r.Signature,
+		digest:       r.Digest,
+		cofactorless: p.Cofactorless,
+	}
+	return e.check() == nil
+}
@@ -0,0 +1,71 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestTraceReplay(t *testing.T) {
+	var trace []TraceRecord
+	SetTracer(func(r *TraceRecord) { trace = append(trace, *r) })
+	t.Cleanup(func() { SetTracer(nil) })
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	msg := []byte("traced")
+	sig := ed25519.Sign(priv, msg)
+	bad := append([]byte(nil), sig...)
+	bad[0] ^= 1
+
+	Verify(pub, msg, sig)
+	Verify(pub, msg, bad)
+	Verify(pub, msg, sig[:63]) // not recorded
+	VerifyConstantTime(pub, msg, sig)
+	new(Verifier).Verify(pub, msg, sig)
+
+	VerifyWithContext(pub, msg, sig, "ctx")
+
+	identity := SmallOrderEncodings()[0]
+	zero := make([]byte, 64)
+	zero[0] = 1
+	p := Policy{Keys: RejectSmallOrderKey}
+	p.Verify(identity[:], msg, zero)
+	Policy{Variants: VariantEd25519}.Verify(pub, msg, sig)
+
+	v := NewBatchVerifier()
+	for i := 0; i < 3; i++ {
+		v.Add(pub, msg, sig)
+	}
+	v.Verify()
+	v.Add(pub, msg, bad)
+	v.Verify()
+
+	want := []bool{true, false, true, true, false, false, true, true, true, true, true, true, true, false}
+	if len(trace) != len(want) {
+		t.Fatalf("recorded %d decisions, want %d", len(trace), len(want))
+	}
+	for i, r := range trace {
+		if r.Valid != want[i] {
+			t.Errorf("record %d: valid = %v, want %v", i, r.Valid, want[i])
+		}
+	}
+	if trace[5].Policy != p {
+		t.Errorf("record 5: policy %+v, want %+v", trace[5].Policy, p)
+	}
+	if trace[4].Variant != VariantEd25519ctx {
+		t.Errorf("record 4: variant %v, want Ed25519ctx", trace[4].Variant)
+	}
+
+	SetTracer(nil)
+	if m := Replay(trace); len(m) != 0 {
+		t.Errorf("replay mismatches at %v", m)
+	}
+	trace[1].Valid = true
+	trace[5].Policy = Policy{}
+	if m := Replay(trace); len(m) != 2 || m[0] != 1 || m[1] != 5 {
+		t.Errorf("replay of a tampered trace mismatches at %v, want [1 5]", m)
+	}
+	trace[0].Policy.Variants = VariantEd25519ctx
+	if m := Replay(trace[:1]); len(m) != 1 {
+		t.Error("replay accepted a variant not allowed by the policy")
+	}
+}
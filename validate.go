@@ -0,0 +1,120 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"errors"
+
+	"filippo.io/edwards25519"
+)
+
+// KeyPolicy is a set of requirements on public keys, checked by
+// ValidatePublicKey in addition to the key being a valid point encoding.
+// Policies are combined with bitwise OR; the zero KeyPolicy accepts every key
+// that Verify can decode.
+type KeyPolicy uint
+
+const (
+	// RequireCanonicalKey rejects keys that are not canonical point
+	// encodings. See IsCanonicalPointEncoding.
+	RequireCanonicalKey KeyPolicy = 1 << iota
+
+	// RejectIdentityKey rejects any encoding of the identity point.
+	RejectIdentityKey
+
+	// RejectSmallOrderKey rejects points of order dividing 8, including
+	// the identity. Signatures under such keys can be forged for any
+	// message.
+	RejectSmallOrderKey
+
+	// RequireTorsionFreeKey rejects points outside the prime-order
+	// subgroup. See HasSmallOrderComponent.
+	RequireTorsionFreeKey
+)
+
+var (
+	// ErrInvalidKeyLength means a public key is not 32 bytes long.
+	ErrInvalidKeyLength = errors.New("ed25519consensus: invalid public key length")
+	// ErrInvalidKeyEncoding means a public key does not decode to a point.
+	ErrInvalidKeyEncoding = errors.New("ed25519consensus: invalid public key encoding")
+	// ErrNonCanonicalKey means a public key violates RequireCanonicalKey.
+	ErrNonCanonicalKey = errors.New("ed25519consensus: non-canonical public key encoding")
+	// ErrIdentityKey means a public key violates RejectIdentityKey.
+	ErrIdentityKey = errors.New("ed25519consensus: public key is the identity")
+	// ErrSmallOrderKey means a public key violates RejectSmallOrderKey.
+	ErrSmallOrderKey = errors.New("ed25519consensus: public key has small order")
+	// ErrTorsionedKey means a public key violates RequireTorsionFreeKey.
+	ErrTorsionedKey = errors.New("ed25519consensus: public key has a small-order component")
+	// ErrNonCanonicalR means the R component of a signature is not a
+	// canonical point encoding, where canonical encodings are required.
+	ErrNonCanonicalR = errors.New("ed25519consensus: non-canonical signature R encoding")
+)
+
+// ValidatePublicKey checks that pub is a valid point encoding satisfying the
+// given policy, returning the first violated requirement as one of the errors
+// above. It lets applications enforce stricter key rules when keys are
+// registered, without changing the signature validity criteria of Verify.
+func ValidatePublicKey(pub ed25519.PublicKey, policy KeyPolicy) error {
+	if len(pub) != ed25519.PublicKeySize {
+		return ErrInvalidKeyLength
+	}
+	A, err := new(edwards25519.Point).SetBytes(pub)
+	if err != nil {
+		return ErrInvalidKeyEncoding
+	}
+
+	if policy&RequireCanonicalKey != 0 {
+		var enc [32]byte
+		copy(enc[:], pub)
+		if !IsCanonicalPointEncoding(enc) {
+			return ErrNonCanonicalKey
+		}
+	}
+
+	identity := edwards25519.NewIdentityPoint()
+	if policy&RejectIdentityKey != 0 && A.Equal(identity) == 1 {
+		return ErrIdentityKey
+	}
+	if policy&RejectSmallOrderKey != 0 && new(edwards25519.Point).MultByCofactor(A).Equal(identity) == 1 {
+		return ErrSmallOrderKey
+	}
+	if policy&RequireTorsionFreeKey != 0 && !isTorsionFree(A) {
+		return ErrTorsionedKey
+	}
+	return nil
+}
+
+// Prevalidate performs the checks of Verify that do not depend on the
+// message and require no point decompression: the lengths of publicKey and
+// sig, the top three bits of sig, and that s is canonical. It returns
+// ErrInvalidKeyLength or ErrMalformedSignature for signatures that Verify
+// would reject for every message, so that gossip layers can drop them before
+// hashing large payloads. A nil result does not mean sig is valid.
+func Prevalidate(publicKey ed25519.PublicKey, sig []byte) error {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return ErrInvalidKeyLength
+	}
+	if len(sig) != ed25519.SignatureSize || sig[63]&224 != 0 {
+		return ErrMalformedSignature
+	}
+	if _, err := new(edwards25519.Scalar).SetCanonicalBytes(sig[32:]); err != nil {
+		return ErrMalformedSignature
+	}
+	return nil
+}
+
+// PrevalidatePoints is like Prevalidate, but also decodes the public key and
+// the R component of sig, returning ErrInvalidKeyEncoding or
+// ErrMalformedSignature if they are not valid point encodings. Decoding costs
+// about a tenth of a verification, which is worth it for large messages.
+func PrevalidatePoints(publicKey ed25519.PublicKey, sig []byte) error {
+	if err := Prevalidate(publicKey, sig); err != nil {
+		return err
+	}
+	if _, err := new(edwards25519.Point).SetBytes(publicKey); err != nil {
+		return ErrInvalidKeyEncoding
+	}
+	if _, err := new(edwards25519.Point).SetBytes(sig[:32]); err != nil {
+		return ErrMalformedSignature
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"filippo.io/edwards25519"
+)
+
+func TestValidatePublicKey(t *testing.T) {
+	all := RequireCanonicalKey | RejectIdentityKey | RejectSmallOrderKey | RequireTorsionFreeKey
+
+	pub, _, _ := ed25519.GenerateKey(nil)
+	A, _ := new(edwards25519.Point).SetBytes(pub)
+	order2 := decodeHex32(t, "ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	T, _ := new(edwards25519.Point).SetBytes(order2[:])
+	mixed := new(edwards25519.Point).Add(A, T).Bytes()
+	identity := decodeHex32(t, "0100000000000000000000000000000000000000000000000000000000000000")
+	nonCanonicalIdentity := decodeHex32(t, "0100000000000000000000000000000000000000000000000000000000000080")
+	notOnCurve := decodeHex32(t, "0200000000000000000000000000000000000000000000000000000000000000")
+
+	for _, c := range []struct {
+		name   string
+		pub    []byte
+		policy KeyPolicy
+		err    error
+	}{
+		{"generated", pub, all, nil},
+		{"short", pub[:31], 0, ErrInvalidKeyLength},
+		{"not on curve", notOnCurve[:], 0, ErrInvalidKeyEncoding},
+		{"non-canonical identity", nonCanonicalIdentity[:], RejectSmallOrderKey, ErrSmallOrderKey},
+		{"non-canonical identity", nonCanonicalIdentity[:], all, ErrNonCanonicalKey},
+		{"identity", identity[:], RequireTorsionFreeKey, nil},
+		{"identity", identity[:], RejectIdentityKey, ErrIdentityKey},
+		{"identity", identity[:], RejectSmallOrderKey, ErrSmallOrderKey},
+		{"order 2", order2[:], RejectIdentityKey, nil},
+		{"order 2", order2[:], RejectSmallOrderKey, ErrSmallOrderKey},
+		{"order 2", order2[:], RequireTorsionFreeKey, ErrTorsionedKey},
+		{"mixed order", mixed, RejectSmallOrderKey, nil},
+		{"mixed order", mixed, all, ErrTorsionedKey},
+	} {
+		if err := ValidatePublicKey(c.pub, c.policy); err != c.err {
+			t.Errorf("%s key with policy %b: got %v, want %v", c.name, c.policy, err, c.err)
+		}
+	}
+}
+
+func TestPrevalidate(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	sig := ed25519.Sign(priv, []byte("gossip"))
+	if err := Prevalidate(pub, sig); err != nil {
+		t.Errorf("valid signature: %v", err)
+	}
+	if err := PrevalidatePoints(pub, sig); err != nil {
+		t.Errorf("valid signature: %v", err)
+	}
+
+	highBits := append([]byte{}, sig...)
+	highBits[63] |= 0x40
+	nonCanonicalS := append([]byte{}, sig...)
+	l := decodeHex32(t, "edd3f55c1a631258d69cf7a2def9de1400000000000000000000000000000010")
+	copy(nonCanonicalS[32:], l[:])
+	// y = 2 is not on the curve.
+	badPoint := decodeHex32(t, "0200000000000000000000000000000000000000000000000000000000000000")
+	badR := append(append([]byte{}, badPoint[:]...), sig[32:]...)
+
+	for _, tc := range []struct {
+		name      string
+		pub, sig  []byte
+		err, full error
+	}{
+		{"short key", pub[:31], sig, ErrInvalidKeyLength, ErrInvalidKeyLength},
+		{"short signature", pub, sig[:63], ErrMalformedSignature, ErrMalformedSignature},
+		{"high bits", pub, highBits, ErrMalformedSignature, ErrMalformedSignature},
+		{"non-canonical s", pub, nonCanonicalS, ErrMalformedSignature, ErrMalformedSignature},
+		{"undecodable key", badPoint[:], sig, nil, ErrInvalidKeyEncoding},
+		{"undecodable R", pub, badR, nil, ErrMalformedSignature},
+	} {
+		if err := Prevalidate(tc.pub, tc.sig); err != tc.err {
+			t.Errorf("%s: Prevalidate returned %v, want %v", tc.name, err, tc.err)
+		}
+		if err := PrevalidatePoints(tc.pub, tc.sig); err != tc.full {
+			t.Errorf("%s: PrevalidatePoints returned %v, want %v", tc.name, err, tc.full)
+		}
+	}
+}
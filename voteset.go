@@ -0,0 +1,129 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"math"
+	"sort"
+)
+
+// VoteSetVerifier verifies votes weighted by voting power until enough
+// power is verified, as in Tendermint-style consensus, where a commit only
+// needs valid signatures from more than two thirds of the voting power, not
+// from every validator.
+//
+// Verify checks the votes with the most power first, in batches just large
+// enough to reach the threshold if they are valid, and stops as soon as it
+// is reached, so the signatures of the remaining votes are never checked.
+type VoteSetVerifier struct {
+	threshold uint64
+	batch     BatchVerifier
+	powers    []uint64
+}
+
+// NewVoteSetVerifier creates an empty VoteSetVerifier that succeeds once
+// votes totalling at least threshold voting power are verified. With a
+// threshold of math.MaxUint64, every vote is verified.
+func NewVoteSetVerifier(threshold uint64) *VoteSetVerifier {
+	return &VoteSetVerifier{
+		threshold: threshold,
+		batch:     NewBatchVerifier(),
+	}
+}
+
+// Add adds a vote, a (public key, message, sig) triple, with its voting
+// power. It retains no reference to its arguments. Further votes by the same
+// public key do not add to its power, as described at Verify.
+func (v *VoteSetVerifier) Add(publicKey ed25519.PublicKey, message, sig []byte, power uint64) {
+	v.batch.Add(publicKey, message, sig)
+	v.powers = append(v.powers, power)
+}
+
+// Len returns the number of votes added.
+func (v *VoteSetVerifier) Len() int {
+	return len(v.powers)
+}
+
+// Verify verifies votes until their power reaches the threshold, and
+// returns the total power of the votes verified valid, which saturates at
+// math.MaxUint64, and whether it reached the threshold. Invalid votes are
+// skipped and do not make Verify fail by themselves. The power of each
+// public key is counted once, from its valid vote of highest power, however
+// many votes were added for it.
+//
+// If ok is true, power may be less than the total power of all valid votes,
+// since the remaining votes are not verified.
+func (v *VoteSetVerifier) Verify() (power uint64, ok bool) {
+	if v.threshold == 0 {
+		return 0, true
+	}
+
+	// Sort the votes by decreasing power, keeping the order of equal ones.
+	entries := v.batch.entries
+	order := make([]int, len(entries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return v.powers[order[a]] > v.powers[order[b]]
+	})
+	// Each public key counts once: remaining holds the votes not verified
+	// yet, and a key's other votes are dropped once one of them is valid.
+	// Malformed votes are never valid, so they are dropped upfront.
+	remaining := order[:0]
+	for _, j := range order {
+		if entries[j].good {
+			remaining = append(remaining, j)
+		}
+	}
+	counted := make(map[[ed25519.PublicKeySize]byte]bool)
+	var chunk []entry
+	var chunkPowers []uint64
+	for len(remaining) > 0 && power < v.threshold {
+		// Take the fewest votes by distinct keys that reach the threshold
+		// if all valid, keeping the other votes of those keys for later.
+		chunk, chunkPowers = chunk[:0], chunkPowers[:0]
+		inChunk := make(map[[ed25519.PublicKeySize]byte]bool)
+		total := power
+		next := remaining[:0]
+		for _, j := range remaining {
+			key := entries[j].pubkey
+			switch {
+			case counted[key]:
+			case inChunk[key] || total >= v.threshold:
+				next = append(next, j)
+			default:
+				inChunk[key] = true
+				chunk = append(chunk, entries[j])
+				chunkPowers = append(chunkPowers, v.powers[j])
+				total = addPower(total, v.powers[j])
+			}
+		}
+		remaining = next
+		if len(chunk) == 0 {
+			break
+		}
+
+		if v.batch.verifyEntries(chunk) {
+			power = total
+			for i := range chunk {
+				counted[chunk[i].pubkey] = true
+			}
+			continue
+		}
+		for i := range chunk {
+			if chunk[i].check() == nil {
+				power = addPower(power, chunkPowers[i])
+				counted[chunk[i].pubkey] = true
+			}
+		}
+	}
+	return power, power >= v.threshold
+}
+
+// addPower returns a + b, saturating at math.MaxUint64.
+func addPower(a, b uint64) uint64 {
+	if a > math.MaxUint64-b {
+		return math.MaxUint64
+	}
+	return a + b
+}
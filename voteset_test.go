@@ -0,0 +1,90 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+	"math"
+	"testing"
+)
+
+func TestVoteSetVerifier(t *testing.T) {
+	msg := []byte("block hash")
+	powers := []uint64{10, 40, 5, 30, 15}
+	type vote struct {
+		pub, sig []byte
+	}
+	votes := make([]vote, len(powers))
+	for i := range votes {
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		votes[i] = vote{pub, ed25519.Sign(priv, msg)}
+	}
+
+	for _, tt := range []struct {
+		threshold uint64
+		invalid   int // index of an invalid vote, or -1
+		power     uint64
+		ok        bool
+	}{
+		// The 40 and 30 votes are enough.
+		{67, -1, 70, true},
+		// Without the 40 vote, the remaining ones are verified.
+		{67, 1, 60, false},
+		{60, 1, 60, true},
+		{math.MaxUint64, -1, 100, false},
+		{math.MaxUint64, 2, 95, false},
+		{0, -1, 0, true},
+	} {
+		v := NewVoteSetVerifier(tt.threshold)
+		for i, vote := range votes {
+			m := msg
+			if i == tt.invalid {
+				m = []byte("other")
+			}
+			v.Add(vote.pub, m, vote.sig, powers[i])
+		}
+		if v.Len() != len(votes) {
+			t.Errorf("Len() = %d", v.Len())
+		}
+		power, ok := v.Verify()
+		if power != tt.power || ok != tt.ok {
+			t.Errorf("threshold %d, invalid %d: Verify() = %d, %v, want %d, %v",
+				tt.threshold, tt.invalid, power, ok, tt.power, tt.ok)
+		}
+	}
+}
+
+func TestVoteSetVerifierDuplicates(t *testing.T) {
+	msg := []byte("block hash")
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	sig := ed25519.Sign(priv, msg)
+	other, otherPriv, _ := ed25519.GenerateKey(nil)
+
+	// One validator with a third of the power cannot reach two thirds by
+	// repeating its vote, or by signing another message.
+	v := NewVoteSetVerifier(67)
+	for i := 0; i < 3; i++ {
+		v.Add(pub, msg, sig, 34)
+	}
+	v.Add(pub, []byte("other"), ed25519.Sign(priv, []byte("other")), 34)
+	v.Add(other, msg, ed25519.Sign(otherPriv, msg), 20)
+	if power, ok := v.Verify(); power != 54 || ok {
+		t.Errorf("duplicated votes: Verify() = %d, %v, want 54, false", power, ok)
+	}
+
+	// An invalid vote does not hide a valid one by the same key.
+	v = NewVoteSetVerifier(50)
+	v.Add(pub, []byte("forged"), sig, 34)
+	v.Add(pub, msg, sig, 34)
+	v.Add(other, msg, ed25519.Sign(otherPriv, msg), 20)
+	if power, ok := v.Verify(); power != 54 || !ok {
+		t.Errorf("invalid then valid vote: Verify() = %d, %v, want 54, true", power, ok)
+	}
+}
+
+func TestAddPower(t *testing.T) {
+	if got := addPower(math.MaxUint64-1, 2); got != math.MaxUint64 {
+		t.Errorf("addPower saturated to %d", got)
+	}
+	if got := addPower(1, 2); got != 3 {
+		t.Errorf("addPower(1, 2) = %d", got)
+	}
+}
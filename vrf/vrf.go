@@ -0,0 +1,176 @@
+// Package vrf implements the ECVRF-EDWARDS25519-SHA512-TAI verifiable random
+// function from RFC 9381, using Ed25519 keys.
+//
+// Proofs are decoded with the same rules as ed25519consensus.Verify: points
+// may have non-canonical encodings, while scalars must be reduced. Public keys
+// of small order are rejected, as required for the VRF to be unique.
+package vrf
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"errors"
+
+	"filippo.io/edwards25519"
+)
+
+const (
+	// ProofSize is the size, in bytes, of a VRF proof.
+	ProofSize = 80
+	// OutputSize is the size, in bytes, of a VRF output.
+	OutputSize = 64
+
+	// suite is the suite_string of ECVRF-EDWARDS25519-SHA512-TAI.
+	suite = 0x03
+	// cLen is the length in bytes of the challenge.
+	cLen = 16
+)
+
+// Prove returns the VRF proof for alpha under priv. The proof is
+// deterministic, and its output is given by ProofToHash.
+func Prove(priv ed25519.PrivateKey, alpha []byte) []byte {
+	digest := sha512.Sum512(priv.Seed())
+	x, _ := new(edwards25519.Scalar).SetBytesWithClamping(digest[:32])
+	pub := priv.Public().(ed25519.PublicKey)
+
+	Y, _ := new(edwards25519.Point).SetBytes(pub)
+	H := encodeToCurve(pub, alpha)
+	Gamma := new(edwards25519.Point).ScalarMult(x, H)
+
+	// Nonce generation as in RFC 8032, Section 5.1.6.
+	h := sha512.New()
+	h.Write(digest[32:])
+	h.Write(H.Bytes())
+	k, _ := new(edwards25519.Scalar).SetUniformBytes(h.Sum(nil))
+
+	c := challenge(Y, H, Gamma,
+		new(edwards25519.Point).ScalarBaseMult(k),
+		new(edwards25519.Point).ScalarMult(k, H))
+	s := new(edwards25519.Scalar).MultiplyAdd(c, x, k)
+
+	pi := make([]byte, 0, ProofSize)
+	pi = append(pi, Gamma.Bytes()...)
+	pi = append(pi, c.Bytes()[:cLen]...)
+	return append(pi, s.Bytes()...)
+}
+
+// Verify reports whether pi is a valid proof for alpha under pub, and if so
+// returns the VRF output.
+func Verify(pub ed25519.PublicKey, pi, alpha []byte) (bool, []byte) {
+	if len(pub) != ed25519.PublicKeySize {
+		return false, nil
+	}
+	Y, err := new(edwards25519.Point).SetBytes(pub)
+	if err != nil {
+		return false, nil
+	}
+	if new(edwards25519.Point).MultByCofactor(Y).Equal(edwards25519.NewIdentityPoint()) == 1 {
+		return false, nil
+	}
+
+	Gamma, c, s, err := decodeProof(pi)
+	if err != nil {
+		return false, nil
+	}
+
+	H := encodeToCurve(pub, alpha)
+	negC := new(edwards25519.Scalar).Negate(c)
+	// U = [s]B - [c]Y
+	U := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(negC, Y, s)
+	// V = [s]H - [c]Gamma
+	V := new(edwards25519.Point).VarTimeMultiScalarMult(
+		[]*edwards25519.Scalar{s, negC},
+		[]*edwards25519.Point{H, Gamma})
+
+	if challenge(Y, H, Gamma, U, V).Equal(c) != 1 {
+		return false, nil
+	}
+	return true, proofToHash(Gamma)
+}
+
+// ProofToHash returns the VRF output of a proof, without verifying it.
+// Callers must only use the output of proofs checked with Verify.
+func ProofToHash(pi []byte) ([]byte, error) {
+	Gamma, _, _, err := decodeProof(pi)
+	if err != nil {
+		return nil, err
+	}
+	return proofToHash(Gamma), nil
+}
+
+func proofToHash(Gamma *edwards25519.Point) []byte {
+	h := sha512.New()
+	h.Write([]byte{suite, 0x03})
+	h.Write(new(edwards25519.Point).MultByCofactor(Gamma).Bytes())
+	h.Write([]byte{0x00})
+	return h.Sum(nil)
+}
+
+var errInvalidProof = errors.New("vrf: invalid proof")
+
+// decodeProof splits pi into Gamma, c and s.
+func decodeProof(pi []byte) (Gamma *edwards25519.Point, c, s *edwards25519.Scalar, err error) {
+	if len(pi) != ProofSize {
+		return nil, nil, nil, errInvalidProof
+	}
+	Gamma, err = new(edwards25519.Point).SetBytes(pi[:32])
+	if err != nil {
+		return nil, nil, nil, errInvalidProof
+	}
+	var cBytes [32]byte
+	copy(cBytes[:], pi[32:32+cLen])
+	c, err = new(edwards25519.Scalar).SetCanonicalBytes(cBytes[:])
+	if err != nil {
+		return nil, nil, nil, errInvalidProof
+	}
+	s, err = new(edwards25519.Scalar).SetCanonicalBytes(pi[32+cLen:])
+	if err != nil {
+		return nil, nil, nil, errInvalidProof
+	}
+	return Gamma, c, s, nil
+}
+
+// encodeToCurve implements ECVRF_encode_to_curve_try_and_increment, with the
+// public key encoding as the salt.
+func encodeToCurve(salt, alpha []byte) *edwards25519.Point {
+	h := sha512.New()
+	var digest [64]byte
+	for ctr := 0; ; ctr++ {
+		// The probability of reaching 256 iterations is negligible.
+		if ctr > 255 {
+			panic("vrf: try-and-increment failed")
+		}
+		h.Reset()
+		h.Write([]byte{suite, 0x01})
+		h.Write(salt)
+		h.Write(alpha)
+		h.Write([]byte{byte(ctr), 0x00})
+		h.Sum(digest[:0])
+
+		// RFC 8032 decoding, which rejects non-canonical encodings.
+		H, err := new(edwards25519.Point).SetBytes(digest[:32])
+		if err != nil || !bytes.Equal(H.Bytes(), digest[:32]) {
+			continue
+		}
+		H.MultByCofactor(H)
+		if H.Equal(edwards25519.NewIdentityPoint()) == 1 {
+			continue
+		}
+		return H
+	}
+}
+
+// challenge implements ECVRF_challenge_generation.
+func challenge(points ...*edwards25519.Point) *edwards25519.Scalar {
+	h := sha512.New()
+	h.Write([]byte{suite, 0x02})
+	for _, p := range points {
+		h.Write(p.Bytes())
+	}
+	h.Write([]byte{0x00})
+	var c [32]byte
+	copy(c[:cLen], h.Sum(nil))
+	s, _ := new(edwards25519.Scalar).SetCanonicalBytes(c[:])
+	return s
+}
@@ -0,0 +1,87 @@
+package vrf
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+)
+
+// Test vectors from RFC 9381, Appendix B.3.
+var rfcVectors = []struct {
+	sk, pk, alpha, pi, beta string
+}{
+	{
+		sk:    "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+		pk:    "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+		alpha: "",
+		pi:    "8657106690b5526245a92b003bb079ccd1a92130477671f6fc01ad16f26f723f26f8a57ccaed74ee1b190bed1f479d9727d2d0f9b005a6e456a35d4fb0daab1268a1b0db10836d9826a528ca76567805",
+		beta:  "90cf1df3b703cce59e2a35b925d411164068269d7b2d29f3301c03dd757876ff66b71dda49d2de59d03450451af026798e8f81cd2e333de5cdf4f3e140fdd8ae",
+	},
+	{
+		sk:    "4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb",
+		pk:    "3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c",
+		alpha: "72",
+		pi:    "f3141cd382dc42909d19ec5110469e4feae18300e94f304590abdced48aed5933bf0864a62558b3ed7f2fea45c92a465301b3bbf5e3e54ddf2d935be3b67926da3ef39226bbc355bdc9850112c8f4b02",
+		beta:  "eb4440665d3891d668e7e0fcaf587f1b4bd7fbfe99d0eb2211ccec90496310eb5e33821bc613efb94db5e5b54c70a848a0bef4553a41befc57663b56373a5031",
+	},
+}
+
+func TestRFCVectors(t *testing.T) {
+	for i, v := range rfcVectors {
+		sk, _ := hex.DecodeString(v.sk)
+		pk, _ := hex.DecodeString(v.pk)
+		alpha, _ := hex.DecodeString(v.alpha)
+		priv := ed25519.NewKeyFromSeed(sk)
+		if !bytes.Equal(priv.Public().(ed25519.PublicKey), pk) {
+			t.Fatalf("vector %d: wrong public key", i)
+		}
+
+		pi := Prove(priv, alpha)
+		if got := hex.EncodeToString(pi); got != v.pi {
+			t.Errorf("vector %d: proof %s, want %s", i, got, v.pi)
+		}
+		ok, beta := Verify(pk, pi, alpha)
+		if !ok {
+			t.Errorf("vector %d: proof failed to verify", i)
+		}
+		if got := hex.EncodeToString(beta); got != v.beta {
+			t.Errorf("vector %d: output %s, want %s", i, got, v.beta)
+		}
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	alpha := []byte("block 1234")
+	pi := Prove(priv, alpha)
+	if ok, _ := Verify(pub, pi, alpha); !ok {
+		t.Fatal("proof failed to verify")
+	}
+	beta, err := ProofToHash(pi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, want := Verify(pub, pi, alpha); !bytes.Equal(beta, want) {
+		t.Error("ProofToHash disagrees with Verify")
+	}
+
+	if ok, _ := Verify(pub, pi, []byte("block 1235")); ok {
+		t.Error("proof verified for another input")
+	}
+	for i := range pi {
+		corrupted := append([]byte(nil), pi...)
+		corrupted[i] ^= 1
+		if ok, _ := Verify(pub, corrupted, alpha); ok {
+			t.Errorf("proof corrupted at byte %d verified", i)
+		}
+	}
+
+	identity, _ := hex.DecodeString("0100000000000000000000000000000000000000000000000000000000000000")
+	if ok, _ := Verify(identity, pi, alpha); ok {
+		t.Error("proof verified under a small-order key")
+	}
+	if ok, _ := Verify(pub, pi[:79], alpha); ok {
+		t.Error("short proof verified")
+	}
+}
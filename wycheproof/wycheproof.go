@@ -0,0 +1,171 @@
+// Package wycheproof loads Ed25519 test vectors in the JSON format of Project
+// Wycheproof (https://github.com/C2SP/wycheproof), such as
+// testvectors_v1/ed25519_test.json, and runs them against a verifier.
+//
+// Wycheproof marks some vectors "acceptable" rather than valid or invalid,
+// when implementations legitimately disagree on them, as they do on
+// small-order keys. Run accepts either decision for those, so the same
+// corpus can check ed25519consensus.Verify, a stricter Policy, or a
+// downstream wrapper around either.
+package wycheproof
+
+import (
+	"crypto/ed25519"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Result is the expected outcome of a Vector.
+type Result string
+
+const (
+	// Valid vectors must be accepted.
+	Valid Result = "valid"
+	// Invalid vectors must be rejected.
+	Invalid Result = "invalid"
+	// Acceptable vectors may be accepted or rejected.
+	Acceptable Result = "acceptable"
+)
+
+// Vector is a single verification test case.
+type Vector struct {
+	// TcID is the identifier of the test case in its file.
+	TcID    int
+	Comment string
+	// Flags name the notes of the file that apply to the vector, such as
+	// "SignatureMalleability".
+	Flags []string
+
+	PublicKey []byte
+	Message   []byte
+	Signature []byte
+
+	Result Result
+}
+
+// ErrUnsupportedFile means a file is not a Wycheproof EdDSA verification
+// file.
+var ErrUnsupportedFile = errors.New("wycheproof: not an EdDSA verification file")
+
+type file struct {
+	Algorithm  string `json:"algorithm"`
+	TestGroups []struct {
+		Type string `json:"type"`
+		// Current files name the key publicKey, older ones key.
+		PublicKey *groupKey `json:"publicKey"`
+		Key       *groupKey `json:"key"`
+		Tests     []struct {
+			TcID    int      `json:"tcId"`
+			Comment string   `json:"comment"`
+			Flags   []string `json:"flags"`
+			Msg     string   `json:"msg"`
+			Sig     string   `json:"sig"`
+			Result  Result   `json:"result"`
+		} `json:"tests"`
+	} `json:"testGroups"`
+}
+
+type groupKey struct {
+	Curve string `json:"curve"`
+	Pk    string `json:"pk"`
+}
+
+// Parse reads a Wycheproof EdDSA verification file and returns its vectors.
+// Groups of keys on curves other than edwards25519, such as the Ed448 groups
+// of older combined files, are skipped.
+func Parse(r io.Reader) ([]Vector, error) {
+	var f file
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("wycheproof: %w", err)
+	}
+	if f.Algorithm != "EDDSA" {
+		return nil, ErrUnsupportedFile
+	}
+	var vectors []Vector
+	for _, g := range f.TestGroups {
+		if g.Type != "EddsaVerify" {
+			return nil, ErrUnsupportedFile
+		}
+		key := g.PublicKey
+		if key == nil {
+			key = g.Key
+		}
+		if key == nil {
+			return nil, fmt.Errorf("wycheproof: test group without a public key")
+		}
+		if key.Curve != "" && key.Curve != "edwards25519" {
+			continue
+		}
+		pk, err := hex.DecodeString(key.Pk)
+		if err != nil {
+			return nil, fmt.Errorf("wycheproof: public key: %w", err)
+		}
+		for _, tc := range g.Tests {
+			v := Vector{
+				TcID:      tc.TcID,
+				Comment:   tc.Comment,
+				Flags:     tc.Flags,
+				PublicKey: pk,
+				Result:    tc.Result,
+			}
+			switch v.Result {
+			case Valid, Invalid, Acceptable:
+			default:
+				return nil, fmt.Errorf("wycheproof: test %d: unknown result %q", tc.TcID, tc.Result)
+			}
+			if v.Message, err = hex.DecodeString(tc.Msg); err != nil {
+				return nil, fmt.Errorf("wycheproof: test %d: message: %w", tc.TcID, err)
+			}
+			if v.Signature, err = hex.DecodeString(tc.Sig); err != nil {
+				return nil, fmt.Errorf("wycheproof: test %d: signature: %w", tc.TcID, err)
+			}
+			vectors = append(vectors, v)
+		}
+	}
+	return vectors, nil
+}
+
+// Load reads the Wycheproof EdDSA verification file at path, as by Parse.
+func Load(path string) ([]Vector, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+//go:embed testdata/eddsa_sample.json
+var sample string
+
+// Sample returns a small corpus in the Wycheproof format, derived from the
+// vectors of RFC 8032 and ZIP215, for use when the Wycheproof files are not
+// available.
+func Sample() []Vector {
+	vectors, err := Parse(strings.NewReader(sample))
+	if err != nil {
+		panic(err)
+	}
+	return vectors
+}
+
+// Run checks verify against vectors, reporting each wrong decision on a
+// valid or invalid vector as an error on t. The signature of verify matches
+// Verify in both ed25519consensus and crypto/ed25519.
+func Run(t testing.TB, vectors []Vector, verify func(publicKey ed25519.PublicKey, message, sig []byte) bool) {
+	t.Helper()
+	for _, v := range vectors {
+		got := verify(v.PublicKey, v.Message, v.Signature)
+		if v.Result == Acceptable || got == (v.Result == Valid) {
+			continue
+		}
+		t.Errorf("test %d (%s, flags %v): got %v, want %s", v.TcID, v.Comment, v.Flags, got, v.Result)
+	}
+}
@@ -0,0 +1,108 @@
+package wycheproof
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hdevalence/ed25519consensus"
+)
+
+func TestSample(t *testing.T) {
+	vectors := Sample()
+	if len(vectors) != 12 {
+		t.Fatalf("got %d vectors, want 12", len(vectors))
+	}
+	Run(t, vectors, ed25519consensus.Verify)
+	Run(t, vectors, ed25519.Verify)
+
+	strict := ed25519consensus.Policy{
+		Keys:              ed25519consensus.RequireCanonicalKey | ed25519consensus.RejectSmallOrderKey,
+		RequireCanonicalR: true,
+		Cofactorless:      true,
+	}
+	Run(t, vectors, strict.Verify)
+
+	// The acceptable vector is where ZIP215 and the strict rules disagree.
+	for _, v := range vectors {
+		if v.Result != Acceptable {
+			continue
+		}
+		if !ed25519consensus.Verify(v.PublicKey, v.Message, v.Signature) {
+			t.Errorf("test %d: rejected by Verify", v.TcID)
+		}
+		if strict.Verify(v.PublicKey, v.Message, v.Signature) {
+			t.Errorf("test %d: accepted by the strict policy", v.TcID)
+		}
+	}
+}
+
+func TestRunReportsWrongDecisions(t *testing.T) {
+	var ft fakeT
+	Run(&ft, Sample(), func(ed25519.PublicKey, []byte, []byte) bool { return true })
+	if ft.errors != 7 {
+		t.Errorf("accepting everything reported %d errors, want 7", ft.errors)
+	}
+}
+
+type fakeT struct {
+	testing.TB
+	errors int
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(string, ...interface{}) { t.errors++ }
+
+func TestParseOlderFormat(t *testing.T) {
+	const older = `{"algorithm": "EDDSA", "testGroups": [
+		{"type": "EddsaVerify", "key": {"curve": "edwards25519", "pk": "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"},
+		 "tests": [{"tcId": 1, "msg": "", "sig": "e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b", "result": "valid", "flags": []}]},
+		{"type": "EddsaVerify", "key": {"curve": "edwards448", "pk": "00"},
+		 "tests": [{"tcId": 2, "msg": "", "sig": "00", "result": "valid", "flags": []}]}]}`
+	vectors, err := Parse(strings.NewReader(older))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) != 1 {
+		t.Fatalf("got %d vectors, want 1", len(vectors))
+	}
+	Run(t, vectors, ed25519consensus.Verify)
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name, json string
+	}{
+		{"not JSON", `{`},
+		{"other algorithm", `{"algorithm": "ECDSA", "testGroups": []}`},
+		{"other group type", `{"algorithm": "EDDSA", "testGroups": [{"type": "EddsaSign"}]}`},
+		{"no key", `{"algorithm": "EDDSA", "testGroups": [{"type": "EddsaVerify"}]}`},
+		{"bad key", `{"algorithm": "EDDSA", "testGroups": [{"type": "EddsaVerify", "publicKey": {"pk": "zz"}}]}`},
+		{"bad result", `{"algorithm": "EDDSA", "testGroups": [{"type": "EddsaVerify", "publicKey": {"pk": "00"},
+			"tests": [{"tcId": 1, "msg": "", "sig": "", "result": "maybe"}]}]}`},
+		{"bad signature", `{"algorithm": "EDDSA", "testGroups": [{"type": "EddsaVerify", "publicKey": {"pk": "00"},
+			"tests": [{"tcId": 1, "msg": "", "sig": "0", "result": "valid"}]}]}`},
+	} {
+		if _, err := Parse(strings.NewReader(tc.json)); err == nil {
+			t.Errorf("%s: no error", tc.name)
+		}
+	}
+	if _, err := Parse(strings.NewReader(`{"algorithm": "ECDSA"}`)); !errors.Is(err, ErrUnsupportedFile) {
+		t.Errorf("got %v, want ErrUnsupportedFile", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	vectors, err := Load("testdata/eddsa_sample.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) != len(Sample()) {
+		t.Errorf("Load returned %d vectors, Sample %d", len(vectors), len(Sample()))
+	}
+	if _, err := Load("testdata/missing.json"); err == nil {
+		t.Error("no error for a missing file")
+	}
+}
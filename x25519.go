@@ -0,0 +1,26 @@
+package ed25519consensus
+
+import (
+	"crypto/ed25519"
+
+	"filippo.io/edwards25519"
+)
+
+// ToX25519 converts an Ed25519 public key to the X25519 public key (the
+// Montgomery u-coordinate) of the same point, using the birational map from
+// RFC 7748. The X25519 private key corresponding to the result is the clamped
+// first half of the SHA-512 hash of the Ed25519 seed.
+//
+// Like Verify, ToX25519 accepts non-canonical encodings, and it does not
+// reject small-order points, which X25519 callers must handle anyway. The
+// identity maps to the all-zero u-coordinate.
+func ToX25519(pub ed25519.PublicKey) ([]byte, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, ErrInvalidKeyLength
+	}
+	A, err := new(edwards25519.Point).SetBytes(pub)
+	if err != nil {
+		return nil, ErrInvalidKeyEncoding
+	}
+	return A.BytesMontgomery(), nil
+}
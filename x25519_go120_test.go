@@ -0,0 +1,32 @@
+//go:build go1.20
+
+package ed25519consensus
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"testing"
+)
+
+// This test cross-checks against crypto/ecdh, which was added in Go 1.20.
+
+func TestToX25519(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		pub, priv, _ := ed25519.GenerateKey(nil)
+		h := sha512.Sum512(priv.Seed())
+		xPriv, err := ecdh.X25519().NewPrivateKey(h[:32])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := ToX25519(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := xPriv.PublicKey().Bytes(); !bytes.Equal(got, want) {
+			t.Errorf("ToX25519 = %x, want %x", got, want)
+		}
+	}
+}
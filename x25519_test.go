@@ -0,0 +1,13 @@
+package ed25519consensus
+
+import "testing"
+
+func TestToX25519Errors(t *testing.T) {
+	if _, err := ToX25519(make([]byte, 31)); err != ErrInvalidKeyLength {
+		t.Errorf("short key: got %v", err)
+	}
+	notOnCurve := decodeHex32(t, "0200000000000000000000000000000000000000000000000000000000000000")
+	if _, err := ToX25519(notOnCurve[:]); err != ErrInvalidKeyEncoding {
+		t.Errorf("invalid key: got %v", err)
+	}
+}
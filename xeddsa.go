@@ -0,0 +1,46 @@
+package ed25519consensus
+
+import (
+	"bytes"
+
+	"filippo.io/edwards25519/field"
+)
+
+// VerifyXEdDSA reports whether sig is a valid XEdDSA signature of message by
+// the X25519 public key (Montgomery u-coordinate) publicKey, as specified by
+// Signal's XEdDSA and VXEdDSA Signature Schemes, Section 2.
+//
+// The u-coordinate must be canonical, that is, less than 2^255 - 19. It is
+// converted to the Edwards point with sign bit zero, and the signature is then
+// checked exactly like Verify, so R may be non-canonical and s must be
+// reduced. The specification only requires s < 2^253, so VerifyXEdDSA is
+// stricter than it in that respect, but matches every signature produced by
+// a conforming signer.
+func VerifyXEdDSA(publicKey, message, sig []byte) bool {
+	A, ok := xeddsaPublicKey(publicKey)
+	if !ok {
+		return false
+	}
+	return Verify(A, message, sig)
+}
+
+// xeddsaPublicKey implements convert_mont from the XEdDSA specification,
+// returning the Edwards encoding of the point with u-coordinate u and sign
+// bit zero.
+func xeddsaPublicKey(u []byte) ([]byte, bool) {
+	if len(u) != 32 {
+		return nil, false
+	}
+	U, err := new(field.Element).SetBytes(u)
+	if err != nil || !bytes.Equal(U.Bytes(), u) {
+		return nil, false
+	}
+
+	// y = (u - 1) / (u + 1). As in the specification, the inverse of zero is
+	// zero, so u = -1 maps to y = 0.
+	one := new(field.Element).One()
+	num := new(field.Element).Subtract(U, one)
+	den := new(field.Element).Add(U, one)
+	y := new(field.Element).Multiply(num, den.Invert(den))
+	return y.Bytes(), true
+}
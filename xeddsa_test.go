@@ -0,0 +1,82 @@
+package ed25519consensus
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"testing"
+
+	"filippo.io/edwards25519"
+)
+
+// xeddsaSign implements xeddsa_sign from the XEdDSA specification for the
+// clamped X25519 private scalar k.
+func xeddsaSign(k *edwards25519.Scalar, message []byte) []byte {
+	A := new(edwards25519.Point).ScalarBaseMult(k)
+	a := new(edwards25519.Scalar).Set(k)
+	encA := A.Bytes()
+	if encA[31]&0x80 != 0 {
+		a.Negate(a)
+		encA[31] &= 0x7f
+	}
+
+	var Z [64]byte
+	rand.Read(Z[:])
+	h := sha512.New()
+	// hash_1 prefix: 2^256 - 1 - 1 in little-endian.
+	h.Write([]byte{0xfe})
+	for i := 0; i < 31; i++ {
+		h.Write([]byte{0xff})
+	}
+	h.Write(a.Bytes())
+	h.Write(message)
+	h.Write(Z[:])
+	r, _ := new(edwards25519.Scalar).SetUniformBytes(h.Sum(nil))
+	R := new(edwards25519.Point).ScalarBaseMult(r)
+
+	h.Reset()
+	h.Write(R.Bytes())
+	h.Write(encA)
+	h.Write(message)
+	c, _ := new(edwards25519.Scalar).SetUniformBytes(h.Sum(nil))
+	s := new(edwards25519.Scalar).MultiplyAdd(c, a, r)
+	return append(R.Bytes(), s.Bytes()...)
+}
+
+func TestVerifyXEdDSA(t *testing.T) {
+	msg := []byte("signal identity message")
+	for i := 0; i < 16; i++ {
+		var seed [32]byte
+		rand.Read(seed[:])
+		k, _ := new(edwards25519.Scalar).SetBytesWithClamping(seed[:])
+		u := new(edwards25519.Point).ScalarBaseMult(k).BytesMontgomery()
+
+		sig := xeddsaSign(k, msg)
+		if !VerifyXEdDSA(u, msg, sig) {
+			t.Fatalf("valid XEdDSA signature rejected for u = %x", u)
+		}
+		if VerifyXEdDSA(u, []byte("other"), sig) {
+			t.Error("XEdDSA signature verified for the wrong message")
+		}
+	}
+
+	var seed [32]byte
+	rand.Read(seed[:])
+	k, _ := new(edwards25519.Scalar).SetBytesWithClamping(seed[:])
+	u := new(edwards25519.Point).ScalarBaseMult(k).BytesMontgomery()
+	sig := xeddsaSign(k, msg)
+
+	// With the top bit set, u is at least 2^255 and thus not canonical.
+	high := append([]byte(nil), u...)
+	high[31] |= 0x80
+	if VerifyXEdDSA(high, msg, sig) {
+		t.Error("XEdDSA signature verified with the top bit of u set")
+	}
+	if VerifyXEdDSA(u[:31], msg, sig) {
+		t.Error("XEdDSA signature verified with a short key")
+	}
+
+	p := decodeHex32(t, "edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	if VerifyXEdDSA(p[:], msg, sig) {
+		t.Error("XEdDSA signature verified with u = p")
+	}
+}